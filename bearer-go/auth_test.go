@@ -0,0 +1,40 @@
+package main
+
+import "testing"
+
+func TestResolveAuthMode(t *testing.T) {
+	t.Setenv("AUTH_MODE", "")
+	cfg := &Config{}
+	if err := resolveAuthMode(cfg, "bearer", "bearer", "none"); err != nil {
+		t.Fatalf("resolveAuthMode returned error: %v", err)
+	}
+	if cfg.AuthMode != "bearer" || !cfg.AuthModeInferred {
+		t.Errorf("Expected inferred bearer mode, got %q (inferred=%v)", cfg.AuthMode, cfg.AuthModeInferred)
+	}
+
+	t.Setenv("AUTH_MODE", "apikey")
+	if err := resolveAuthMode(&Config{}, "none", "bearer", "none"); err == nil {
+		t.Error("Expected an error for an unsupported mode")
+	}
+}
+
+func TestValidateAuth(t *testing.T) {
+	if err := (&Config{AuthMode: "bearer"}).validateAuth(); err == nil {
+		t.Error("Expected bearer mode without MCP_BEARER_TOKEN to be rejected")
+	}
+	if err := (&Config{AuthMode: "bearer", BearerToken: "token"}).validateAuth(); err != nil {
+		t.Errorf("Expected bearer mode with a token to be valid, got: %v", err)
+	}
+	if err := (&Config{AuthMode: "none"}).validateAuth(); err != nil {
+		t.Errorf("Expected none mode without a token to be valid, got: %v", err)
+	}
+	if err := (&Config{AuthMode: "bearer", BearerToken: "token", ReadonlyToken: "token"}).validateAuth(); err == nil {
+		t.Error("Expected a read-only token equal to the primary token to be rejected")
+	}
+	if err := (&Config{AuthMode: "none", ReadonlyToken: "token"}).validateAuth(); err == nil {
+		t.Error("Expected none mode with a read-only token to be rejected")
+	}
+	if err := (&Config{AuthMode: "none", SessionsEnabled: true}).validateAuth(); err == nil {
+		t.Error("Expected sessions_info without authentication to be rejected")
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadBaselines(t *testing.T) {
+	dir := t.TempDir()
+	write := func(body string) string {
+		path := filepath.Join(dir, "baselines.json")
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	for _, path := range []string{"", filepath.Join(dir, "missing.json")} {
+		if b, err := loadBaselines(path); err != nil || b != nil {
+			t.Errorf("Expected no baselines for %q, got %v, %v", path, b, err)
+		}
+	}
+
+	b, err := loadBaselines(write(`{"cpu_percent": {"warn": 80, "critical": 95}, "load1": {"critical": 4}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b) != 2 || *b["cpu_percent"].Warn != 80 || b["load1"].Warn != nil {
+		t.Errorf("Unexpected baselines %+v", b)
+	}
+
+	for _, body := range []string{
+		`{"cpu": {"warn": 80}}`,
+		`{"cpu_percent": {}}`,
+		`{"cpu_percent": {"warn": 95, "critical": 80}}`,
+		`{"cpu_percent": {"warm": 80}}`,
+		`not json`,
+	} {
+		if _, err := loadBaselines(write(body)); err == nil || !strings.Contains(err.Error(), "BASELINES_FILE") {
+			t.Errorf("Expected %s to be rejected, got %v", body, err)
+		}
+	}
+}
+
+func TestBaselineSeverity(t *testing.T) {
+	warn, critical := 80.0, 95.0
+	b := baseline{Warn: &warn, Critical: &critical}
+	for v, want := range map[float64]string{10: severityOK, 80: severityWarning, 94.9: severityWarning, 95: severityCritical} {
+		if got := b.severity(v); got != want {
+			t.Errorf("severity(%v) = %s, want %s", v, got, want)
+		}
+	}
+	if got := (baseline{Critical: &critical}).severity(90); got != severityOK {
+		t.Errorf("Expected no warning without a warn threshold, got %s", got)
+	}
+}
+
+func TestFormatBaselineCheck(t *testing.T) {
+	warn, critical := 80.0, 90.0
+	b := baselines{
+		"cpu_percent":    {Warn: &warn},
+		"disk_percent":   {Warn: &warn, Critical: &critical},
+		"memory_percent": {Critical: &critical},
+		"load1":          {Critical: &critical},
+	}
+	out := formatBaselineCheck("/etc/baselines.json", b, map[string]baselineReading{
+		"cpu_percent":    {Value: 85},
+		"disk_percent":   {Value: 93.5, Where: "/data"},
+		"memory_percent": {Value: 40},
+		"load1":          {Err: errors.New("not implemented")},
+	})
+	for _, want := range []string{
+		"Baselines: /etc/baselines.json",
+		"critical (/data)",
+		"load1",
+		"unavailable: not implemented",
+		"Out of range: cpu_percent (warning), disk_percent (critical)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "swap_percent") {
+		t.Errorf("Expected unconfigured metrics to be left out:\n%s", out)
+	}
+
+	out = formatBaselineCheck("b.json", baselines{"memory_percent": {Critical: &critical}}, map[string]baselineReading{"memory_percent": {Value: 10}})
+	if !strings.Contains(out, "All metrics within baselines") {
+		t.Errorf("Expected an all-clear, got:\n%s", out)
+	}
+}
+
+func TestBaselineCheckReportUnconfigured(t *testing.T) {
+	out, err := baselineCheckReport(context.Background(), "", diskReportOptions{})
+	if err != nil || !strings.Contains(out, "No baselines configured") {
+		t.Errorf("Expected the unconfigured note, got %q, %v", out, err)
+	}
+}
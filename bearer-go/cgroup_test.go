@@ -0,0 +1,44 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseCPUMax(t *testing.T) {
+	tests := []struct {
+		in   string
+		want float64
+		ok   bool
+	}{
+		{"200000 100000\n", 2, true},
+		{"50000 100000", 0.5, true},
+		{"max 100000\n", 0, false},
+		{"", 0, false},
+		{"abc 100000", 0, false},
+		{"100000 0", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseCPUMax(tt.in)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseCPUMax(%q) = %v, %v; want %v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestCgroupCPULimit(t *testing.T) {
+	orig := cgroupRoot
+	defer func() { cgroupRoot = orig }()
+
+	cgroupRoot = t.TempDir()
+	if _, ok := cgroupCPULimit(); ok {
+		t.Error("Expected no limit when cpu.max is missing")
+	}
+	if err := os.WriteFile(filepath.Join(cgroupRoot, "cpu.max"), []byte("150000 100000\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := cgroupCPULimit(); !ok || got != 1.5 {
+		t.Errorf("Expected 1.5 vCPU, got %v (ok=%v)", got, ok)
+	}
+}
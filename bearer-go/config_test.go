@@ -0,0 +1,141 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormatConfigRedactsSecrets(t *testing.T) {
+	cfg := &Config{Port: "9090", AuthMode: "bearer", BearerToken: "super-secret-key"}
+
+	text, err := formatConfig(cfg, false)
+	if err != nil {
+		t.Fatalf("formatConfig returned error: %v", err)
+	}
+	if strings.Contains(text, "super-secret-key") {
+		t.Errorf("Expected text output to redact the bearer token, got: %s", text)
+	}
+	if !strings.Contains(text, fingerprint("super-secret-key")) {
+		t.Errorf("Expected text output to contain the token fingerprint, got: %s", text)
+	}
+
+	out, err := formatConfig(cfg, true)
+	if err != nil {
+		t.Fatalf("formatConfig returned error: %v", err)
+	}
+	var m map[string]any
+	if err := json.Unmarshal([]byte(out), &m); err != nil {
+		t.Fatalf("Expected valid JSON, got error: %v", err)
+	}
+	if m["port"] != "9090" {
+		t.Errorf("Expected port 9090, got: %v", m["port"])
+	}
+	if strings.Contains(out, "super-secret-key") {
+		t.Errorf("Expected JSON output to redact the bearer token, got: %s", out)
+	}
+}
+
+func TestFormatConfigDurationsAndColumns(t *testing.T) {
+	cfg := &Config{Port: "9090", AuthMode: "none", ProbeTimeout: 5 * time.Second, SlowThreshold: 2 * time.Second}
+
+	out, err := formatConfig(cfg, true)
+	if err != nil {
+		t.Fatalf("formatConfig returned error: %v", err)
+	}
+	var m map[string]any
+	if err := json.Unmarshal([]byte(out), &m); err != nil {
+		t.Fatalf("Expected valid JSON, got error: %v", err)
+	}
+	if m["health_probe_timeout"] != "5s" || m["slow_request_threshold"] != "2s" {
+		t.Errorf("Expected durations as strings, got %v and %v", m["health_probe_timeout"], m["slow_request_threshold"])
+	}
+
+	text, err := formatConfig(cfg, false)
+	if err != nil {
+		t.Fatalf("formatConfig returned error: %v", err)
+	}
+	col := -1
+	for _, line := range strings.Split(text, "\n")[3:] {
+		label, rest, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(rest) == "" {
+			continue
+		}
+		start := len(label) + 1 + len(rest) - len(strings.TrimLeft(rest, " "))
+		if col == -1 {
+			col = start
+		} else if start != col {
+			t.Errorf("Expected values to start in column %d, got %q", col, line)
+		}
+	}
+}
+
+func TestLoadConfigTCPKeepAlive(t *testing.T) {
+	for _, tt := range []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"0", 0},
+		{"-1s", -time.Second},
+		{"30s", 30 * time.Second},
+	} {
+		t.Setenv("TCP_KEEPALIVE", tt.value)
+		cfg, err := loadConfig()
+		if err != nil {
+			t.Errorf("TCP_KEEPALIVE=%q: loadConfig returned error: %v", tt.value, err)
+			continue
+		}
+		if cfg.TCPKeepAlive != tt.want {
+			t.Errorf("TCP_KEEPALIVE=%q: got %v, want %v", tt.value, cfg.TCPKeepAlive, tt.want)
+		}
+	}
+	t.Setenv("TCP_KEEPALIVE", "soon")
+	if _, err := loadConfig(); err == nil {
+		t.Error("Expected an unparsable TCP_KEEPALIVE to be rejected")
+	}
+}
+
+func TestEnvDuration(t *testing.T) {
+	for _, tt := range []struct {
+		value string
+		def   time.Duration
+		want  time.Duration
+		ok    bool
+	}{
+		{"", 0, 0, true},
+		{"", 5 * time.Second, 5 * time.Second, true},
+		{"24h", 0, 24 * time.Hour, true},
+		{"0", 0, 0, false},
+		{"-1s", 0, 0, false},
+		{"soon", 0, 0, false},
+	} {
+		t.Setenv("TEST_DURATION", tt.value)
+		got, err := envDuration("TEST_DURATION", tt.def)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("envDuration(%q, %v) = %v, %v; want %v (ok=%v)", tt.value, tt.def, got, err, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestFormatConfigRedactsWebhookURL(t *testing.T) {
+	const hook = "https://hooks.example.com/services/T000/B000/s3cret-token?key=abc"
+	out, err := formatConfig(&Config{Port: "9090", AuthMode: "none", WebhookURL: hook}, true)
+	if err != nil {
+		t.Fatalf("formatConfig returned error: %v", err)
+	}
+	var m map[string]any
+	if err := json.Unmarshal([]byte(out), &m); err != nil {
+		t.Fatalf("Expected valid JSON, got error: %v", err)
+	}
+	if want := "https://hooks.example.com " + fingerprint(hook); m["report_webhook_url"] != want {
+		t.Errorf("Expected %q, got %v", want, m["report_webhook_url"])
+	}
+	if strings.Contains(out, "s3cret-token") || strings.Contains(out, "key=abc") {
+		t.Errorf("Expected the webhook path and query to be redacted, got: %s", out)
+	}
+	if got := redactURL(""); got != "(not set)" {
+		t.Errorf("Expected an unset URL to show as not set, got %q", got)
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// connStats counts the public listener's client connections so /stats shows
+// connection churn under streaming load. It is safe for concurrent use.
+type connStats struct {
+	active   atomic.Int64
+	accepted atomic.Int64
+}
+
+// serverConns tracks the public listener; it is installed as the server's
+// ConnState hook.
+var serverConns connStats
+
+// track is an http.Server ConnState hook. Hijacked connections leave the
+// server's control and so stop counting as active.
+func (c *connStats) track(_ net.Conn, state http.ConnState) {
+	switch state {
+	case http.StateNew:
+		c.active.Add(1)
+		c.accepted.Add(1)
+	case http.StateHijacked, http.StateClosed:
+		c.active.Add(-1)
+	}
+}
+
+// listenAndServe is http.Server.ListenAndServe(TLS) with the TCP keepalive
+// period set on accepted connections. A zero keepAlive keeps Go's default
+// and a negative one disables keepalives. With proxyProtocol every connection
+// must start with a PROXY protocol header, read before any TLS handshake. TLS
+// is served when certFile is set.
+func listenAndServe(srv *http.Server, keepAlive time.Duration, proxyProtocol bool, certFile, keyFile string) error {
+	lc := net.ListenConfig{KeepAlive: keepAlive}
+	ln, err := lc.Listen(context.Background(), "tcp", srv.Addr)
+	if err != nil {
+		return err
+	}
+	if proxyProtocol {
+		ln = proxyListener{ln}
+	}
+	if certFile != "" {
+		return srv.ServeTLS(ln, certFile, keyFile)
+	}
+	return srv.Serve(ln)
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseResolvConf(t *testing.T) {
+	const conf = `# generated by resolvconf
+nameserver 10.0.0.2
+nameserver fd00::53 ; secondary
+domain old.example
+search corp.example svc.cluster.local
+options ndots:5 timeout:2
+nameserver
+`
+	cfg := parseResolvConf(strings.NewReader(conf))
+	if !slices.Equal(cfg.Nameservers, []string{"10.0.0.2", "fd00::53"}) {
+		t.Errorf("Unexpected nameservers %q", cfg.Nameservers)
+	}
+	if !slices.Equal(cfg.Search, []string{"corp.example", "svc.cluster.local"}) {
+		t.Errorf("Expected the last search line to win, got %q", cfg.Search)
+	}
+	if !slices.Equal(cfg.Options, []string{"ndots:5", "timeout:2"}) {
+		t.Errorf("Unexpected options %q", cfg.Options)
+	}
+}
+
+func TestDNSInfoInputValidate(t *testing.T) {
+	if err := (dnsInfoInput{Hostname: "bad host;"}).validate(); !errors.Is(err, errInvalidInput) {
+		t.Errorf("Expected an invalid hostname to be rejected, got %v", err)
+	}
+	if err := (dnsInfoInput{Hostname: "db-1.corp.example"}).validate(); err != nil {
+		t.Errorf("Expected a valid hostname to pass, got %v", err)
+	}
+}
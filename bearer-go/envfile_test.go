@@ -0,0 +1,45 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseEnvFile(t *testing.T) {
+	vars, err := parseEnvFile("# comment\n\nPORT=9090\nexport NAME = \"a b\"\nQUOTED='x=1'\nPORT=9091\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vars["PORT"] != "9091" || vars["NAME"] != "a b" || vars["QUOTED"] != "x=1" || len(vars) != 3 {
+		t.Errorf("Unexpected variables: %v", vars)
+	}
+	if _, err := parseEnvFile("NOT A SETTING\n"); err == nil {
+		t.Error("Expected an error for a line without KEY=VALUE")
+	}
+}
+
+func TestLoadEnvFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dev.env")
+	if err := os.WriteFile(path, []byte("ENVFILE_TEST_NEW=from-file\nENVFILE_TEST_SET=from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ENV_FILE", path)
+	t.Setenv("ENVFILE_TEST_SET", "from-env")
+	t.Cleanup(func() { os.Unsetenv("ENVFILE_TEST_NEW") })
+
+	if err := loadEnvFile(); err != nil {
+		t.Fatal(err)
+	}
+	if got := os.Getenv("ENVFILE_TEST_NEW"); got != "from-file" {
+		t.Errorf("Expected an unset variable to be loaded, got %q", got)
+	}
+	if got := os.Getenv("ENVFILE_TEST_SET"); got != "from-env" {
+		t.Errorf("Expected the real environment to win, got %q", got)
+	}
+
+	t.Setenv("ENV_FILE", filepath.Join(t.TempDir(), "missing.env"))
+	if err := loadEnvFile(); err == nil {
+		t.Error("Expected an error for a missing ENV_FILE")
+	}
+}
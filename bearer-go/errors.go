@@ -0,0 +1,47 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+)
+
+// Error classes. Wrap one of these with %w so toolError can pick the code
+// the client sees; anything else is reported as an internal error.
+var (
+	// errInvalidInput marks errors caused by the tool arguments.
+	errInvalidInput = errors.New("invalid input")
+	// errForbidden marks calls the caller's credentials do not allow.
+	errForbidden = errors.New("forbidden")
+	// errRateLimited marks calls over their TOOL_RATE_LIMITS budget.
+	errRateLimited = errors.New("rate limit exceeded")
+	// errResultTooLarge marks JSON results over MAX_RESULT_BYTES.
+	errResultTooLarge = errors.New("result too large")
+)
+
+// codeForbidden is the server-defined JSON-RPC code for errForbidden.
+const codeForbidden = -32001
+
+// codeRateLimited is the server-defined JSON-RPC code for errRateLimited.
+const codeRateLimited = -32002
+
+// codeResultTooLarge is the server-defined JSON-RPC code for
+// errResultTooLarge.
+const codeResultTooLarge = -32003
+
+// toolError converts a tool failure into a JSON-RPC error so clients get a
+// code to branch on instead of an IsError text result.
+func toolError(err error) error {
+	code := int64(jsonrpc.CodeInternalError)
+	switch {
+	case errors.Is(err, errInvalidInput):
+		code = jsonrpc.CodeInvalidParams
+	case errors.Is(err, errForbidden):
+		code = codeForbidden
+	case errors.Is(err, errRateLimited):
+		code = codeRateLimited
+	case errors.Is(err, errResultTooLarge):
+		code = codeResultTooLarge
+	}
+	return &jsonrpc.Error{Code: code, Message: err.Error()}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+)
+
+func TestToolErrorCodes(t *testing.T) {
+	tests := []struct {
+		err  error
+		code int64
+	}{
+		{fmt.Errorf("%w: bad format", errInvalidInput), jsonrpc.CodeInvalidParams},
+		{fmt.Errorf("%w: wrong token", errForbidden), codeForbidden},
+		{fmt.Errorf("%w: top_processes", errRateLimited), codeRateLimited},
+		{errors.New("disk read failed"), jsonrpc.CodeInternalError},
+	}
+	for _, tt := range tests {
+		var wireErr *jsonrpc.Error
+		if !errors.As(toolError(tt.err), &wireErr) {
+			t.Fatalf("Expected a JSON-RPC error for %v", tt.err)
+		}
+		if wireErr.Code != tt.code || wireErr.Message != tt.err.Error() {
+			t.Errorf("toolError(%v) = %d %q, want %d", tt.err, wireErr.Code, wireErr.Message, tt.code)
+		}
+	}
+}
+
+func TestToolInputValidation(t *testing.T) {
+	if err := (systemInfoInput{SoftDeadlineMS: -1}).validate(); !errors.Is(err, errInvalidInput) {
+		t.Errorf("Expected negative soft_deadline_ms to be invalid input, got: %v", err)
+	}
+	if err := (systemInfoInput{Timezone: "Mars/Olympus"}).validate(); !errors.Is(err, errInvalidInput) {
+		t.Errorf("Expected unknown timezone to be invalid input, got: %v", err)
+	}
+	if _, err := diskUsageReport("xml", diskReportOptions{}); !errors.Is(err, errInvalidInput) {
+		t.Errorf("Expected unknown format to be invalid input, got: %v", err)
+	}
+	if _, err := recentLogsReport("/var/log/syslog", maxLogTailLines+1); !errors.Is(err, errInvalidInput) {
+		t.Errorf("Expected too many lines to be invalid input, got: %v", err)
+	}
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestSystemFileHandles(t *testing.T) {
+	allocated, limit, err := systemFileHandles("4096\t0\t9223372036854775807\n")
+	if err != nil || allocated != 4096 || limit != 9223372036854775807 {
+		t.Errorf("Unexpected result: %d %d %v", allocated, limit, err)
+	}
+	if _, _, err := systemFileHandles("garbage"); err == nil {
+		t.Error("Expected an error for malformed file-nr")
+	}
+}
+
+func TestCollectFDUsage(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("descriptor counts are read from /proc")
+	}
+	out := collectFDUsage(context.Background(), 4)
+	if !strings.Contains(out, "System Handles:") || !strings.Contains(out, "Open FDs") {
+		t.Errorf("Unexpected report:\n%s", out)
+	}
+}
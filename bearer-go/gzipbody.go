@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// defaultMaxDecompressed caps a gzip request body once inflated when
+// MAX_DECOMPRESSED_BYTES is unset.
+const defaultMaxDecompressed = 10 << 20
+
+// withGzipRequest inflates request bodies sent with Content-Encoding: gzip
+// before h sees them, so compression-aware MCP clients work unchanged. The
+// inflated body is capped at limit bytes to defuse zip bombs: a larger one is
+// rejected with 413 and a malformed one with 400. Other requests pass through
+// untouched.
+func withGzipRequest(h http.Handler, limit int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.EqualFold(strings.TrimSpace(r.Header.Get("Content-Encoding")), "gzip") {
+			h.ServeHTTP(w, r)
+			return
+		}
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			slog.Warn("Malformed gzip request body", "path", r.URL.Path, "error", err)
+			http.Error(w, "Bad Request: malformed gzip body", http.StatusBadRequest)
+			return
+		}
+		defer zr.Close()
+		body, err := io.ReadAll(io.LimitReader(zr, int64(limit)+1))
+		if err != nil {
+			slog.Warn("Malformed gzip request body", "path", r.URL.Path, "error", err)
+			http.Error(w, "Bad Request: malformed gzip body", http.StatusBadRequest)
+			return
+		}
+		if len(body) > limit {
+			slog.Warn("Gzip request body too large", "path", r.URL.Path, "limit", limit)
+			http.Error(w, fmt.Sprintf("Request Entity Too Large: decompressed body exceeds %d bytes", limit), http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		r = r.Clone(r.Context())
+		r.Header.Del("Content-Encoding")
+		r.Header.Set("Content-Length", strconv.Itoa(len(body)))
+		r.ContentLength = int64(len(body))
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		h.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/host"
+)
+
+// defaultHealthProbeTimeout bounds the probe when HEALTH_PROBE_TIMEOUT is
+// unset, so that a hung /proc read fails the check instead of stalling it.
+const defaultHealthProbeTimeout = time.Second
+
+// healthProbe is the cheap metrics call behind /healthz/probe.
+var healthProbe = func(ctx context.Context) error {
+	_, err := host.InfoWithContext(ctx)
+	return err
+}
+
+// runProbe runs healthProbe, giving up after timeout even if the probe
+// ignores its context, so the caller never waits longer than the budget. A
+// zero timeout uses defaultHealthProbeTimeout.
+func runProbe(ctx context.Context, timeout time.Duration) error {
+	timeout = cmp.Or(timeout, defaultHealthProbeTimeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() { done <- healthProbe(ctx) }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("probe timed out after %s", timeout)
+		}
+		return ctx.Err()
+	}
+}
+
+// serveLive serves /livez: "OK" whenever the process can answer at all. It
+// checks nothing else, so an orchestrator only restarts a hung process;
+// whether to route traffic here is /readyz's job.
+func serveLive(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("OK"))
+}
+
+// serveHealthProbe serves /healthz/probe. Unlike the static /livez check it
+// confirms that host metrics can still be collected, e.g. that the
+// container has not lost /proc access. It is kept separate so a slow probe
+// never makes the liveness check flap. A probe slower than timeout
+// (HEALTH_PROBE_TIMEOUT) is a 503 rather than a hung request.
+func serveHealthProbe(w http.ResponseWriter, r *http.Request, timeout time.Duration) {
+	if err := runProbe(r.Context(), timeout); err != nil {
+		slog.Warn("Health probe failed", "error", err)
+		http.Error(w, "Service Unavailable: metrics probe failed: "+err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("OK"))
+}
+
+// Statuses of a health check and of the report as a whole, from best to
+// worst. The report takes the worst status of its checks.
+const (
+	healthOK   = "ok"
+	healthWarn = "warn"
+	healthFail = "fail"
+)
+
+// healthCheck is one component of the structured health report.
+type healthCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// healthReport is the structured /healthz document.
+type healthReport struct {
+	Status string        `json:"status"`
+	Checks []healthCheck `json:"checks"`
+}
+
+// newHealthReport aggregates checks into a report.
+func newHealthReport(checks []healthCheck) healthReport {
+	rank := func(status string) int { return slices.Index([]string{healthOK, healthWarn, healthFail}, status) }
+	report := healthReport{Status: healthOK, Checks: checks}
+	for _, c := range checks {
+		if rank(c.Status) > rank(report.Status) {
+			report.Status = c.Status
+		}
+	}
+	return report
+}
+
+// wantsVerboseHealth reports whether a /healthz request asks for the
+// structured report, with ?verbose=1 or an Accept header naming JSON. Plain
+// probes, and every request to "/", keep getting "OK".
+func wantsVerboseHealth(r *http.Request) bool {
+	if r.URL.Path != "/healthz" {
+		return false
+	}
+	if verbose, err := strconv.ParseBool(r.URL.Query().Get("verbose")); err == nil && verbose {
+		return true
+	}
+	return strings.Contains(r.Header.Get("Accept"), "application/json")
+}
+
+// serveHealth serves /healthz: "OK" for simple probes, or the structured
+// report of checks when asked for it. It predates /livez and is not an alias
+// of it. A failed check turns the report into a
+// 503 so orchestrators can act on it; warnings do not. Once a graceful
+// shutdown has started every request gets a 503, so the load balancer stops
+// routing here while in-flight requests drain.
+func serveHealth(w http.ResponseWriter, r *http.Request, checks ...func(context.Context) healthCheck) {
+	if shuttingDown.Load() {
+		http.Error(w, "Service Unavailable: shutting down", http.StatusServiceUnavailable)
+		return
+	}
+	if !wantsVerboseHealth(r) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("OK"))
+		return
+	}
+	results := make([]healthCheck, len(checks))
+	for i, check := range checks {
+		results[i] = check(r.Context())
+	}
+	report := newHealthReport(results)
+	status := http.StatusOK
+	if report.Status == healthFail {
+		slog.Warn("Health check failed", "checks", results)
+		status = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(report)
+}
+
+// metricsCheck returns the /healthz/probe metrics call as a health check,
+// bounded by timeout.
+func metricsCheck(timeout time.Duration) func(context.Context) healthCheck {
+	return func(ctx context.Context) healthCheck {
+		if err := runProbe(ctx, timeout); err != nil {
+			return healthCheck{Name: "metrics", Status: healthFail, Detail: err.Error()}
+		}
+		return healthCheck{Name: "metrics", Status: healthOK, Detail: "host metrics readable"}
+	}
+}
+
+// readOnlyImageFS are filesystem types that are always full by design, such
+// as snap packages and ISO images, and so never count against the disk check.
+var readOnlyImageFS = []string{"squashfs", "iso9660", "erofs", "udf"}
+
+// diskCheck returns a health check of the partitions in the disk report
+// against the HEALTH_DISK_WARN_PERCENT and HEALTH_DISK_FAIL_PERCENT
+// thresholds. The detail names the fullest partitions over a threshold.
+func diskCheck(warnPercent, failPercent float64, minTotalMB int) func(context.Context) healthCheck {
+	return func(ctx context.Context) healthCheck {
+		parts, err := reportPartitions(diskReportOptions{MinTotalMB: minTotalMB})
+		if err != nil {
+			return healthCheck{Name: "disk", Status: healthFail, Detail: err.Error()}
+		}
+		check := healthCheck{Name: "disk", Status: healthOK}
+		var over []string
+		for _, p := range parts {
+			if p.Error != "" || p.Total == 0 || slices.Contains(readOnlyImageFS, p.Fstype) {
+				continue
+			}
+			switch {
+			case p.Percent >= failPercent:
+				check.Status = healthFail
+			case p.Percent >= warnPercent:
+				if check.Status == healthOK {
+					check.Status = healthWarn
+				}
+			default:
+				continue
+			}
+			over = append(over, fmt.Sprintf("%s %.1f%%", p.Mountpoint, p.Percent))
+		}
+		if len(over) == 0 {
+			check.Detail = fmt.Sprintf("%d partitions below %.0f%% used", len(parts), warnPercent)
+		} else {
+			check.Detail = "over threshold: " + strings.Join(over, ", ")
+		}
+		return check
+	}
+}
+
+// bearerTokenCheck reports whether requests are authenticated at all: without
+// MCP_BEARER_TOKEN every caller gets the primary tier.
+func bearerTokenCheck(token string) func(context.Context) healthCheck {
+	return func(context.Context) healthCheck {
+		if token == "" {
+			return healthCheck{Name: "bearer_token", Status: healthWarn, Detail: "MCP_BEARER_TOKEN not set; requests are not authenticated"}
+		}
+		return healthCheck{Name: "bearer_token", Status: healthOK, Detail: "configured"}
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync/atomic"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// clientLogHandler is a slog.Handler that forwards records to every connected
+// MCP client session as logging notifications. Per the MCP specification, a
+// client only receives messages after it has called logging/setLevel.
+type clientLogHandler struct {
+	name   string
+	level  slog.Leveler
+	server *atomic.Pointer[mcp.Server]
+	ops    []func(slog.Handler) slog.Handler
+}
+
+func newClientLogHandler(name string, level slog.Leveler) *clientLogHandler {
+	return &clientLogHandler{name: name, level: level, server: new(atomic.Pointer[mcp.Server])}
+}
+
+// attach sets the server whose sessions receive forwarded records. Records
+// logged before a server is attached are only written to stderr.
+func (h *clientLogHandler) attach(s *mcp.Server) {
+	h.server.Store(s)
+}
+
+func (h *clientLogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level() && h.server.Load() != nil
+}
+
+func (h *clientLogHandler) Handle(ctx context.Context, r slog.Record) error {
+	s := h.server.Load()
+	if s == nil {
+		return nil
+	}
+	for ss := range s.Sessions() {
+		var handler slog.Handler = mcp.NewLoggingHandler(ss, &mcp.LoggingHandlerOptions{LoggerName: h.name})
+		for _, op := range h.ops {
+			handler = op(handler)
+		}
+		if handler.Enabled(ctx, r.Level) {
+			handler.Handle(ctx, r.Clone())
+		}
+	}
+	return nil
+}
+
+func (h *clientLogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.with(func(next slog.Handler) slog.Handler { return next.WithAttrs(attrs) })
+}
+
+func (h *clientLogHandler) WithGroup(name string) slog.Handler {
+	return h.with(func(next slog.Handler) slog.Handler { return next.WithGroup(name) })
+}
+
+func (h *clientLogHandler) with(op func(slog.Handler) slog.Handler) *clientLogHandler {
+	return &clientLogHandler{
+		name:   h.name,
+		level:  h.level,
+		server: h.server,
+		ops:    append(slices.Clip(h.ops), op),
+	}
+}
+
+// setupClientLogging installs a default logger that writes to stderr and, when
+// MCP_CLIENT_LOGGING is enabled, also forwards to MCP clients. The returned
+// handler must be attached to the server once it is created; it is nil when
+// client logging is disabled.
+func setupClientLogging(cfg *Config, name string) *clientLogHandler {
+	if !cfg.ClientLogging {
+		return nil
+	}
+	clientLogs := newClientLogHandler(name, cfg.ClientLogLevel)
+	stderr := slog.Default().Handler()
+	slog.SetDefault(slog.New(slog.NewMultiHandler(stderr, clientLogs)))
+	slog.Info("MCP client logging enabled", "level", cfg.ClientLogLevel.String())
+	return clientLogs
+}
@@ -1,117 +1,259 @@
 package main
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
 	"runtime"
 	"strings"
-	"sync"
+	"syscall"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
-	"github.com/shirou/gopsutil/v3/cpu"
-	"github.com/shirou/gopsutil/v3/disk"
-	"github.com/shirou/gopsutil/v3/host"
-	"github.com/shirou/gopsutil/v3/mem"
-	"github.com/shirou/gopsutil/v3/net"
 )
 
-const MiB = 1024 * 1024
+// systemInfoInput is the local_system_info tool input.
+type systemInfoInput struct {
+	SoftDeadlineMS int    `json:"soft_deadline_ms,omitempty" jsonschema:"return the sections finished after this many milliseconds instead of waiting for all of them; 0 (default) waits"`
+	IncludeIdle    bool   `json:"include_idle,omitempty" jsonschema:"also list network interfaces with no RX or TX traffic"`
+	Timezone       string `json:"timezone,omitempty" jsonschema:"IANA time zone for timestamps, e.g. Europe/Berlin (default UTC)"`
+	IfaceInclude   string `json:"iface_include,omitempty" jsonschema:"only list network interfaces whose name matches this regular expression (overrides NET_IFACE_INCLUDE)"`
+	IfaceExclude   string `json:"iface_exclude,omitempty" jsonschema:"hide network interfaces whose name matches this regular expression (overrides NET_IFACE_EXCLUDE)"`
+	SwapSampleMS   int    `json:"swap_sample_ms,omitempty" jsonschema:"sample swap-in/out rates over this many milliseconds (at most MAX_SAMPLE_INTERVAL, default 5000, and never over 10000) and flag active swapping; 0 (default) skips"`
+	Format         string `json:"format,omitempty" jsonschema:"output format: text (default) or markdown"`
+	ResolveVendor  bool   `json:"resolve_vendor,omitempty" jsonschema:"annotate each interface MAC address with its vendor from the OUI table"`
+	MemoryDetail   bool   `json:"memory_detail,omitempty" jsonschema:"break used memory down into free, available, buffers, cached and shared where the platform reports them"`
+	MaxLines       int    `json:"max_lines,omitempty" jsonschema:"fit the report into this many lines, leaving out the least important sections first; 0 (default) is unlimited"`
+	MaxChars       int    `json:"max_chars,omitempty" jsonschema:"fit the report into this many characters, leaving out the least important sections first; 0 (default) is unlimited"`
+}
+
+// validate rejects inputs that options would otherwise silently adjust.
+func (in systemInfoInput) validate() error {
+	if in.SoftDeadlineMS < 0 {
+		return fmt.Errorf("%w: soft_deadline_ms %d must not be negative", errInvalidInput, in.SoftDeadlineMS)
+	}
+	if _, err := parseTimezone(in.Timezone); err != nil {
+		return err
+	}
+	if _, err := (interfaceFilter{}).withOverrides(in.IfaceInclude, in.IfaceExclude); err != nil {
+		return fmt.Errorf("%w: %w", errInvalidInput, err)
+	}
+	if in.SwapSampleMS < 0 || int64(in.SwapSampleMS) > maxSwapSample.Milliseconds() {
+		return fmt.Errorf("%w: swap_sample_ms %d must be between 0 and %d", errInvalidInput, in.SwapSampleMS, maxSwapSample.Milliseconds())
+	}
+	if in.Format != "" && in.Format != "text" && in.Format != "markdown" {
+		return fmt.Errorf("%w: unsupported format %q: must be text or markdown", errInvalidInput, in.Format)
+	}
+	if in.MaxLines < 0 || in.MaxChars < 0 {
+		return fmt.Errorf("%w: max_lines and max_chars must not be negative", errInvalidInput)
+	}
+	return nil
+}
+
+// options builds the report options on top of the configured interface
+// filter, retry policy and section priority. Inputs rejected by validate fall
+// back to the defaults here.
+func (in systemInfoInput) options(filter interfaceFilter, retry sectionRetry, priority []string) systemInfoOptions {
+	loc, err := parseTimezone(in.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	if f, err := filter.withOverrides(in.IfaceInclude, in.IfaceExclude); err == nil {
+		filter = f
+	}
+	return systemInfoOptions{
+		SoftDeadline: time.Duration(max(in.SoftDeadlineMS, 0)) * time.Millisecond,
+		IncludeIdle:  in.IncludeIdle,
+		Location:     loc,
+		Interfaces:   filter,
+		SwapSample:   time.Duration(min(max(in.SwapSampleMS, 0), int(maxSwapSample.Milliseconds()))) * time.Millisecond,
+		Markdown:     in.Format == "markdown",
+		Retry:        retry,
+		Vendors:      in.ResolveVendor,
+		MemoryDetail: in.MemoryDetail,
+		Budget:       reportBudget{MaxLines: max(in.MaxLines, 0), MaxChars: max(in.MaxChars, 0)},
+		Priority:     priority,
+	}
+}
+
+// systemInfoOptions controls what the system report includes. The zero value
+// waits for every section, lists only interfaces that carried traffic, with no
+// name filter, does not sample swap activity, retry failed sections or
+// resolve MAC vendors or break memory down, prints timestamps in UTC as plain text and has no size
+// budget.
+type systemInfoOptions struct {
+	SoftDeadline time.Duration
+	IncludeIdle  bool
+	Location     *time.Location
+	Interfaces   interfaceFilter
+	SwapSample   time.Duration
+	Markdown     bool
+	Retry        sectionRetry
+	Vendors      bool
+	MemoryDetail bool
+	Budget       reportBudget
+	Priority     []string
+	Source       MetricsSource
+}
 
-func collectSystemInfo() string {
+// systemSections returns the parts of the system report, collected
+// concurrently and rendered in this order.
+func systemSections(opts systemInfoOptions) []reportSection {
+	src := metricsSourceOr(opts.Source)
+	return []reportSection{
+		{"Host", func() (string, error) { return hostSection(src, opts.Location) }},
+		{"Deployment", func() (string, error) { return deploymentSection(true) }},
+		{"CPU", func() (string, error) { return cpuSection(src) }},
+		{"Memory", func() (string, error) { return memorySection(src, opts.SwapSample, opts.MemoryDetail) }},
+		{"Network", func() (string, error) { return networkSection(src, opts.IncludeIdle, opts.Interfaces, opts.Vendors) }},
+		{"Power", powerSection},
+	}
+}
+
+// systemSectionNames are the names of systemSections, which
+// REPORT_SECTION_PRIORITY orders.
+var systemSectionNames = []string{"Host", "Deployment", "CPU", "Memory", "Network", "Power"}
+
+// collectSystemInfo renders the system report. With a non-zero SoftDeadline,
+// sections not finished by then are left out and the report says so. With a
+// Budget, the least important sections by Priority are left out to fit it,
+// as fitSections describes. With Markdown set, the report is converted by
+// markdownReport.
+func collectSystemInfo(ctx context.Context, debugTiming bool, opts systemInfoOptions) string {
+	timer := newSectionTimer(debugTiming)
 	var sb strings.Builder
 	fmt.Fprintln(&sb, "System Information Report")
 	fmt.Fprintln(&sb, "=========================")
 	fmt.Fprintln(&sb)
 
+	header := sb.String()
+	results := collectSectionsRetry(ctx, systemSections(opts), opts.SoftDeadline, opts.Retry)
+	for _, r := range results {
+		if r.done {
+			timer.record(r.name, r.duration)
+		}
+	}
+	return fitSections(results, opts.Priority, opts.Budget, func(kept []sectionResult, omitted []string) string {
+		var out strings.Builder
+		out.WriteString(header)
+		for _, r := range kept {
+			if r.done {
+				out.WriteString(r.text)
+			}
+		}
+		out.WriteString(truncationNote(kept, opts.SoftDeadline))
+		out.WriteString(budgetNote(omitted))
+		out.WriteString(timer.report())
+		if opts.Markdown {
+			return markdownReport(out.String())
+		}
+		return out.String()
+	})
+}
+
+func hostSection(src MetricsSource, loc *time.Location) (string, error) {
+	var sb strings.Builder
 	fmt.Fprintln(&sb, "System Information")
 	fmt.Fprintln(&sb, "------------------")
 	fmt.Fprintf(&sb, "System Name:      %s\n", runtime.GOOS)
-	if hInfo, err := host.Info(); err == nil {
+	hInfo, err := src.HostInfo()
+	if err == nil {
 		fmt.Fprintf(&sb, "OS Name:          %s\n", hInfo.OS)
 		fmt.Fprintf(&sb, "Host Name:        %s\n", hInfo.Hostname)
+		fmt.Fprintf(&sb, "Boot Time:        %s\n", formatTimestamp(time.Unix(int64(hInfo.BootTime), 0), loc))
+		fmt.Fprintf(&sb, "Server Time:      %s\n", formatTimestamp(time.Now(), loc))
 	} else {
 		fmt.Fprintf(&sb, "OS/Host Info:     Error: %v\n", err)
 	}
+	return sb.String(), err
+}
 
+func cpuSection(src MetricsSource) (string, error) {
+	var sb strings.Builder
 	fmt.Fprintln(&sb, "\nCPU Information")
 	fmt.Fprintln(&sb, "---------------")
-	if cpuCount, err := cpu.Counts(true); err == nil {
+	cpuCount, err := src.CPUCounts(true)
+	if err == nil {
 		fmt.Fprintf(&sb, "Number of Cores:  %d\n", cpuCount)
+		if vcpu, ok := cgroupCPULimit(); ok {
+			fmt.Fprintf(&sb, "Allocated CPU:    %.2f vCPU (cgroup quota)\n", vcpu)
+		}
 	} else {
 		fmt.Fprintf(&sb, "CPU Info:         Error: %v\n", err)
 	}
+	return sb.String(), err
+}
 
+// memorySection renders the Memory section. With detail set, used memory is
+// broken down by memoryBreakdown.
+func memorySection(src MetricsSource, swapSample time.Duration, detail bool) (string, error) {
+	var sb strings.Builder
 	fmt.Fprintln(&sb, "\nMemory Information")
 	fmt.Fprintln(&sb, "------------------")
-	if vMem, err := mem.VirtualMemory(); err == nil {
-		fmt.Fprintf(&sb, "Total Memory:     %d MB\n", vMem.Total/MiB)
-		fmt.Fprintf(&sb, "Used Memory:      %d MB\n", vMem.Used/MiB)
+	vMem, err := src.VirtualMemory()
+	if err == nil {
+		fmt.Fprintf(&sb, "Total Memory:     %s\n", formatBytes(vMem.Total, unitsIEC))
+		fmt.Fprintf(&sb, "Used Memory:      %s\n", formatBytes(vMem.Used, unitsIEC))
+		if detail {
+			sb.WriteString(memoryBreakdown(vMem))
+		}
 	} else {
 		fmt.Fprintf(&sb, "Memory Info:      Error: %v\n", err)
 	}
-	if sMem, err := mem.SwapMemory(); err == nil {
-		fmt.Fprintf(&sb, "Total Swap:       %d MB\n", sMem.Total/MiB)
-		fmt.Fprintf(&sb, "Used Swap:        %d MB\n", sMem.Used/MiB)
+	if sMem, err := src.SwapMemory(); err == nil {
+		fmt.Fprintf(&sb, "Total Swap:       %s\n", formatBytes(sMem.Total, unitsIEC))
+		fmt.Fprintf(&sb, "Used Swap:        %s\n", formatBytes(sMem.Used, unitsIEC))
+	}
+	if swapSample > 0 {
+		sb.WriteString(swapActivity(swapSample))
 	}
+	return sb.String(), err
+}
 
+func networkSection(src MetricsSource, includeIdle bool, filter interfaceFilter, resolveVendor bool) (string, error) {
+	var sb strings.Builder
 	fmt.Fprintln(&sb, "\nNetwork Interfaces")
 	fmt.Fprintln(&sb, "------------------")
-	interfaces, err := net.Interfaces()
-	if err != nil {
+	interfaces, err := collectInterfaces(src)
+	if resolveVendor {
+		resolveVendors(interfaces)
+	}
+	if err == nil {
+		sb.WriteString(filter.format(interfaces, includeIdle))
+	} else {
 		fmt.Fprintf(&sb, "Network Info:     Error fetching interfaces: %v\n", err)
-		return sb.String()
-	}
-
-	ioCounters, _ := net.IOCounters(true)
-	for _, inter := range interfaces {
-		var rx, tx uint64
-		found := false
-		for _, io := range ioCounters {
-			if io.Name == inter.Name {
-				rx = io.BytesRecv
-				tx = io.BytesSent
-				found = true
-				break
-			}
-		}
-		mac := inter.HardwareAddr
-		if mac == "" {
-			mac = "unknown"
-		}
-		if found {
-			fmt.Fprintf(&sb, "%-18s: RX: %10d bytes, TX: %10d bytes (MAC: %s)\n", inter.Name, rx, tx, mac)
-		} else {
-			fmt.Fprintf(&sb, "%-18s: (No IO stats) (MAC: %s)\n", inter.Name, mac)
-		}
 	}
+	return sb.String(), err
+}
 
-	return sb.String()
+func collectDiskUsage(opts diskReportOptions) string {
+	partitions, err := reportPartitions(opts)
+	if err != nil {
+		return fmt.Sprintf("Disk Usage Report\n=================\n\nError fetching partitions: %v\n", err)
+	}
+	return formatDiskUsage(partitions, opts)
 }
 
-func collectDiskUsage() string {
+// formatDiskUsage renders partitions as the text disk usage report.
+func formatDiskUsage(partitions []partitionUsage, opts diskReportOptions) string {
 	var sb strings.Builder
 	fmt.Fprintln(&sb, "Disk Usage Report")
 	fmt.Fprintln(&sb, "=================")
 	fmt.Fprintln(&sb)
 
-	partitions, err := disk.Partitions(false)
-	if err != nil {
-		fmt.Fprintf(&sb, "Error fetching partitions: %v\n", err)
-		return sb.String()
-	}
-
 	for _, p := range partitions {
-		usage, err := disk.Usage(p.Mountpoint)
-		if err == nil {
-			usedMB := usage.Used / MiB
-			totalMB := usage.Total / MiB
-			fmt.Fprintf(&sb, "%-20s %-10s %10d / %10d MB used (%.1f%%)\n",
-				p.Mountpoint, p.Fstype, usedMB, totalMB, usage.UsedPercent)
+		if p.Error == "" {
+			fmt.Fprintln(&sb, p.reportLine(opts))
 		}
 	}
+	if len(partitions) == 0 {
+		fmt.Fprint(&sb, noPartitionsNote)
+	}
 	return sb.String()
 }
 
@@ -124,92 +266,414 @@ func isTTY() bool {
 }
 
 func main() {
-	slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stderr, nil)))
-	slog.Info("APP_STARTING")
+	slog.SetDefault(slog.New(newLogHandler(os.Stderr, cloudLoggingEnabled())))
+	build := currentBuild()
+	slog.Info("APP_STARTING", "version", build.Version, "commit", build.Commit, "build_date", build.BuildDate)
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	cfg, err := loadConfig()
+	if err != nil {
+		slog.Error("Invalid configuration", "error", err)
+		os.Exit(exitConfig)
 	}
-
-	bearerToken := os.Getenv("MCP_BEARER_TOKEN")
-	if bearerToken != "" {
+	// .env may have set LOG_CLOUD_LOGGING or LOG_OUTPUT after the logger was
+	// created
+	logOut, logOutErr := logOutput(cfg.LogOutput)
+	slog.SetDefault(slog.New(newLogHandler(logOut, cfg.CloudLogging)))
+	if logOutErr != nil {
+		slog.Warn("Invalid LOG_OUTPUT; logging to stderr", "error", logOutErr)
+	}
+	if cfg.BearerToken != "" {
 		slog.Info("MCP_BEARER_TOKEN found")
 	}
 
 	if len(os.Args) <= 1 {
-		runServer(port, bearerToken)
+		runServer(cfg)
 		return
 	}
 
-	handleCLI(os.Args[1], bearerToken)
+	handleCLI(os.Args[1], cfg)
 }
 
-func runServer(port, bearerToken string) {
-	slog.Info("Entering Server Mode", "port", port, "auth_enabled", bearerToken != "")
+// newHandler builds the public HTTP handler: health checks, the bearer token
+// check, and the routes behind it. The second handler serves only the admin
+// endpoints, without the token check, for the ADMIN_PORT listener. It does not
+// bind a port, so tests can drive it directly.
+func newHandler(cfg *Config, clientLogs *clientLogHandler) (http.Handler, http.Handler) {
+	bearerToken := cfg.BearerToken
+	srv := &lazyServer{build: func() (*mcp.Server, error) {
+		slog.Info("Lazy Initialization started")
+		server := mcp.NewServer(&mcp.Implementation{Name: "bearer-go", Version: "1.0.0"}, nil)
+		if clientLogs != nil {
+			clientLogs.attach(server)
+		}
+		server.AddReceivingMiddleware(resultSizeMiddleware(cfg.MaxResultBytes))
+		server.AddReceivingMiddleware(reportSigningMiddleware(cfg.ReportSigningKey))
+		server.AddReceivingMiddleware(toolRateLimitMiddleware(newToolRateLimiter(cfg.ToolRateLimits, cfg.Tools)))
+		server.AddReceivingMiddleware(toolTierMiddleware(cfg))
+
+		addTool(server, cfg.Tools, &mcp.Tool{Name: "local_system_info", Description: "System info", Annotations: readOnlyTool(true)},
+			func(ctx context.Context, request *mcp.CallToolRequest, input systemInfoInput) (*mcp.CallToolResult, any, error) {
+				if err := checkSampleInterval("swap_sample_ms", input.SwapSampleMS, cfg.MaxSampleInterval); err != nil {
+					return nil, nil, toolError(err)
+				}
+				_, span := tracer.Start(ctx, "collectSystemInfo")
+				defer span.End()
+				return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: collectSystemInfo(ctx, cfg.DebugTiming, input.options(cfg.IfaceFilter, cfg.sectionRetry(), cfg.SectionPriority))}}}, nil, nil
+			})
+
+		addTool(server, cfg.Tools, &mcp.Tool{Name: "system_info_json", Description: "Host, CPU, memory, swap and network interfaces as one JSON object with a collected_at timestamp", Annotations: readOnlyTool(false)},
+			func(ctx context.Context, request *mcp.CallToolRequest, input systemInfoJSONInput) (*mcp.CallToolResult, any, error) {
+				report, err := systemInfoJSONReport(systemInfoOptions{IncludeIdle: input.IncludeIdle, Interfaces: cfg.IfaceFilter})
+				if err != nil {
+					return nil, nil, toolError(err)
+				}
+				return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: report}}}, nil, nil
+			})
+
+		addTool(server, cfg.Tools, &mcp.Tool{Name: "runtime_info", Description: "Go runtime details", Annotations: readOnlyTool(false)},
+			func(ctx context.Context, request *mcp.CallToolRequest, input emptyInput) (*mcp.CallToolResult, any, error) {
+				return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: collectRuntimeInfo()}}}, nil, nil
+			})
+
+		addTool(server, cfg.Tools, &mcp.Tool{Name: "cpu_load", Description: "Aggregate and per-core CPU utilization sampled over an interval", Annotations: readOnlyTool(false)},
+			func(ctx context.Context, request *mcp.CallToolRequest, input cpuLoadInput) (*mcp.CallToolResult, any, error) {
+				if err := checkSampleInterval("interval_ms", input.IntervalMS, cfg.MaxSampleInterval); err != nil {
+					return nil, nil, toolError(err)
+				}
+				return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: cpuLoadReport(ctx, cpuLoadInterval(input.IntervalMS, cfg.MaxSampleInterval))}}}, nil, nil
+			})
+
+		addTool(server, cfg.Tools, &mcp.Tool{Name: "disk_usage", Description: "Disk usage", Annotations: readOnlyTool(false)},
+			func(ctx context.Context, request *mcp.CallToolRequest, input diskUsageInput) (*mcp.CallToolResult, any, error) {
+				_, span := tracer.Start(ctx, "collectDiskUsage")
+				defer span.End()
+				report, err := diskUsageReport(input.Format, input.options(cfg.DiskMinTotalMB))
+				if err != nil {
+					return nil, nil, toolError(err)
+				}
+				return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: report}}}, nil, nil
+			})
 
-	var (
-		server     *mcp.Server
-		once       sync.Once
-		initServer = func() {
-			once.Do(func() {
-				slog.Info("Lazy Initialization started")
-				server = mcp.NewServer(&mcp.Implementation{Name: "bearer-go", Version: "1.0.0"}, nil)
-				type empty struct{}
+		addTool(server, cfg.Tools, &mcp.Tool{Name: "disk_latency", Description: "Time a stat call per mount to find slow or hung mounts", Annotations: readOnlyTool(false)},
+			func(ctx context.Context, request *mcp.CallToolRequest, input diskLatencyInput) (*mcp.CallToolResult, any, error) {
+				threshold, timeout := input.durations()
+				report, err := collectDiskLatency(ctx, threshold, timeout)
+				if err != nil {
+					return nil, nil, toolError(err)
+				}
+				return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: report}}}, nil, nil
+			})
 
-				mcp.AddTool(server, &mcp.Tool{Name: "local_system_info", Description: "System info"},
-					func(ctx context.Context, request *mcp.CallToolRequest, input empty) (*mcp.CallToolResult, any, error) {
-						return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: collectSystemInfo()}}}, nil, nil
-					})
+		addTool(server, cfg.Tools, &mcp.Tool{Name: "full_report", Description: "System info, disk usage and optionally top processes as one JSON object", Annotations: readOnlyTool(false)},
+			func(ctx context.Context, request *mcp.CallToolRequest, input fullReportInput) (*mcp.CallToolResult, any, error) {
+				report, err := collectFullReport(ctx, input.sections(), fullReportOptions{
+					IncludeIdle:     input.IncludeIdle,
+					Interfaces:      cfg.IfaceFilter,
+					Disk:            diskReportOptions{MinTotalMB: cfg.DiskMinTotalMB},
+					ProcessWorkers:  cfg.ProcessWorkers,
+					ProcessDeadline: cfg.ProcessDeadline,
+				})
+				if err != nil {
+					return nil, nil, toolError(err)
+				}
+				return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: report}}}, nil, nil
+			})
 
-				mcp.AddTool(server, &mcp.Tool{Name: "disk_usage", Description: "Disk usage"},
-					func(ctx context.Context, request *mcp.CallToolRequest, input empty) (*mcp.CallToolResult, any, error) {
-						return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: collectDiskUsage()}}}, nil, nil
-					})
-				slog.Info("Lazy Initialization complete")
+		addTool(server, cfg.Tools, &mcp.Tool{Name: "top_processes", Description: "Running processes by memory, CPU, open descriptors or name, one page at a time", Annotations: readOnlyTool(false)},
+			func(ctx context.Context, request *mcp.CallToolRequest, input topProcessesInput) (*mcp.CallToolResult, any, error) {
+				ctx, cancel := context.WithTimeout(ctx, cfg.ProcessDeadline)
+				defer cancel()
+				report, err := collectProcessPage(ctx, cfg.ProcessWorkers, input, cfg.ProcessPageMax)
+				if err != nil {
+					return nil, nil, toolError(err)
+				}
+				return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: report}}}, nil, nil
 			})
+
+		addTool(server, cfg.Tools, &mcp.Tool{Name: "list_processes", Description: "Running processes with PID, name, resident memory and CPU percent, sorted by memory, CPU or PID", Annotations: readOnlyTool(false)},
+			func(ctx context.Context, request *mcp.CallToolRequest, input listProcessesInput) (*mcp.CallToolResult, any, error) {
+				if input.Limit > cfg.ProcessPageMax {
+					return nil, nil, toolError(fmt.Errorf("%w: limit %d exceeds PROCESS_PAGE_MAX %d", errInvalidInput, input.Limit, cfg.ProcessPageMax))
+				}
+				ctx, cancel := context.WithTimeout(ctx, cfg.ProcessDeadline)
+				defer cancel()
+				return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: listProcesses(ctx, cfg.ProcessWorkers, input.SortBy, input.Limit)}}}, nil, nil
+			})
+
+		addTool(server, cfg.Tools, &mcp.Tool{Name: "fd_usage", Description: "Processes with the most open file descriptors and system-wide descriptor usage", Annotations: readOnlyTool(false)},
+			func(ctx context.Context, request *mcp.CallToolRequest, input emptyInput) (*mcp.CallToolResult, any, error) {
+				ctx, cancel := context.WithTimeout(ctx, cfg.ProcessDeadline)
+				defer cancel()
+				return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: collectFDUsage(ctx, cfg.ProcessWorkers)}}}, nil, nil
+			})
+
+		if cfg.PortsEnabled {
+			addTool(server, cfg.Tools, &mcp.Tool{Name: "listening_ports", Description: "Listening TCP ports with the owning process", Annotations: readOnlyTool(false)},
+				func(ctx context.Context, request *mcp.CallToolRequest, input emptyInput) (*mcp.CallToolResult, any, error) {
+					ctx, cancel := context.WithTimeout(ctx, cfg.ProcessDeadline)
+					defer cancel()
+					return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: collectListeningPorts(ctx)}}}, nil, nil
+				})
 		}
-	)
+		if cfg.SessionsEnabled {
+			addTool(server, cfg.Tools, &mcp.Tool{Name: "sessions_info", Description: "Logged-in users with their terminal, remote host and login time", Annotations: readOnlyTool(false)},
+				func(ctx context.Context, request *mcp.CallToolRequest, input emptyInput) (*mcp.CallToolResult, any, error) {
+					return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: collectSessions(ctx)}}}, nil, nil
+				})
+		}
+		if cfg.DiskHealthEnabled {
+			addTool(server, cfg.Tools, &mcp.Tool{Name: "disk_health", Description: "SMART health, reallocated sectors and temperature of each disk smartctl can read", Annotations: readOnlyTool(false)},
+				func(ctx context.Context, request *mcp.CallToolRequest, input emptyInput) (*mcp.CallToolResult, any, error) {
+					return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: collectDiskHealth(ctx)}}}, nil, nil
+				})
+		}
+		if cfg.DNSInfoEnabled {
+			addTool(server, cfg.Tools, &mcp.Tool{Name: "dns_info", Description: "Configured DNS nameservers and search domains, with an optional test resolution", Annotations: readOnlyTool(true)},
+				func(ctx context.Context, request *mcp.CallToolRequest, input dnsInfoInput) (*mcp.CallToolResult, any, error) {
+					return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: dnsInfoReport(ctx, cmp.Or(input.Hostname, cfg.DNSTestHost))}}}, nil, nil
+				})
+		}
+		addTool(server, cfg.Tools, &mcp.Tool{Name: "deployment_info", Description: "Cloud Run service, revision and configuration, with the region and zone from the metadata server", Annotations: readOnlyTool(true)},
+			func(ctx context.Context, request *mcp.CallToolRequest, input emptyInput) (*mcp.CallToolResult, any, error) {
+				return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: deploymentReport(true)}}}, nil, nil
+			})
+		addTool(server, cfg.Tools, &mcp.Tool{Name: "baseline_check", Description: "Current CPU, memory, swap, disk and load compared against the BASELINES_FILE thresholds", Annotations: readOnlyTool(false)},
+			func(ctx context.Context, request *mcp.CallToolRequest, input emptyInput) (*mcp.CallToolResult, any, error) {
+				report, err := baselineCheckReport(ctx, cfg.BaselinesFile, diskReportOptions{MinTotalMB: cfg.DiskMinTotalMB})
+				if err != nil {
+					return nil, nil, toolError(err)
+				}
+				return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: report}}}, nil, nil
+			})
+
+		if cfg.LogTailEnabled {
+			addTool(server, cfg.Tools, &mcp.Tool{Name: "recent_logs", Description: "Last lines of the host system log", Annotations: readOnlyTool(false)},
+				func(ctx context.Context, request *mcp.CallToolRequest, input recentLogsInput) (*mcp.CallToolResult, any, error) {
+					report, err := recentLogsReport(cfg.LogTailFile, input.Lines)
+					if err != nil {
+						return nil, nil, toolError(err)
+					}
+					return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: report}}}, nil, nil
+				})
+		}
+		slog.Info("Lazy Initialization complete")
+		return server, nil
+	}}
 
 	mcpHandler := mcp.NewStreamableHTTPHandler(func(r *http.Request) *mcp.Server {
-		initServer()
+		server, _ := srv.get()
 		return server
 	}, nil)
+	gzipHandler := srv.require(withGzipRequest(withRateClient(mcpHandler), cfg.MaxDecompressed))
 
+	stats := newRequestStats()
+	debugLoad := serveDebugLoad(debugLoadTargets(cfg))
 	mux := http.NewServeMux()
 	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path == "/" || r.URL.Path == "/healthz" {
 			slog.Info("Health check received")
-			w.WriteHeader(http.StatusOK)
-			w.Write([]byte("OK"))
+			serveHealth(w, r, metricsCheck(cfg.ProbeTimeout), bearerTokenCheck(bearerToken), diskCheck(cfg.DiskWarnPercent, cfg.DiskFailPercent, cfg.DiskMinTotalMB))
+			return
+		}
+		if r.URL.Path == "/livez" {
+			serveLive(w)
+			return
+		}
+		if r.URL.Path == "/healthz/probe" {
+			serveHealthProbe(w, r, cfg.ProbeTimeout)
+			return
+		}
+		if r.URL.Path == "/readyz" {
+			srv.serveReady(w, r)
 			return
 		}
+		if cfg.AdminPort != "" && isAdminPath(r.URL.Path) {
+			http.NotFound(w, r)
+			return
+		}
+		stats.total.Add(1)
 
+		tier := tierPrimary
 		if bearerToken != "" {
-			authHeader := r.Header.Get("Authorization")
-			if !strings.HasPrefix(authHeader, "Bearer ") || strings.TrimPrefix(authHeader, "Bearer ") != bearerToken {
+			tier = tokenTier(cfg, r.Header.Get("Authorization"))
+			if tier == "" {
 				slog.Warn("Unauthorized request")
+				stats.unauthorized.Add(1)
 				http.Error(w, "Unauthorized", http.StatusUnauthorized)
 				return
 			}
+			slog.Info("Request authenticated", "tier", tier)
+			r = withVerifiedClient(r, "tier "+tier)
 		}
 
-		mcpHandler.ServeHTTP(w, r)
+		switch r.URL.Path {
+		case "/report/disk":
+			if err := toolAccess(cfg, tier, cfg.Tools.name("disk_usage")); err != nil {
+				slog.Warn("Disk report denied for token tier", "tier", tier)
+				http.Error(w, "Forbidden: the disk report requires access to the disk_usage tool", http.StatusForbidden)
+				return
+			}
+			serveDiskReport(w, r, cfg.DiskMinTotalMB)
+			return
+		case "/stats":
+			stats.serveStats(w, r)
+			return
+		case "/debug/load":
+			if !cfg.DebugLoad {
+				http.NotFound(w, r)
+				return
+			}
+			if tier != tierPrimary {
+				http.Error(w, "Forbidden: /debug/load requires the primary bearer token", http.StatusForbidden)
+				return
+			}
+			debugLoad(w, r)
+			return
+		}
+		gzipHandler.ServeHTTP(w, r)
 	})
 
-	slog.Info("Starting ListenAndServe", "address", "0.0.0.0:"+port)
-	if err := http.ListenAndServe("0.0.0.0:"+port, mux); err != nil {
+	admin := http.NewServeMux()
+	admin.HandleFunc("/stats", stats.serveStats)
+	admin.HandleFunc("/debug/load", func(w http.ResponseWriter, r *http.Request) {
+		if !cfg.DebugLoad {
+			http.NotFound(w, r)
+			return
+		}
+		debugLoad(w, r)
+	})
+	return mux, admin
+}
+
+func runServer(cfg *Config) {
+	port := cfg.Port
+	slog.Info("Entering Server Mode", "port", port, "auth_enabled", cfg.BearerToken != "")
+	if err := checkRoot(cfg.AllowRoot); err != nil {
+		slog.Error("Refusing to start", "error", err)
+		os.Exit(exitConfig)
+	}
+	applyAutoMaxprocs(cfg.AutoMaxprocs)
+	stopRefresh := func() {}
+	if cfg.BackgroundRefresh {
+		stopRefresh = startBackgroundRefresh(cfg.RefreshInterval)
+	}
+	stopWebhook := func() {}
+	if cfg.WebhookURL != "" {
+		stopWebhook = startReportWebhook(cfg)
+	}
+	if cfg.DebugLoad {
+		slog.Warn("DEBUG: /debug/load is enabled; it runs tool collectors in a tight loop on request. Do not leave it on in production")
+	}
+	if err := cfg.validateAuth(); err != nil {
+		slog.Error("Auth configuration check failed", "auth_mode", cfg.AuthMode, "error", err)
+		os.Exit(exitConfig)
+	}
+	slog.Info("Auth mode resolved", "auth_mode", cfg.AuthMode, "inferred", cfg.AuthModeInferred)
+	waitForDependencies(cfg.WaitForTCP, cfg.WaitForTimeout)
+	clientLogs := setupClientLogging(cfg, "bearer-go")
+
+	shutdownTracing, err := setupTracing(context.Background(), cfg, "bearer-go")
+	if err != nil {
+		slog.Error("Failed to set up tracing", "error", err)
+		os.Exit(exitFailure)
+	}
+	public, admin := newHandler(cfg, clientLogs)
+	handler := withTracing(withRequestLog(public, cfg.RequestLogSample, cfg.SlowThreshold), "bearer-go")
+
+	httpServer := &http.Server{Addr: "0.0.0.0:" + port, Handler: handler, MaxHeaderBytes: cfg.MaxHeaderBytes, ConnState: serverConns.track}
+	servers := []*http.Server{httpServer}
+	if cfg.AdminPort != "" {
+		adminServer := &http.Server{Addr: adminAddr(cfg.AdminPort), Handler: withRequestLog(admin, cfg.RequestLogSample, cfg.SlowThreshold)}
+		servers = append(servers, adminServer)
+		go func() {
+			slog.Info("Starting admin listener", "address", adminServer.Addr, "paths", adminPaths)
+			if err := adminServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				slog.Error("Admin listener failed", "error", err)
+				os.Exit(exitFailure)
+			}
+		}()
+	}
+	sigCtx, stopSignals := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stopSignals()
+	stopped := shutdownOnSignal(sigCtx, cfg.ShutdownDrain, cfg.ShutdownGrace, servers...)
+	var restart <-chan struct{}
+	if cfg.MaxUptime > 0 {
+		slog.Info("Scheduled restart for MAX_UPTIME", "max_uptime", cfg.MaxUptime, "restart_at", time.Now().Add(cfg.MaxUptime).Format(time.RFC3339))
+		restart = shutdownAfter(cfg.MaxUptime, cfg.ShutdownDrain, cfg.ShutdownGrace, "MAX_UPTIME reached", servers...)
+	}
+	if cfg.tlsEnabled() {
+		httpServer.TLSConfig = cfg.tlsConfig()
+		slog.Info("Starting ListenAndServeTLS", "address", httpServer.Addr, "min_version", cfg.TLSMinVersion, "cipher_profile", cfg.TLSCipherProfile)
+		err = listenAndServe(httpServer, cfg.TCPKeepAlive, cfg.ProxyProtocol, cfg.TLSCertFile, cfg.TLSKeyFile)
+	} else {
+		slog.Info("Starting ListenAndServe", "address", httpServer.Addr)
+		err = listenAndServe(httpServer, cfg.TCPKeepAlive, cfg.ProxyProtocol, "", "")
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		message := "Server stopped"
+		select {
+		case <-stopped:
+		case <-restart:
+			message = "Server stopped; exiting for restart"
+		}
+		stopWebhook()
+		stopRefresh()
+		shutdownTracing(context.Background())
+		slog.Info(message)
+		return
+	}
+	if err != nil {
 		slog.Error("ListenAndServe failed", "error", err)
-		os.Exit(1)
+		stopWebhook()
+		stopRefresh()
+		shutdownTracing(context.Background())
+		os.Exit(exitFailure)
 	}
 }
 
-func handleCLI(command, bearerToken string) {
+func handleCLI(command string, cfg *Config) {
+	if isHelp(command) {
+		printUsage(os.Stdout)
+		return
+	}
+	if isVersion(command) {
+		printVersion(os.Stdout, "bearer-go")
+		return
+	}
+	if command == "info" || command == "disk" {
+		target, forward, err := splitRemote(os.Args[2:])
+		if err != nil {
+			slog.Error("Invalid configuration", "error", err)
+			os.Exit(exitConfig)
+		}
+		if target != "" {
+			os.Exit(runRemote(target, cfg.RemoteBinary, append([]string{command}, forward...)))
+		}
+	}
+	bearerToken := cfg.BearerToken
 	switch command {
 	case "info":
-		fmt.Print(collectSystemInfo())
+		fmt.Print(collectSystemInfo(context.Background(), cfg.DebugTiming, systemInfoOptions{IncludeIdle: true, Interfaces: cfg.IfaceFilter, Retry: cfg.sectionRetry(), Vendors: hasFlag(os.Args[2:], "--resolve-vendor"), MemoryDetail: hasFlag(os.Args[2:], "--memory-detail")}))
 	case "disk":
-		fmt.Print(collectDiskUsage())
+		format := "text"
+		if hasFlag(os.Args[2:], "--json") {
+			format = "json"
+		}
+		report, err := diskUsageReport(format, diskReportOptions{
+			ShowDevice:     hasFlag(os.Args[2:], "--show-device"),
+			KeepDuplicates: hasFlag(os.Args[2:], "--keep-duplicates"),
+			MinTotalMB:     cfg.DiskMinTotalMB,
+			ExactBytes:     hasFlag(os.Args[2:], "--exact-bytes"),
+		})
+		if err != nil {
+			slog.Error("Failed to collect disk usage", "error", err)
+			os.Exit(exitCollection)
+		}
+		fmt.Print(report)
+	case "processes":
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.ProcessDeadline)
+		defer cancel()
+		fmt.Print(collectTopProcesses(ctx, cfg.ProcessWorkers))
 	case "check":
 		if isTTY() {
 			authMsg := "No Authentication Required"
@@ -220,8 +684,16 @@ func handleCLI(command, bearerToken string) {
 		} else {
 			slog.Info("System utilities available", "auth_enabled", bearerToken != "")
 		}
+	case "config":
+		out, err := formatConfig(cfg, hasFlag(os.Args[2:], "--json"))
+		if err != nil {
+			slog.Error("Failed to format configuration", "error", err)
+			os.Exit(exitFailure)
+		}
+		fmt.Print(out)
 	default:
-		fmt.Printf("Unknown command: %s\n", command)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Unknown command: %s\n\n", command)
+		printUsage(os.Stderr)
+		os.Exit(exitConfig)
 	}
 }
@@ -1,20 +1,102 @@
 package main
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
 	"strings"
 	"testing"
 )
 
 func TestCollectDiskUsage(t *testing.T) {
-	output := collectDiskUsage()
+	output := collectDiskUsage(diskReportOptions{})
 	if !strings.Contains(output, "Disk Usage Report") {
 		t.Errorf("Expected output to contain 'Disk Usage Report', got: %s", output)
 	}
 }
 
 func TestCollectSystemInfo(t *testing.T) {
-	output := collectSystemInfo()
+	output := collectSystemInfo(context.Background(), false, systemInfoOptions{})
 	if !strings.Contains(output, "System Information Report") {
 		t.Errorf("Expected output to contain 'System Information Report', got: %s", output)
 	}
 }
+
+func TestAuthBypassLimitedToHealthPaths(t *testing.T) {
+	handler, _ := newHandler(&Config{AuthMode: "bearer", BearerToken: "good-token", ReadonlyToken: "readonly-token"}, nil)
+
+	tests := []struct {
+		method, path, token string
+		want                int
+	}{
+		{http.MethodGet, "/", "", http.StatusOK},
+		{http.MethodGet, "/healthz", "", http.StatusOK},
+		{http.MethodGet, "/healthz/probe", "", http.StatusOK},
+		{http.MethodGet, "/livez", "", http.StatusOK},
+		{http.MethodGet, "/readyz", "", http.StatusOK},
+		{http.MethodPost, "/mcp", "", http.StatusUnauthorized},
+		{http.MethodPost, "/mcp", "bad-token", http.StatusUnauthorized},
+		{http.MethodGet, "/foo", "bad-token", http.StatusUnauthorized},
+		{http.MethodGet, "/healthz/", "", http.StatusUnauthorized},
+		{http.MethodGet, "/report/disk", "bad-token", http.StatusUnauthorized},
+		{http.MethodGet, "/report/disk", "good-token", http.StatusOK},
+		{http.MethodGet, "/stats", "bad-token", http.StatusUnauthorized},
+		{http.MethodGet, "/stats", "good-token", http.StatusOK},
+		{http.MethodGet, "/report/disk", "readonly-token", http.StatusForbidden},
+		{http.MethodGet, "/stats", "readonly-token", http.StatusOK},
+		{http.MethodGet, "/debug/load", "bad-token", http.StatusUnauthorized},
+		{http.MethodGet, "/debug/load", "good-token", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		if tt.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("%s %s (token %q): expected %d, got %d", tt.method, tt.path, tt.token, tt.want, rec.Code)
+		}
+	}
+}
+
+func TestBearerTokenAuthorization(t *testing.T) {
+	handler, _ := newHandler(&Config{AuthMode: "bearer", BearerToken: "good-token"}, nil)
+	for header, want := range map[string]int{
+		"Bearer good-token": http.StatusOK,
+		"Bearer good-tokem": http.StatusUnauthorized,
+		"Bearer good":       http.StatusUnauthorized,
+		"good-token":        http.StatusUnauthorized,
+		"Basic good-token":  http.StatusUnauthorized,
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/stats", nil)
+		req.Header.Set("Authorization", header)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Errorf("Authorization %q: expected %d, got %d", header, want, rec.Code)
+		}
+	}
+}
+
+func TestUsage(t *testing.T) {
+	for _, command := range []string{"info", "disk", "processes", "check", "config"} {
+		if !strings.Contains(usageText, "\n  "+command+" ") {
+			t.Errorf("Expected usage to list %q", command)
+		}
+	}
+	if !isHelp("--help") || !isHelp("-h") || !isHelp("help") || isHelp("info") {
+		t.Error("Expected help, -h and --help to be recognized as help")
+	}
+}
+
+func TestSystemSectionNames(t *testing.T) {
+	var names []string
+	for _, s := range systemSections(systemInfoOptions{}) {
+		names = append(names, s.name)
+	}
+	if !slices.Equal(names, systemSectionNames) {
+		t.Errorf("systemSectionNames %q does not match systemSections %q", systemSectionNames, names)
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/shirou/gopsutil/v3/host"
+	"github.com/shirou/gopsutil/v3/mem"
+	"github.com/shirou/gopsutil/v3/net"
+)
+
+// MetricsSource is where the system and disk reports read host metrics
+// from. gopsutilSource reads the real host; tests substitute a fake so that
+// formatting, filtering, sorting and error handling can be checked against
+// fixed values.
+type MetricsSource interface {
+	HostInfo() (*host.InfoStat, error)
+	CPUCounts(logical bool) (int, error)
+	VirtualMemory() (*mem.VirtualMemoryStat, error)
+	SwapMemory() (*mem.SwapMemoryStat, error)
+	Partitions(all bool) ([]disk.PartitionStat, error)
+	DiskUsage(mountpoint string) (*disk.UsageStat, error)
+	Interfaces() (net.InterfaceStatList, error)
+	IOCounters() ([]net.IOCountersStat, error)
+}
+
+// gopsutilSource is the MetricsSource of the running host. Partitions and
+// interfaces come from the background refresh snapshot when there is one.
+type gopsutilSource struct{}
+
+func (gopsutilSource) HostInfo() (*host.InfoStat, error)              { return host.Info() }
+func (gopsutilSource) CPUCounts(logical bool) (int, error)            { return cpu.Counts(logical) }
+func (gopsutilSource) VirtualMemory() (*mem.VirtualMemoryStat, error) { return mem.VirtualMemory() }
+func (gopsutilSource) SwapMemory() (*mem.SwapMemoryStat, error)       { return mem.SwapMemory() }
+func (gopsutilSource) Partitions(all bool) ([]disk.PartitionStat, error) {
+	return listPartitions(all)
+}
+func (gopsutilSource) DiskUsage(mountpoint string) (*disk.UsageStat, error) {
+	return statUsage(mountpoint)
+}
+func (gopsutilSource) Interfaces() (net.InterfaceStatList, error) { return cachedInterfaces() }
+func (gopsutilSource) IOCounters() ([]net.IOCountersStat, error)  { return net.IOCounters(true) }
+
+// metricsSourceOr returns s, or gopsutilSource when s is nil, so that the
+// zero report options read the real host.
+func metricsSourceOr(s MetricsSource) MetricsSource {
+	if s == nil {
+		return gopsutilSource{}
+	}
+	return s
+}
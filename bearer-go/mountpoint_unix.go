@@ -0,0 +1,22 @@
+//go:build unix
+
+package main
+
+import (
+	"path/filepath"
+	"syscall"
+)
+
+// isMountpoint reports whether path is the root of a mounted filesystem: "/"
+// or a path on a different device than its parent directory. Bind mounts of
+// a directory within the same filesystem are not detected.
+func isMountpoint(path string) (bool, error) {
+	var st, parent syscall.Stat_t
+	if err := syscall.Stat(path, &st); err != nil {
+		return false, err
+	}
+	if err := syscall.Stat(filepath.Join(path, ".."), &parent); err != nil {
+		return false, err
+	}
+	return st.Dev != parent.Dev || st.Ino == parent.Ino, nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+)
+
+// embeddedOUI is a compact vendor table so resolve_vendor works offline.
+//
+//go:embed oui.txt
+var embeddedOUI string
+
+// ouiVendors maps an upper-case six-digit OUI to its vendor. It is built from
+// the embedded table at init and extended by loadOUIFile before serving.
+var ouiVendors = func() map[string]string {
+	m, _ := parseOUI(strings.NewReader(embeddedOUI))
+	return m
+}()
+
+// parseOUI reads OUI lines in either the embedded "525400<TAB>Vendor" form or
+// the IEEE oui.txt "52-54-00   (hex)<TAB>Vendor" form. Other lines, such as
+// comments and the IEEE "(base 16)" lines, are skipped.
+func parseOUI(r io.Reader) (map[string]string, error) {
+	m := make(map[string]string)
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		prefix, vendor, ok := strings.Cut(sc.Text(), "\t")
+		if !ok || strings.HasPrefix(prefix, "#") {
+			continue
+		}
+		prefix = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(prefix), "(hex)"))
+		prefix = strings.ToUpper(strings.NewReplacer("-", "", ":", "").Replace(prefix))
+		vendor = strings.TrimSpace(vendor)
+		if len(prefix) != 6 || strings.Trim(prefix, "0123456789ABCDEF") != "" || vendor == "" {
+			continue
+		}
+		m[prefix] = vendor
+	}
+	return m, sc.Err()
+}
+
+// loadOUIFile adds the entries of an OUI_FILE to the embedded table, taking
+// precedence over it. An empty path is a no-op.
+func loadOUIFile(path string) error {
+	if path == "" {
+		return nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("invalid OUI_FILE: %w", err)
+	}
+	defer f.Close()
+	m, err := parseOUI(f)
+	if err != nil {
+		return fmt.Errorf("invalid OUI_FILE %s: %w", path, err)
+	}
+	if len(m) == 0 {
+		return fmt.Errorf("invalid OUI_FILE %s: no OUI entries found", path)
+	}
+	for k, v := range m {
+		ouiVendors[k] = v
+	}
+	return nil
+}
+
+// macVendor names the vendor of a MAC address. Locally administered
+// addresses, which virtual interfaces and containers usually get, have no
+// vendor and are reported as such unless the table knows the prefix anyway,
+// as with QEMU's 52:54:00.
+func macVendor(mac string) string {
+	hw, err := net.ParseMAC(mac)
+	if err != nil || len(hw) < 3 {
+		return ""
+	}
+	if vendor, ok := ouiVendors[fmt.Sprintf("%02X%02X%02X", hw[0], hw[1], hw[2])]; ok {
+		return vendor
+	}
+	if hw[0]&0x02 != 0 {
+		return "locally administered"
+	}
+	return "unknown vendor"
+}
+
+// resolveVendors sets Vendor on every interface with a MAC address.
+func resolveVendors(interfaces []networkInterface) {
+	for i := range interfaces {
+		interfaces[i].Vendor = macVendor(interfaces[i].MAC)
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"net"
+	"runtime"
+	"strings"
+	"syscall"
+	"testing"
+
+	psnet "github.com/shirou/gopsutil/v3/net"
+)
+
+func TestListeningSockets(t *testing.T) {
+	conns := []psnet.ConnectionStat{
+		{Family: syscall.AF_INET6, Type: syscall.SOCK_STREAM, Laddr: psnet.Addr{IP: "::", Port: 22}, Status: "LISTEN"},
+		{Family: syscall.AF_INET, Type: syscall.SOCK_STREAM, Laddr: psnet.Addr{IP: "10.0.0.2", Port: 443}, Status: "ESTABLISHED"},
+		{Family: syscall.AF_INET, Type: syscall.SOCK_STREAM, Laddr: psnet.Addr{IP: "0.0.0.0", Port: 22}, Status: "LISTEN"},
+		{Family: syscall.AF_INET, Type: syscall.SOCK_STREAM, Laddr: psnet.Addr{IP: "127.0.0.1", Port: 8080}, Status: "LISTEN"},
+	}
+	got := listeningSockets(conns)
+	var protos []string
+	for _, c := range got {
+		protos = append(protos, socketProto(c)+" "+c.Laddr.IP)
+	}
+	if want := "tcp 0.0.0.0,tcp6 ::,tcp 127.0.0.1"; strings.Join(protos, ",") != want {
+		t.Errorf("Expected %q, got %q", want, strings.Join(protos, ","))
+	}
+}
+
+func TestCollectListeningPortsShowsOwnListener(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("socket owners are read from /proc")
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer ln.Close()
+	out := collectListeningPorts(context.Background())
+	if !strings.Contains(out, ln.Addr().String()) {
+		t.Errorf("Expected own listener %s in report:\n%s", ln.Addr(), out)
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// pmsetTimeout bounds the pmset call so a hung helper cannot stall the report.
+const pmsetTimeout = 2 * time.Second
+
+func readBatteries() ([]battery, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), pmsetTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "pmset", "-g", "batt").Output()
+	if err != nil {
+		return nil, err
+	}
+	return parsePmset(string(out)), nil
+}
+
+// parsePmset reads the battery lines of `pmset -g batt`, e.g.
+//
+//	-InternalBattery-0 (id=4653155)	85%; discharging; 4:12 remaining present: true
+func parsePmset(out string) []battery {
+	var batteries []battery
+	for line := range strings.Lines(out) {
+		line = strings.TrimSpace(line)
+		name, rest, ok := strings.Cut(strings.TrimPrefix(line, "-"), "\t")
+		if !ok || !strings.HasPrefix(line, "-") {
+			continue
+		}
+		if i := strings.Index(name, " (id="); i >= 0 {
+			name = name[:i]
+		}
+		fields := strings.Split(rest, ";")
+		if len(fields) < 2 {
+			continue
+		}
+		percent, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(fields[0]), "%"))
+		if err != nil {
+			continue
+		}
+		batteries = append(batteries, battery{Name: name, Percent: percent, State: strings.TrimSpace(fields[1])})
+	}
+	return batteries
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPowerSection(t *testing.T) {
+	orig := powerSupplyDir
+	defer func() { powerSupplyDir = orig }()
+	powerSupplyDir = t.TempDir()
+
+	if out, err := powerSection(); out != "" || err != nil {
+		t.Errorf("Expected no section without a battery, got %q, %v", out, err)
+	}
+
+	for name, attrs := range map[string]map[string]string{
+		"AC":   {"type": "Mains\n", "online": "1\n"},
+		"BAT0": {"type": "Battery\n", "capacity": "87\n", "status": "Charging\n"},
+	} {
+		dir := filepath.Join(powerSupplyDir, name)
+		os.Mkdir(dir, 0o755)
+		for attr, value := range attrs {
+			os.WriteFile(filepath.Join(dir, attr), []byte(value), 0o644)
+		}
+	}
+	out, err := powerSection()
+	if err != nil || !strings.Contains(out, "BAT0") || !strings.Contains(out, "87% (charging)") || strings.Contains(out, "AC") {
+		t.Errorf("Unexpected section %q, %v", out, err)
+	}
+}
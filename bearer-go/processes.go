@@ -0,0 +1,245 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/shirou/gopsutil/v3/process"
+)
+
+// topProcessCount is the number of processes shown by the process report.
+const topProcessCount = 20
+
+// processInfo is the structured view of a single process.
+type processInfo struct {
+	PID  int32  `json:"pid"`
+	Name string `json:"name"`
+	RSS  uint64 `json:"rss_bytes"`
+}
+
+// collectProcesses gathers details for every running process using a pool of
+// at most workers goroutines. A process that exits or denies access is skipped
+// without affecting the rest of the batch. If ctx expires first, the processes
+// inspected so far are returned together with the context error.
+func collectProcesses(ctx context.Context, workers int) ([]processInfo, int, error) {
+	return inspectProcesses(ctx, workers, inspectProcess)
+}
+
+// inspectProcesses runs inspect for every running process on a pool of at
+// most workers goroutines and returns the successful results, the number of
+// processes, and ctx's error if it expired before all were inspected.
+func inspectProcesses[T any](ctx context.Context, workers int, inspect func(context.Context, int32) (T, error)) ([]T, int, error) {
+	pids, err := process.PidsWithContext(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	jobs := make(chan int32)
+	results := make(chan T)
+	var wg sync.WaitGroup
+	for range max(1, min(workers, len(pids))) {
+		wg.Go(func() {
+			for pid := range jobs {
+				if info, err := inspect(ctx, pid); err == nil {
+					select {
+					case results <- info:
+					case <-ctx.Done():
+					}
+				}
+			}
+		})
+	}
+
+	go func() {
+		defer close(jobs)
+		for _, pid := range pids {
+			select {
+			case jobs <- pid:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	out := make([]T, 0, len(pids))
+	for info := range results {
+		out = append(out, info)
+	}
+	return out, len(pids), ctx.Err()
+}
+
+func inspectProcess(ctx context.Context, pid int32) (processInfo, error) {
+	p, err := process.NewProcessWithContext(ctx, pid)
+	if err != nil {
+		return processInfo{}, err
+	}
+	name, err := p.NameWithContext(ctx)
+	if err != nil {
+		return processInfo{}, err
+	}
+	mem, err := p.MemoryInfoWithContext(ctx)
+	if err != nil {
+		return processInfo{}, err
+	}
+	return processInfo{PID: pid, Name: name, RSS: mem.RSS}, nil
+}
+
+// collectTopProcesses renders the processes with the largest resident memory.
+func collectTopProcesses(ctx context.Context, workers int) string {
+	var sb strings.Builder
+	sb.WriteString("Process List Report\n")
+	sb.WriteString("===================\n\n")
+
+	procs, total, err := collectProcesses(ctx, workers)
+	if err != nil && procs == nil {
+		sb.WriteString(fmt.Sprintf("Error retrieving processes: %v\n", err))
+		return sb.String()
+	}
+
+	sort.Slice(procs, func(i, j int) bool { return procs[i].RSS > procs[j].RSS })
+	sb.WriteString(fmt.Sprintf("%-10s %-20s %12s\n", "PID", "Name", "Memory"))
+	sb.WriteString("------------------------------------------\n")
+	for _, p := range procs[:min(topProcessCount, len(procs))] {
+		sb.WriteString(fmt.Sprintf("%-10d %-20s %12s\n", p.PID, p.Name, formatBytes(p.RSS, unitsIEC)))
+	}
+	if err != nil {
+		sb.WriteString(fmt.Sprintf("\nNote: collection stopped early (%v); inspected %d of %d processes\n", err, len(procs), total))
+	}
+	return sb.String()
+}
+
+// defaultProcessPageMax is the PROCESS_PAGE_MAX default.
+const defaultProcessPageMax = 100
+
+// processSortKeys are the orders top_processes can list processes in.
+var processSortKeys = []string{"memory", "cpu", "fds", "name"}
+
+// topProcessesInput is the top_processes tool input.
+type topProcessesInput struct {
+	Offset int    `json:"offset,omitempty" jsonschema:"number of processes to skip, for paging (default 0)"`
+	Limit  int    `json:"limit,omitempty" jsonschema:"number of processes to return (default 20, at most PROCESS_PAGE_MAX)"`
+	SortBy string `json:"sort_by,omitempty" jsonschema:"order to list processes in: memory (default), cpu, fds or name"`
+}
+
+// validate rejects negative paging and unknown sort keys. The limit cap is
+// a server setting and is checked by collectProcessPage.
+func (in topProcessesInput) validate() error {
+	if in.Offset < 0 {
+		return fmt.Errorf("%w: offset %d must not be negative", errInvalidInput, in.Offset)
+	}
+	if in.Limit < 0 {
+		return fmt.Errorf("%w: limit %d must not be negative", errInvalidInput, in.Limit)
+	}
+	if in.SortBy != "" && !slices.Contains(processSortKeys, in.SortBy) {
+		return fmt.Errorf("%w: unsupported sort_by %q: must be one of %s", errInvalidInput, in.SortBy, strings.Join(processSortKeys, ", "))
+	}
+	return nil
+}
+
+// processDetail is a process with the extra columns top_processes can sort
+// by. CPU is the average since the process started; FDs is -1 when the
+// count could not be read, typically for another user's process.
+type processDetail struct {
+	processInfo
+	CPU float64
+	FDs int32
+}
+
+func inspectProcessDetail(ctx context.Context, pid int32) (processDetail, error) {
+	p, err := process.NewProcessWithContext(ctx, pid)
+	if err != nil {
+		return processDetail{}, err
+	}
+	name, err := p.NameWithContext(ctx)
+	if err != nil {
+		return processDetail{}, err
+	}
+	mem, err := p.MemoryInfoWithContext(ctx)
+	if err != nil {
+		return processDetail{}, err
+	}
+	d := processDetail{processInfo: processInfo{PID: pid, Name: name, RSS: mem.RSS}, FDs: -1}
+	d.CPU, _ = p.CPUPercentWithContext(ctx)
+	if fds, err := p.NumFDsWithContext(ctx); err == nil {
+		d.FDs = fds
+	}
+	return d, nil
+}
+
+// sortProcesses orders procs by key, largest first except for names and
+// PIDs, with the PID as a tie-breaker so that pages stay consistent between
+// calls.
+func sortProcesses(procs []processDetail, key string) {
+	slices.SortFunc(procs, func(a, b processDetail) int {
+		var c int
+		switch key {
+		case "pid":
+			// The PID tie-breaker below is the whole order.
+		case "cpu":
+			c = cmp.Compare(b.CPU, a.CPU)
+		case "fds":
+			c = cmp.Compare(b.FDs, a.FDs)
+		case "name":
+			c = cmp.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+		default:
+			c = cmp.Compare(b.RSS, a.RSS)
+		}
+		return cmp.Or(c, cmp.Compare(a.PID, b.PID))
+	})
+}
+
+// collectProcessPage renders one page of the process list in the requested
+// order, with the total count and the offset of the next page so clients can
+// browse every process. A limit above maxLimit, the PROCESS_PAGE_MAX setting,
+// is rejected; the default page size is cut down to it.
+func collectProcessPage(ctx context.Context, workers int, in topProcessesInput, maxLimit int) (string, error) {
+	limit := cmp.Or(in.Limit, min(topProcessCount, maxLimit))
+	if limit > maxLimit {
+		return "", fmt.Errorf("%w: limit %d exceeds PROCESS_PAGE_MAX %d", errInvalidInput, limit, maxLimit)
+	}
+	key := cmp.Or(in.SortBy, "memory")
+
+	var sb strings.Builder
+	sb.WriteString("Process List Report\n")
+	sb.WriteString("===================\n\n")
+
+	procs, total, err := inspectProcesses(ctx, workers, inspectProcessDetail)
+	if err != nil && procs == nil {
+		sb.WriteString(fmt.Sprintf("Error retrieving processes: %v\n", err))
+		return sb.String(), nil
+	}
+	sortProcesses(procs, key)
+	start := min(in.Offset, len(procs))
+	page := procs[start:min(start+limit, len(procs))]
+
+	if len(page) == 0 {
+		sb.WriteString(fmt.Sprintf("Sorted by %s; no processes at offset %d of %d\n", key, in.Offset, len(procs)))
+	} else {
+		sb.WriteString(fmt.Sprintf("Sorted by %s; showing %d-%d of %d processes\n\n", key, start+1, start+len(page), len(procs)))
+		sb.WriteString(fmt.Sprintf("%-10s %-20s %12s %8s %6s\n", "PID", "Name", "Memory", "CPU %", "FDs"))
+		sb.WriteString("---------------------------------------------------------------\n")
+		for _, p := range page {
+			fds := "-"
+			if p.FDs >= 0 {
+				fds = fmt.Sprint(p.FDs)
+			}
+			sb.WriteString(fmt.Sprintf("%-10d %-20s %12s %8.1f %6s\n", p.PID, p.Name, formatBytes(p.RSS, unitsIEC), p.CPU, fds))
+		}
+	}
+	if next := start + len(page); next < len(procs) {
+		sb.WriteString(fmt.Sprintf("\nNext page: offset=%d\n", next))
+	}
+	if err != nil {
+		sb.WriteString(fmt.Sprintf("\nNote: collection stopped early (%v); inspected %d of %d processes\n", err, len(procs), total))
+	}
+	return sb.String(), nil
+}
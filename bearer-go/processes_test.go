@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCollectProcessesIncludesSelf(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	procs, total, err := collectProcesses(ctx, 4)
+	if err != nil {
+		t.Fatalf("collectProcesses returned error: %v", err)
+	}
+	if total == 0 || len(procs) == 0 {
+		t.Fatalf("Expected some processes, got %d of %d", len(procs), total)
+	}
+	self := int32(os.Getpid())
+	found := false
+	for _, p := range procs {
+		if p.PID == self {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Errorf("Expected the test process (PID %d) to be listed", self)
+	}
+}
+
+func TestCollectTopProcessesDeadline(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	output := collectTopProcesses(ctx, 4)
+	if !strings.Contains(output, "Process List Report") {
+		t.Errorf("Expected output to contain 'Process List Report', got: %s", output)
+	}
+}
+
+func TestSortProcesses(t *testing.T) {
+	procs := []processDetail{
+		{processInfo: processInfo{PID: 3, Name: "b", RSS: 10}, CPU: 5, FDs: -1},
+		{processInfo: processInfo{PID: 1, Name: "C", RSS: 30}, CPU: 1, FDs: 7},
+		{processInfo: processInfo{PID: 2, Name: "a", RSS: 10}, CPU: 5, FDs: 9},
+	}
+	tests := map[string][]int32{
+		"memory": {1, 2, 3},
+		"cpu":    {2, 3, 1},
+		"fds":    {2, 1, 3},
+		"name":   {2, 3, 1},
+	}
+	for key, want := range tests {
+		sortProcesses(procs, key)
+		var got []int32
+		for _, p := range procs {
+			got = append(got, p.PID)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("sort by %s: expected %v, got %v", key, want, got)
+		}
+	}
+}
+
+func TestCollectProcessPage(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := collectProcessPage(ctx, 4, topProcessesInput{Limit: 11}, 10); !errors.Is(err, errInvalidInput) {
+		t.Errorf("Expected a limit over the cap to be rejected, got %v", err)
+	}
+	out, err := collectProcessPage(ctx, 4, topProcessesInput{Limit: 1, SortBy: "name"}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "Sorted by name; showing 1-1 of ") || !strings.Contains(out, "Next page: offset=1") {
+		t.Errorf("Unexpected first page:\n%s", out)
+	}
+	out, err = collectProcessPage(ctx, 4, topProcessesInput{Offset: 1 << 20}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "no processes at offset") || strings.Contains(out, "Next page") {
+		t.Errorf("Unexpected page past the end:\n%s", out)
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/shirou/gopsutil/v3/net"
+)
+
+// defaultRefreshInterval is how often the background refresher re-enumerates
+// partitions and interfaces when BACKGROUND_REFRESH_INTERVAL is unset.
+const defaultRefreshInterval = 30 * time.Second
+
+// hostEnumeration is a snapshot of the slow-changing host listings. Usage and
+// IO counters are not part of it; they are always read per call.
+type hostEnumeration struct {
+	physical   []disk.PartitionStat
+	all        []disk.PartitionStat
+	interfaces net.InterfaceStatList
+}
+
+// enumerationCache holds the latest snapshot while the background refresher
+// runs, and nil otherwise, in which case every call enumerates directly.
+var enumerationCache atomic.Pointer[hostEnumeration]
+
+// cachedPartitions is the default listPartitions: the cached snapshot when
+// there is one, disk.Partitions otherwise.
+func cachedPartitions(all bool) ([]disk.PartitionStat, error) {
+	if e := enumerationCache.Load(); e != nil {
+		if all {
+			return e.all, nil
+		}
+		return e.physical, nil
+	}
+	return disk.Partitions(all)
+}
+
+// cachedInterfaces is the interface counterpart of cachedPartitions.
+func cachedInterfaces() (net.InterfaceStatList, error) {
+	if e := enumerationCache.Load(); e != nil {
+		return e.interfaces, nil
+	}
+	return net.Interfaces()
+}
+
+// refreshEnumeration replaces the snapshot. On error the previous snapshot,
+// if any, is kept.
+func refreshEnumeration() error {
+	physical, err := disk.Partitions(false)
+	if err != nil {
+		return err
+	}
+	all, err := disk.Partitions(true)
+	if err != nil {
+		return err
+	}
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return err
+	}
+	enumerationCache.Store(&hostEnumeration{physical: physical, all: all, interfaces: interfaces})
+	return nil
+}
+
+// startBackgroundRefresh enumerates once, then refreshes the snapshot every
+// interval from a single goroutine. The returned stop function ends the
+// goroutine, waits for it, and drops the snapshot so later calls enumerate
+// directly again.
+func startBackgroundRefresh(interval time.Duration) (stop func()) {
+	if err := refreshEnumeration(); err != nil {
+		slog.Warn("Background refresh failed", "error", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := refreshEnumeration(); err != nil {
+					slog.Warn("Background refresh failed", "error", err)
+				}
+			}
+		}
+	})
+	slog.Info("Background refresh started", "interval", interval)
+	return func() {
+		cancel()
+		wg.Wait()
+		enumerationCache.Store(nil)
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// defaultMaxResultBytes caps tool results when MAX_RESULT_BYTES is unset.
+const defaultMaxResultBytes = 1 << 20
+
+// resultTruncatedMarker ends a tool result cut short by MAX_RESULT_BYTES.
+const resultTruncatedMarker = "\n[truncated]\n"
+
+// truncateResult caps text at limit bytes, marker included, without splitting
+// a UTF-8 sequence. A limit of 0 or less leaves text unchanged.
+func truncateResult(text string, limit int) string {
+	if limit <= 0 || len(text) <= limit {
+		return text
+	}
+	cut := max(limit-len(resultTruncatedMarker), 0)
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return text[:cut] + resultTruncatedMarker
+}
+
+// isJSONResult reports whether text is a JSON object or array, which a
+// truncation marker would break.
+func isJSONResult(text string) bool {
+	t := strings.TrimSpace(text)
+	return (strings.HasPrefix(t, "{") || strings.HasPrefix(t, "[")) && json.Valid([]byte(t))
+}
+
+// resultTooLargeError is the JSON-RPC error for a JSON result over the cap.
+// Data carries both sizes so clients can narrow the request instead.
+func resultTooLargeError(size, limit int) error {
+	data, _ := json.Marshal(map[string]any{"bytes": size, "limit": limit})
+	return &jsonrpc.Error{
+		Code:    codeResultTooLarge,
+		Message: fmt.Sprintf("%v: %d bytes exceeds MAX_RESULT_BYTES (%d)", errResultTooLarge, size, limit),
+		Data:    data,
+	}
+}
+
+// resultSizeMiddleware applies truncateResult to the text of every tool
+// result, so no tool can return unbounded output. JSON results over the cap
+// fail with resultTooLargeError instead, since cutting them would leave
+// clients unparseable output.
+func resultSizeMiddleware(limit int) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			res, err := next(ctx, method, req)
+			if r, ok := res.(*mcp.CallToolResult); ok {
+				for _, c := range r.Content {
+					tc, ok := c.(*mcp.TextContent)
+					if !ok {
+						continue
+					}
+					if limit > 0 && len(tc.Text) > limit && isJSONResult(tc.Text) {
+						return nil, resultTooLargeError(len(tc.Text), limit)
+					}
+					tc.Text = truncateResult(tc.Text, limit)
+				}
+			}
+			return res, err
+		}
+	}
+}
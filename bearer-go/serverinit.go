@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// lazyServer builds the MCP server on first use. The go-sdk panics on an
+// invalid tool registration, so build runs under a recover and a failure is
+// kept as the server's init error instead of leaving a nil or partial server
+// behind.
+type lazyServer struct {
+	build func() (*mcp.Server, error)
+
+	once   sync.Once
+	server *mcp.Server
+	err    error
+}
+
+// get builds the server once and returns it, or the init error. The error
+// is permanent: a failed registration will fail the same way again.
+func (l *lazyServer) get() (*mcp.Server, error) {
+	l.once.Do(func() {
+		defer func() {
+			if p := recover(); p != nil {
+				l.err = fmt.Errorf("%v", p)
+			}
+			if l.err != nil {
+				l.server = nil
+				l.err = fmt.Errorf("MCP server initialization failed: %w", l.err)
+				slog.Error("Lazy Initialization failed", "error", l.err)
+			}
+		}()
+		l.server, l.err = l.build()
+	})
+	return l.server, l.err
+}
+
+// require wraps the MCP handler so that requests get a 503 with the init
+// error rather than reaching the handler without a server.
+func (l *lazyServer) require(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := l.get(); err != nil {
+			http.Error(w, "Service Unavailable: "+err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// serveReady serves /readyz: 200 once the MCP server is built and every
+// ready condition holds, 503 with the reason otherwise. It builds the server
+// if no request has yet, so a readiness check catches a broken registration
+// before traffic does. A graceful shutdown also makes the instance unready.
+func (l *lazyServer) serveReady(w http.ResponseWriter, r *http.Request, ready ...func() error) {
+	if shuttingDown.Load() {
+		http.Error(w, "Service Unavailable: shutting down", http.StatusServiceUnavailable)
+		return
+	}
+	if _, err := l.get(); err != nil {
+		http.Error(w, "Service Unavailable: "+err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	for _, check := range ready {
+		if err := check(); err != nil {
+			http.Error(w, "Service Unavailable: "+err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("OK"))
+}
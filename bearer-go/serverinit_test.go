@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestLazyServerRegistrationFailure(t *testing.T) {
+	builds := 0
+	srv := &lazyServer{build: func() (*mcp.Server, error) {
+		builds++
+		server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+		// A non-object input type makes the go-sdk panic during registration.
+		mcp.AddTool(server, &mcp.Tool{Name: "broken"}, func(ctx context.Context, request *mcp.CallToolRequest, input string) (*mcp.CallToolResult, any, error) {
+			return nil, nil, nil
+		})
+		return server, nil
+	}}
+	called := false
+	handler := srv.require(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true }))
+
+	for range 2 {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader("{}")))
+		if rec.Code != http.StatusServiceUnavailable || !strings.Contains(rec.Body.String(), `"broken"`) {
+			t.Errorf("Expected a 503 naming the failed tool, got %d %q", rec.Code, rec.Body.String())
+		}
+	}
+	if called {
+		t.Error("Expected the MCP handler not to run without a server")
+	}
+	if builds != 1 {
+		t.Errorf("Expected one build attempt, got %d", builds)
+	}
+
+	rec := httptest.NewRecorder()
+	srv.serveReady(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rec.Code != http.StatusServiceUnavailable || !strings.Contains(rec.Body.String(), "initialization failed") {
+		t.Errorf("Expected /readyz to fail, got %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestLazyServerReady(t *testing.T) {
+	srv := &lazyServer{build: func() (*mcp.Server, error) {
+		return mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil), nil
+	}}
+	rec := httptest.NewRecorder()
+	srv.serveReady(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected /readyz to pass, got %d %q", rec.Code, rec.Body.String())
+	}
+	if server, err := srv.get(); server == nil || err != nil {
+		t.Errorf("Expected a server, got %v, %v", server, err)
+	}
+}
+
+func TestLazyServerReadyConditions(t *testing.T) {
+	srv := &lazyServer{build: func() (*mcp.Server, error) {
+		return mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil), nil
+	}}
+	rec := httptest.NewRecorder()
+	srv.serveReady(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil), func() error { return errors.New("key fetch pending") })
+	if rec.Code != http.StatusServiceUnavailable || !strings.Contains(rec.Body.String(), "key fetch pending") {
+		t.Errorf("Expected an unmet condition to fail /readyz, got %d %q", rec.Code, rec.Body.String())
+	}
+
+	shuttingDown.Store(true)
+	defer shuttingDown.Store(false)
+	rec = httptest.NewRecorder()
+	srv.serveReady(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rec.Code != http.StatusServiceUnavailable || !strings.Contains(rec.Body.String(), "shutting down") {
+		t.Errorf("Expected /readyz to fail during shutdown, got %d %q", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// reportSignatureMeta is the _meta key that carries a text result's signature.
+// It is an annotation rather than a line in the text so JSON reports still
+// parse.
+const reportSignatureMeta = "signature"
+
+// signReport returns the HMAC-SHA256 of text under key, in the form
+// "hmac-sha256=<hex>".
+func signReport(text, key string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(text))
+	return "hmac-sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// verifyReport reports whether sig is the signature of text under key.
+func verifyReport(text, key, sig string) bool {
+	return hmac.Equal([]byte(signReport(text, key)), []byte(sig))
+}
+
+// reportSigningMiddleware annotates the text of every tool result with its
+// signature under REPORT_SIGNING_KEY. It must wrap resultSizeMiddleware so the
+// signature covers the text the client actually receives. An empty key leaves
+// results unsigned.
+func reportSigningMiddleware(key string) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		if key == "" {
+			return next
+		}
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			res, err := next(ctx, method, req)
+			if r, ok := res.(*mcp.CallToolResult); ok {
+				for _, c := range r.Content {
+					if tc, ok := c.(*mcp.TextContent); ok {
+						if tc.Meta == nil {
+							tc.Meta = mcp.Meta{}
+						}
+						tc.Meta[reportSignatureMeta] = signReport(tc.Text, key)
+					}
+				}
+			}
+			return res, err
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// requestStats counts requests since the process started, for environments
+// without an external metrics system. Health checks are not counted. It is
+// safe for concurrent use.
+type requestStats struct {
+	start        time.Time
+	total        atomic.Int64
+	unauthorized atomic.Int64
+}
+
+func newRequestStats() *requestStats {
+	return &requestStats{start: time.Now()}
+}
+
+// serveStats serves /stats as JSON.
+func (s *requestStats) serveStats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"total_requests":        s.total.Load(),
+		"unauthorized_requests": s.unauthorized.Load(),
+		"uptime_seconds":        int64(time.Since(s.start).Seconds()),
+		"active_connections":    serverConns.active.Load(),
+		"accepted_connections":  serverConns.accepted.Load(),
+	})
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestStats(t *testing.T) {
+	stats := newRequestStats()
+	stats.total.Add(3)
+	stats.unauthorized.Add(1)
+
+	rec := httptest.NewRecorder()
+	stats.serveStats(rec, httptest.NewRequest(http.MethodGet, "/stats", nil))
+	var got map[string]int64
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("Expected JSON, got %q: %v", rec.Body.String(), err)
+	}
+	if got["total_requests"] != 3 || got["unauthorized_requests"] != 1 {
+		t.Errorf("Unexpected counters: %v", got)
+	}
+	if _, ok := got["uptime_seconds"]; !ok {
+		t.Errorf("Expected uptime_seconds, got: %v", got)
+	}
+}
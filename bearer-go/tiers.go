@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Token tiers. The primary MCP_BEARER_TOKEN unlocks every tool; the
+// MCP_BEARER_TOKEN_READONLY token, meant for sharing limited access, only
+// unlocks readonlyTools.
+const (
+	tierPrimary  = "primary"
+	tierReadonly = "readonly"
+)
+
+// readonlyTools are the tools the read-only token may call. Disks, processes
+// and logs stay behind the primary token.
+var readonlyTools = []string{"local_system_info"}
+
+// tokenTier returns the tier of the bearer token in an Authorization header,
+// or "" if it matches neither configured token. A header without the
+// "Bearer " prefix is rejected before any comparison; tokens are compared in
+// constant time so response timing does not reveal how much of a guess
+// matched.
+func tokenTier(cfg *Config, authHeader string) string {
+	token, ok := strings.CutPrefix(authHeader, "Bearer ")
+	switch {
+	case !ok:
+		return ""
+	case cfg.BearerToken != "" && secretEqual(token, cfg.BearerToken):
+		return tierPrimary
+	case cfg.ReadonlyToken != "" && secretEqual(token, cfg.ReadonlyToken):
+		return tierReadonly
+	}
+	return ""
+}
+
+// secretEqual compares a presented token with a configured one in constant
+// time.
+func secretEqual(got, want string) bool {
+	return subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1
+}
+
+// parseToolAllowlist parses TOOL_ALLOWLIST, a ";"-separated list of
+// tier=tool,tool entries such as "readonly=local_system_info;primary=
+// local_system_info,disk_usage". Tools are named by their default names.
+func parseToolAllowlist(v string) (map[string][]string, error) {
+	if strings.TrimSpace(v) == "" {
+		return nil, nil
+	}
+	allowlist := make(map[string][]string)
+	for entry := range strings.SplitSeq(v, ";") {
+		tier, tools, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		tier = strings.TrimSpace(tier)
+		if !ok || tools == "" {
+			return nil, fmt.Errorf("invalid TOOL_ALLOWLIST entry %q: want tier=tool,tool", entry)
+		}
+		if tier != tierPrimary && tier != tierReadonly {
+			return nil, fmt.Errorf("invalid TOOL_ALLOWLIST tier %q: must be %s or %s", tier, tierPrimary, tierReadonly)
+		}
+		if _, dup := allowlist[tier]; dup {
+			return nil, fmt.Errorf("invalid TOOL_ALLOWLIST: tier %q listed twice", tier)
+		}
+		for tool := range strings.SplitSeq(tools, ",") {
+			tool = strings.TrimSpace(tool)
+			if !slices.Contains(toolNames, tool) {
+				return nil, fmt.Errorf("invalid TOOL_ALLOWLIST: unknown tool %q for tier %q", tool, tier)
+			}
+			allowlist[tier] = append(allowlist[tier], tool)
+		}
+	}
+	return allowlist, nil
+}
+
+// formatToolAllowlist renders an allow-list back in TOOL_ALLOWLIST form.
+func formatToolAllowlist(allowlist map[string][]string) string {
+	if len(allowlist) == 0 {
+		return "(not set)"
+	}
+	var entries []string
+	for _, tier := range []string{tierPrimary, tierReadonly} {
+		if tools, ok := allowlist[tier]; ok {
+			entries = append(entries, tier+"="+strings.Join(tools, ","))
+		}
+	}
+	return strings.Join(entries, ";")
+}
+
+// toolAccess reports whether a token tier may call a tool, by its registered
+// name, and why not when it may not. The read-only tier is limited to
+// readonlyTools; TOOL_ALLOWLIST narrows a tier further. Tools renamed in
+// TOOLS_CONFIG_FILE keep their access.
+func toolAccess(cfg *Config, tier, tool string) error {
+	allowed := func(names []string) bool {
+		return slices.ContainsFunc(names, func(name string) bool { return cfg.Tools.name(name) == tool })
+	}
+	if tier == tierReadonly && !allowed(readonlyTools) {
+		return fmt.Errorf("%w: tool %q requires the primary bearer token", errForbidden, tool)
+	}
+	if names, ok := cfg.ToolAllowlist[tier]; ok && !allowed(names) {
+		return fmt.Errorf("%w: tool %q is not in the TOOL_ALLOWLIST for the %s token", errForbidden, tool, tier)
+	}
+	return nil
+}
+
+// toolTierMiddleware enforces tool access at call time: a tools/call the
+// token's tier may not make fails with an authorization error. The tier is
+// derived from the Authorization header of the HTTP request that carried the
+// call.
+func toolTierMiddleware(cfg *Config) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			call, ok := req.(*mcp.CallToolRequest)
+			if !ok || call.Extra == nil || call.Params == nil {
+				return next(ctx, method, req)
+			}
+			tier := tokenTier(cfg, call.Extra.Header.Get("Authorization"))
+			if err := toolAccess(cfg, tier, call.Params.Name); err != nil {
+				slog.Warn("Tool call denied for token tier", "tier", tier, "tool", call.Params.Name)
+				return nil, toolError(err)
+			}
+			return next(ctx, method, req)
+		}
+	}
+}
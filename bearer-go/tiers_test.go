@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestTokenTier(t *testing.T) {
+	cfg := &Config{BearerToken: "primary-token", ReadonlyToken: "readonly-token"}
+	tests := map[string]string{
+		"Bearer primary-token":  tierPrimary,
+		"Bearer readonly-token": tierReadonly,
+		"Bearer other":          "",
+		"Bearer primary-tokem":  "",
+		"Bearer primary-toke":   "",
+		"Bearer primary-token ": "",
+		"bearer primary-token":  "",
+		"primary-token":         "",
+		"":                      "",
+	}
+	for header, want := range tests {
+		if got := tokenTier(cfg, header); got != want {
+			t.Errorf("tokenTier(%q) = %q, want %q", header, got, want)
+		}
+	}
+	if got := tokenTier(&Config{BearerToken: "primary-token"}, "Bearer "); got != "" {
+		t.Errorf("Expected an unset read-only token to match nothing, got %q", got)
+	}
+}
+
+func TestToolTierMiddleware(t *testing.T) {
+	cfg := &Config{BearerToken: "primary-token", ReadonlyToken: "readonly-token"}
+	next := func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		return &mcp.CallToolResult{}, nil
+	}
+	handler := toolTierMiddleware(cfg)(next)
+
+	call := func(token, tool string) error {
+		req := &mcp.CallToolRequest{
+			Params: &mcp.CallToolParamsRaw{Name: tool},
+			Extra:  &mcp.RequestExtra{Header: http.Header{"Authorization": {"Bearer " + token}}},
+		}
+		_, err := handler(context.Background(), "tools/call", req)
+		return err
+	}
+	if err := call("readonly-token", "local_system_info"); err != nil {
+		t.Errorf("Expected the read-only token to call local_system_info, got: %v", err)
+	}
+	var wireErr *jsonrpc.Error
+	if err := call("readonly-token", "disk_usage"); !errors.As(err, &wireErr) || wireErr.Code != codeForbidden {
+		t.Errorf("Expected the read-only token to be denied disk_usage with code %d, got: %v", codeForbidden, err)
+	}
+	if err := call("primary-token", "disk_usage"); err != nil {
+		t.Errorf("Expected the primary token to call disk_usage, got: %v", err)
+	}
+}
+
+func TestParseToolAllowlist(t *testing.T) {
+	allowlist, err := parseToolAllowlist("readonly=local_system_info; primary=local_system_info, disk_usage")
+	if err != nil {
+		t.Fatalf("parseToolAllowlist returned error: %v", err)
+	}
+	if got := formatToolAllowlist(allowlist); got != "primary=local_system_info,disk_usage;readonly=local_system_info" {
+		t.Errorf("Unexpected allow-list: %q", got)
+	}
+	for _, bad := range []string{"primary", "monitoring=disk_usage", "primary=ping", "primary=disk_usage;primary=top_processes"} {
+		if _, err := parseToolAllowlist(bad); err == nil {
+			t.Errorf("Expected error for %q", bad)
+		}
+	}
+}
+
+func TestToolAccessAllowlist(t *testing.T) {
+	cfg := &Config{
+		ToolAllowlist: map[string][]string{tierPrimary: {"local_system_info", "disk_usage"}},
+		Tools:         toolsConfig{"disk_usage": {Name: "disks"}},
+	}
+	if err := toolAccess(cfg, tierPrimary, "disks"); err != nil {
+		t.Errorf("Expected renamed disk_usage to stay allowed, got: %v", err)
+	}
+	if err := toolAccess(cfg, tierPrimary, "top_processes"); !errors.Is(err, errForbidden) {
+		t.Errorf("Expected top_processes to be forbidden, got: %v", err)
+	}
+	if err := toolAccess(cfg, tierReadonly, "disks"); !errors.Is(err, errForbidden) {
+		t.Errorf("Expected the read-only tier to stay limited, got: %v", err)
+	}
+	if err := toolAccess(&Config{}, tierPrimary, "top_processes"); err != nil {
+		t.Errorf("Expected every tool allowed by default, got: %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// sectionTimer measures how long each report section takes to collect. A nil
+// timer records nothing, so reports only pay for timing when DEBUG_TIMING is
+// set.
+type sectionTimer struct {
+	start    time.Time
+	last     time.Time
+	sections []timedSection
+}
+
+type timedSection struct {
+	name     string
+	duration time.Duration
+}
+
+func newSectionTimer(enabled bool) *sectionTimer {
+	if !enabled {
+		return nil
+	}
+	now := time.Now()
+	return &sectionTimer{start: now, last: now}
+}
+
+// mark records the time elapsed since the previous mark as the named section.
+func (t *sectionTimer) mark(name string) {
+	if t == nil {
+		return
+	}
+	now := time.Now()
+	t.sections = append(t.sections, timedSection{name, now.Sub(t.last)})
+	t.last = now
+}
+
+// record adds a section measured elsewhere, such as one collected
+// concurrently, and advances the total to now.
+func (t *sectionTimer) record(name string, d time.Duration) {
+	if t == nil {
+		return
+	}
+	t.sections = append(t.sections, timedSection{name, d})
+	t.last = time.Now()
+}
+
+// report renders the Timing section, or nothing for a nil timer.
+func (t *sectionTimer) report() string {
+	if t == nil {
+		return ""
+	}
+	var sb strings.Builder
+	fmt.Fprintln(&sb, "\nTiming")
+	fmt.Fprintln(&sb, "------")
+	for _, s := range t.sections {
+		fmt.Fprintf(&sb, "%-18s%s\n", s.name+":", s.duration)
+	}
+	fmt.Fprintf(&sb, "%-18s%s\n", "Total:", t.last.Sub(t.start))
+	return sb.String()
+}
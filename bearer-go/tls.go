@@ -0,0 +1,78 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// tlsVersions maps accepted TLS_MIN_VERSION values to protocol versions.
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// cipherProfiles maps TLS_CIPHER_PROFILE names to the TLS 1.2 cipher suites
+// they allow. A nil list keeps Go's default selection. TLS 1.3 suites are not
+// configurable and are always enabled when TLS 1.3 is negotiated.
+var cipherProfiles = map[string][]uint16{
+	"default": nil,
+	"strict": {
+		tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+		tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+		tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+		tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+		tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+		tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+	},
+}
+
+// loadTLSConfig reads and validates the TLS settings. TLS is enabled only
+// when both TLS_CERT_FILE and TLS_KEY_FILE are set.
+func loadTLSConfig(cfg *Config) error {
+	cfg.TLSCertFile = os.Getenv("TLS_CERT_FILE")
+	cfg.TLSKeyFile = os.Getenv("TLS_KEY_FILE")
+	if (cfg.TLSCertFile == "") != (cfg.TLSKeyFile == "") {
+		return fmt.Errorf("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+
+	cfg.TLSMinVersion = os.Getenv("TLS_MIN_VERSION")
+	if cfg.TLSMinVersion == "" {
+		cfg.TLSMinVersion = "1.2"
+	}
+	if _, ok := tlsVersions[cfg.TLSMinVersion]; !ok {
+		return fmt.Errorf("invalid TLS_MIN_VERSION %q: must be one of %v", cfg.TLSMinVersion, sortedKeys(tlsVersions))
+	}
+
+	cfg.TLSCipherProfile = os.Getenv("TLS_CIPHER_PROFILE")
+	if cfg.TLSCipherProfile == "" {
+		cfg.TLSCipherProfile = "default"
+	}
+	if _, ok := cipherProfiles[cfg.TLSCipherProfile]; !ok {
+		return fmt.Errorf("invalid TLS_CIPHER_PROFILE %q: must be one of %v", cfg.TLSCipherProfile, sortedKeys(cipherProfiles))
+	}
+	return nil
+}
+
+// tlsEnabled reports whether the server should terminate TLS itself.
+func (c *Config) tlsEnabled() bool {
+	return c.TLSCertFile != "" && c.TLSKeyFile != ""
+}
+
+// tlsConfig builds the server's tls.Config from the validated settings.
+func (c *Config) tlsConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:   tlsVersions[c.TLSMinVersion],
+		CipherSuites: cipherProfiles[c.TLSCipherProfile],
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSetupTracingDisabled(t *testing.T) {
+	shutdown, err := setupTracing(context.Background(), &Config{}, "test")
+	if err != nil {
+		t.Fatalf("setupTracing returned error: %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("Expected no-op shutdown, got: %v", err)
+	}
+}
+
+func TestWithTracingPropagatesTraceparent(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	orig := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(orig)
+	if _, err := setupTracing(context.Background(), &Config{}, "test"); err != nil {
+		t.Fatal(err)
+	}
+
+	handler := withTracing(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), "test")
+	for _, path := range []string{"/healthz", "/mcp"} {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("Expected one span (health checks are skipped), got %d", len(spans))
+	}
+	if got := spans[0].SpanContext().TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("Expected the incoming trace ID to be continued, got %s", got)
+	}
+}
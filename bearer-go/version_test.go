@@ -0,0 +1,26 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPrintVersion(t *testing.T) {
+	defer func(v, c, d string) { version, commit, buildDate = v, c, d }(version, commit, buildDate)
+	version, commit, buildDate = "v1.2.0", "abc1234", "2026-10-01T12:00:00Z"
+
+	var sb strings.Builder
+	printVersion(&sb, "test-bin")
+	want := "test-bin v1.2.0\ncommit: abc1234\nbuilt:  2026-10-01T12:00:00Z\n"
+	if sb.String() != want {
+		t.Errorf("got %q, want %q", sb.String(), want)
+	}
+
+	version, commit, buildDate = "", "", ""
+	if b := currentBuild(); b.Version == "" || b.Commit == "" || b.BuildDate != "unknown" {
+		t.Errorf("Expected unset values to fall back, got %+v", b)
+	}
+	if !isVersion("version") || !isVersion("--version") || isVersion("-v") {
+		t.Error("Unexpected isVersion result")
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// adminPaths are the operational endpoints that ADMIN_PORT moves off the
+// public listener.
+var adminPaths = []string{"/stats", "/debug/load", "/admin/refresh-key"}
+
+func isAdminPath(path string) bool {
+	return slices.Contains(adminPaths, path)
+}
+
+// adminAddr resolves ADMIN_PORT to a listen address. A bare port binds to
+// localhost only; host:port binds where it says.
+func adminAddr(port string) string {
+	if strings.Contains(port, ":") {
+		return port
+	}
+	return net.JoinHostPort("127.0.0.1", port)
+}
+
+// validateAdminPort checks that ADMIN_PORT is a port or host:port that does
+// not collide with the public PORT.
+func validateAdminPort(adminPort, port string) error {
+	_, p, err := net.SplitHostPort(adminAddr(adminPort))
+	if err != nil {
+		return fmt.Errorf("invalid ADMIN_PORT %q: %w", adminPort, err)
+	}
+	if n, err := strconv.Atoi(p); err != nil || n < 1 || n > 65535 {
+		return fmt.Errorf("invalid ADMIN_PORT %q: port must be between 1 and 65535", adminPort)
+	}
+	if p == port {
+		return fmt.Errorf("invalid ADMIN_PORT %q: must differ from PORT", adminPort)
+	}
+	return nil
+}
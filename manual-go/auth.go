@@ -0,0 +1,123 @@
+package main
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+)
+
+// resolveAuthMode sets cfg.AuthMode from AUTH_MODE. When AUTH_MODE is unset,
+// the mode inferred from the rest of the environment is used instead so that
+// existing deployments keep working.
+func resolveAuthMode(cfg *Config, inferred string, supported ...string) error {
+	mode := strings.ToLower(strings.TrimSpace(os.Getenv("AUTH_MODE")))
+	if mode == "" {
+		cfg.AuthMode, cfg.AuthModeInferred = inferred, true
+		return nil
+	}
+	if !slices.Contains(supported, mode) {
+		return fmt.Errorf("invalid AUTH_MODE %q: supported modes are %s", mode, strings.Join(supported, ", "))
+	}
+	cfg.AuthMode = mode
+	return nil
+}
+
+// validateAuth verifies that the environment required by the chosen auth
+// mode is present, so the server fails fast instead of running unsecured.
+// An apikey mode that was only inferred and has no key source falls back to
+// none with a warning, as before AUTH_MODE existed; an explicit one fails
+// unless REQUIRE_API_KEY=false allows running without a key.
+func (c *Config) validateAuth() error {
+	if c.AuthModeInferred && c.AuthMode == "apikey" && c.APIKey == "" && (c.Offline || getProjectID() == "") {
+		slog.Warn("No API key source (MCP_API_KEY or a Google Cloud project); serving without authentication. Set AUTH_MODE=apikey to refuse to start instead")
+		c.AuthMode = "none"
+	}
+	if c.DebugLoad && c.AuthMode == "none" {
+		return fmt.Errorf("ENABLE_DEBUG_LOAD requires authentication; it cannot be used with AUTH_MODE=none")
+	}
+	if c.SessionsEnabled && c.AuthMode == "none" {
+		return fmt.Errorf("SESSIONS_INFO_ENABLED requires authentication; it cannot be used with AUTH_MODE=none")
+	}
+	switch c.AuthMode {
+	case "apikey":
+		if c.APIKey == "" && !c.RequireAPIKey && (c.Offline || getProjectID() == "") {
+			slog.Warn("AUTH_MODE=apikey has no key source; skipping the key check because REQUIRE_API_KEY=false")
+			break
+		}
+		if c.APIKey == "" && c.Offline {
+			return fmt.Errorf("AUTH_MODE=apikey with OFFLINE=true requires MCP_API_KEY; the key cannot be fetched offline")
+		}
+		if c.APIKey == "" && getProjectID() == "" {
+			return fmt.Errorf("AUTH_MODE=apikey requires MCP_API_KEY or a Google Cloud project (GOOGLE_CLOUD_PROJECT or gcloud config) to fetch the key from")
+		}
+	case "any":
+		if c.BearerToken == "" && c.APIKey == "" && c.Offline {
+			return fmt.Errorf("AUTH_MODE=any with OFFLINE=true requires MCP_BEARER_TOKEN or MCP_API_KEY; the key cannot be fetched offline")
+		}
+		if c.BearerToken == "" && c.APIKey == "" && getProjectID() == "" {
+			return fmt.Errorf("AUTH_MODE=any requires MCP_BEARER_TOKEN, MCP_API_KEY, or a Google Cloud project to fetch the key from")
+		}
+	case "none":
+		if c.APIKey != "" {
+			return fmt.Errorf("AUTH_MODE=none conflicts with MCP_API_KEY being set; unset one of them")
+		}
+		if c.BearerToken != "" {
+			return fmt.Errorf("AUTH_MODE=none conflicts with MCP_BEARER_TOKEN being set; unset one of them")
+		}
+	}
+	return nil
+}
+
+// Authenticator checks a request against a single credential scheme.
+// Implementations must fail closed when they have no secret configured.
+type Authenticator interface {
+	Name() string
+	Authenticate(r *http.Request) bool
+}
+
+// apiKeyAuthenticator accepts the expected API key from any of the sources
+// read by extractAPIKey.
+type apiKeyAuthenticator struct {
+	expected string
+}
+
+func (a apiKeyAuthenticator) Name() string { return "apikey" }
+
+func (a apiKeyAuthenticator) Authenticate(r *http.Request) bool {
+	key, _ := extractAPIKey(r)
+	return a.expected != "" && secretEqual(key, a.expected)
+}
+
+// bearerAuthenticator accepts an "Authorization: Bearer <token>" header.
+type bearerAuthenticator struct {
+	token string
+}
+
+func (a bearerAuthenticator) Name() string { return "bearer" }
+
+func (a bearerAuthenticator) Authenticate(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && a.token != "" && secretEqual(token, a.token)
+}
+
+// secretEqual compares a presented credential with the expected one in
+// constant time, so response timing does not reveal how much of a guess
+// matched.
+func secretEqual(got, want string) bool {
+	return subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1
+}
+
+// authenticate tries each authenticator in turn and returns the name of the
+// first one that accepts the request.
+func authenticate(r *http.Request, authenticators ...Authenticator) (string, bool) {
+	for _, a := range authenticators {
+		if a.Authenticate(r) {
+			return a.Name(), true
+		}
+	}
+	return "", false
+}
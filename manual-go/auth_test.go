@@ -0,0 +1,126 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestResolveAuthMode(t *testing.T) {
+	t.Setenv("AUTH_MODE", "")
+	cfg := &Config{}
+	if err := resolveAuthMode(cfg, "apikey", "apikey", "none"); err != nil {
+		t.Fatalf("resolveAuthMode returned error: %v", err)
+	}
+	if cfg.AuthMode != "apikey" || !cfg.AuthModeInferred {
+		t.Errorf("Expected inferred apikey mode, got %q (inferred=%v)", cfg.AuthMode, cfg.AuthModeInferred)
+	}
+
+	t.Setenv("AUTH_MODE", "NONE")
+	cfg = &Config{}
+	if err := resolveAuthMode(cfg, "apikey", "apikey", "none"); err != nil {
+		t.Fatalf("resolveAuthMode returned error: %v", err)
+	}
+	if cfg.AuthMode != "none" || cfg.AuthModeInferred {
+		t.Errorf("Expected explicit none mode, got %q (inferred=%v)", cfg.AuthMode, cfg.AuthModeInferred)
+	}
+
+	t.Setenv("AUTH_MODE", "bearer")
+	if err := resolveAuthMode(&Config{}, "apikey", "apikey", "none"); err == nil {
+		t.Error("Expected an error for an unsupported mode")
+	}
+}
+
+func TestValidateAuth(t *testing.T) {
+	if err := (&Config{AuthMode: "apikey", APIKey: "key"}).validateAuth(); err != nil {
+		t.Errorf("Expected apikey mode with MCP_API_KEY to be valid, got: %v", err)
+	}
+	if err := (&Config{AuthMode: "none", APIKey: "key"}).validateAuth(); err == nil {
+		t.Error("Expected none mode with MCP_API_KEY set to be rejected")
+	}
+	if err := (&Config{AuthMode: "apikey", Offline: true, RequireAPIKey: true}).validateAuth(); err == nil || !strings.Contains(err.Error(), "OFFLINE") {
+		t.Errorf("Expected offline apikey mode without MCP_API_KEY to be rejected, got: %v", err)
+	}
+	if err := (&Config{AuthMode: "any", Offline: true, BearerToken: "token"}).validateAuth(); err != nil {
+		t.Errorf("Expected offline any mode with a bearer token to be valid, got: %v", err)
+	}
+	if err := (&Config{AuthMode: "none", SessionsEnabled: true}).validateAuth(); err == nil {
+		t.Error("Expected sessions_info without authentication to be rejected")
+	}
+}
+
+// withoutKeySource clears every source of an API key and of a project for
+// loadConfig and validateAuth.
+func withoutKeySource(t *testing.T) {
+	t.Helper()
+	for _, name := range []string{"AUTH_MODE", "MCP_API_KEY", "MCP_BEARER_TOKEN", "GOOGLE_CLOUD_PROJECT", "OFFLINE", "REQUIRE_API_KEY"} {
+		t.Setenv(name, "")
+	}
+	old := gcloudProjectID
+	t.Cleanup(func() { gcloudProjectID = old })
+	gcloudProjectID = func() string { return "" }
+}
+
+func TestValidateAuthEmptyEnv(t *testing.T) {
+	withoutKeySource(t)
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Fatalf("loadConfig returned error: %v", err)
+	}
+	if err := cfg.validateAuth(); err != nil {
+		t.Fatalf("Expected an empty environment to start, got: %v", err)
+	}
+	if cfg.AuthMode != "none" || !cfg.AuthModeInferred {
+		t.Errorf("Expected inferred none mode, got %q (inferred=%v)", cfg.AuthMode, cfg.AuthModeInferred)
+	}
+
+	t.Setenv("AUTH_MODE", "apikey")
+	if cfg, err = loadConfig(); err != nil {
+		t.Fatalf("loadConfig returned error: %v", err)
+	}
+	if err := cfg.validateAuth(); err == nil {
+		t.Error("Expected an explicit AUTH_MODE=apikey without a key source to be rejected")
+	}
+
+	t.Setenv("REQUIRE_API_KEY", "false")
+	if cfg, err = loadConfig(); err != nil {
+		t.Fatalf("loadConfig returned error: %v", err)
+	}
+	if err := cfg.validateAuth(); err != nil {
+		t.Errorf("Expected REQUIRE_API_KEY=false to start without a key source, got: %v", err)
+	}
+	if cfg.AuthMode != "apikey" {
+		t.Errorf("Expected the explicit apikey mode to be kept, got %q", cfg.AuthMode)
+	}
+}
+
+func TestAuthenticateAny(t *testing.T) {
+	authenticators := []Authenticator{apiKeyAuthenticator{expected: "key"}, bearerAuthenticator{token: "token"}}
+
+	r := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+	r.Header.Set("Authorization", "Bearer token")
+	if name, ok := authenticate(r, authenticators...); !ok || name != "bearer" {
+		t.Errorf("Expected bearer to match, got %q (ok=%v)", name, ok)
+	}
+
+	r = httptest.NewRequest(http.MethodPost, "/mcp", nil)
+	r.Header.Set("x-goog-api-key", "key")
+	if name, ok := authenticate(r, authenticators...); !ok || name != "apikey" {
+		t.Errorf("Expected apikey to match, got %q (ok=%v)", name, ok)
+	}
+
+	r = httptest.NewRequest(http.MethodPost, "/mcp", nil)
+	r.Header.Set("Authorization", "Bearer wrong")
+	if _, ok := authenticate(r, authenticators...); ok {
+		t.Error("Expected a wrong token to be rejected")
+	}
+}
+
+func TestAuthenticatorsFailClosed(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+	r.Header.Set("Authorization", "Bearer ")
+	if _, ok := authenticate(r, apiKeyAuthenticator{}, bearerAuthenticator{}); ok {
+		t.Error("Expected authenticators without a configured secret to reject")
+	}
+}
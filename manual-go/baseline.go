@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/load"
+	"github.com/shirou/gopsutil/v3/mem"
+)
+
+// baselineCPUSample is how long baseline_check samples CPU usage.
+const baselineCPUSample = 500 * time.Millisecond
+
+// baselineMetrics are the metrics a BASELINES_FILE can set thresholds for,
+// in report order.
+var baselineMetrics = []string{"cpu_percent", "memory_percent", "swap_percent", "disk_percent", "load1", "load5", "load15"}
+
+// Severities of a metric compared against its baseline.
+const (
+	severityOK       = "ok"
+	severityWarning  = "warning"
+	severityCritical = "critical"
+)
+
+// baseline holds the thresholds of one metric. A value at or above a
+// threshold is out of range; either threshold may be left unset.
+type baseline struct {
+	Warn     *float64 `json:"warn,omitempty"`
+	Critical *float64 `json:"critical,omitempty"`
+}
+
+// severity classifies v against the thresholds.
+func (b baseline) severity(v float64) string {
+	switch {
+	case b.Critical != nil && v >= *b.Critical:
+		return severityCritical
+	case b.Warn != nil && v >= *b.Warn:
+		return severityWarning
+	}
+	return severityOK
+}
+
+// baselines maps metric names to their thresholds.
+type baselines map[string]baseline
+
+// loadBaselines reads the BASELINES_FILE document, e.g.
+//
+//	{"cpu_percent": {"warn": 80, "critical": 95}, "load1": {"critical": 8}}
+//
+// It is read on every call so edits apply without a restart. An empty path
+// or a missing file returns nil baselines.
+func loadBaselines(path string) (baselines, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid BASELINES_FILE: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var b baselines
+	if err := dec.Decode(&b); err != nil {
+		return nil, fmt.Errorf("invalid BASELINES_FILE %q: %w", path, err)
+	}
+	if err := b.validate(); err != nil {
+		return nil, fmt.Errorf("invalid BASELINES_FILE %q: %w", path, err)
+	}
+	return b, nil
+}
+
+// validate rejects unknown metrics, entries without a threshold and warn
+// thresholds above the critical one.
+func (b baselines) validate() error {
+	for name, t := range b {
+		if !slices.Contains(baselineMetrics, name) {
+			return fmt.Errorf("unknown metric %q, want one of %s", name, strings.Join(baselineMetrics, ", "))
+		}
+		if t.Warn == nil && t.Critical == nil {
+			return fmt.Errorf("metric %q sets neither warn nor critical", name)
+		}
+		if t.Warn != nil && t.Critical != nil && *t.Warn > *t.Critical {
+			return fmt.Errorf("metric %q: warn %g is above critical %g", name, *t.Warn, *t.Critical)
+		}
+	}
+	return nil
+}
+
+// baselineReading is the current value of one metric. Where names the
+// partition for disk_percent.
+type baselineReading struct {
+	Value float64
+	Where string
+	Err   error
+}
+
+// readBaselineMetrics collects the named metrics. Only requested metrics are
+// read, so the CPU sample is skipped when no CPU baseline is set.
+func readBaselineMetrics(ctx context.Context, names []string, disk diskReportOptions) map[string]baselineReading {
+	readings := make(map[string]baselineReading, len(names))
+	if slices.Contains(names, "cpu_percent") {
+		var r baselineReading
+		var pct []float64
+		if pct, r.Err = cpu.PercentWithContext(ctx, baselineCPUSample, false); r.Err == nil && len(pct) > 0 {
+			r.Value = pct[0]
+		}
+		readings["cpu_percent"] = r
+	}
+	if slices.Contains(names, "memory_percent") {
+		var r baselineReading
+		if v, err := mem.VirtualMemoryWithContext(ctx); err == nil {
+			r.Value = v.UsedPercent
+		} else {
+			r.Err = err
+		}
+		readings["memory_percent"] = r
+	}
+	if slices.Contains(names, "swap_percent") {
+		var r baselineReading
+		if s, err := mem.SwapMemoryWithContext(ctx); err == nil {
+			r.Value = s.UsedPercent
+		} else {
+			r.Err = err
+		}
+		readings["swap_percent"] = r
+	}
+	if slices.Contains(names, "disk_percent") {
+		readings["disk_percent"] = fullestPartition(disk)
+	}
+	if slices.ContainsFunc(names, func(n string) bool { return strings.HasPrefix(n, "load") }) {
+		if avg, err := load.AvgWithContext(ctx); err == nil {
+			readings["load1"] = baselineReading{Value: avg.Load1}
+			readings["load5"] = baselineReading{Value: avg.Load5}
+			readings["load15"] = baselineReading{Value: avg.Load15}
+		} else {
+			for _, name := range []string{"load1", "load5", "load15"} {
+				readings[name] = baselineReading{Err: err}
+			}
+		}
+	}
+	return readings
+}
+
+// fullestPartition is the disk_percent reading: the highest usage among the
+// reported partitions, skipping read-only images as the disk health check does.
+func fullestPartition(opts diskReportOptions) baselineReading {
+	parts, err := reportPartitions(opts)
+	if err != nil {
+		return baselineReading{Err: err}
+	}
+	r := baselineReading{Err: errors.New("no partitions")}
+	for _, p := range parts {
+		if p.Error != "" || p.Total == 0 || slices.Contains(readOnlyImageFS, p.Fstype) {
+			continue
+		}
+		if r.Err != nil || p.Percent > r.Value {
+			r = baselineReading{Value: p.Percent, Where: p.Mountpoint}
+		}
+	}
+	return r
+}
+
+// formatBaselineCheck renders each configured metric against its thresholds,
+// followed by the metrics out of range.
+func formatBaselineCheck(path string, b baselines, readings map[string]baselineReading) string {
+	var sb strings.Builder
+	sb.WriteString("Baseline Check\n")
+	sb.WriteString("==============\n\n")
+	sb.WriteString(fmt.Sprintf("Baselines: %s\n\n", path))
+	sb.WriteString(fmt.Sprintf("%-16s %10s %10s %10s  %s\n", "Metric", "Current", "Warn", "Critical", "Status"))
+
+	threshold := func(v *float64) string {
+		if v == nil {
+			return "-"
+		}
+		return fmt.Sprintf("%.2f", *v)
+	}
+	var out []string
+	for _, name := range baselineMetrics {
+		t, ok := b[name]
+		if !ok {
+			continue
+		}
+		r := readings[name]
+		if r.Err != nil {
+			sb.WriteString(fmt.Sprintf("%-16s %10s %10s %10s  unavailable: %v\n", name, "-", threshold(t.Warn), threshold(t.Critical), r.Err))
+			continue
+		}
+		status := t.severity(r.Value)
+		if status != severityOK {
+			out = append(out, fmt.Sprintf("%s (%s)", name, status))
+		}
+		if r.Where != "" {
+			status += " (" + r.Where + ")"
+		}
+		sb.WriteString(fmt.Sprintf("%-16s %10.2f %10s %10s  %s\n", name, r.Value, threshold(t.Warn), threshold(t.Critical), status))
+	}
+	if len(out) == 0 {
+		sb.WriteString("\nAll metrics within baselines\n")
+	} else {
+		sb.WriteString(fmt.Sprintf("\nOut of range: %s\n", strings.Join(out, ", ")))
+	}
+	return sb.String()
+}
+
+// baselineCheckReport is the baseline_check tool: the current metrics
+// compared against the thresholds in path.
+func baselineCheckReport(ctx context.Context, path string, disk diskReportOptions) (string, error) {
+	b, err := loadBaselines(path)
+	if err != nil {
+		return "", err
+	}
+	if len(b) == 0 {
+		return "No baselines configured: set BASELINES_FILE to a JSON file of metric thresholds\n", nil
+	}
+	names := make([]string, 0, len(b))
+	for name := range b {
+		names = append(names, name)
+	}
+	return formatBaselineCheck(path, b, readBaselineMetrics(ctx, names, disk)), nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+)
+
+// cloudLoggingEnabled reads LOG_CLOUD_LOGGING for the logger, which is set up
+// before the rest of the configuration. An unparseable value leaves it off.
+func cloudLoggingEnabled() bool {
+	enabled, _ := strconv.ParseBool(os.Getenv("LOG_CLOUD_LOGGING"))
+	return enabled
+}
+
+// logOutput opens the LOG_OUTPUT destination: stderr (the default), stdout,
+// or a file path, which is appended to. A path that cannot be opened falls
+// back to stderr; the error is returned for the caller to log.
+func logOutput(spec string) (io.Writer, error) {
+	switch spec {
+	case "", "stderr":
+		return os.Stderr, nil
+	case "stdout":
+		return os.Stdout, nil
+	}
+	f, err := os.OpenFile(spec, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return os.Stderr, err
+	}
+	return f, nil
+}
+
+// newLogHandler returns the JSON handler the binary logs to w with. With
+// cloudLogging set, the standard fields are renamed to the ones Cloud Logging
+// reads, so entries get the right severity without a logging agent.
+func newLogHandler(w io.Writer, cloudLogging bool) slog.Handler {
+	opts := &slog.HandlerOptions{}
+	if cloudLogging {
+		opts.ReplaceAttr = cloudLoggingAttr
+	}
+	return slog.NewJSONHandler(w, opts)
+}
+
+// cloudLoggingAttr renames level to severity, msg to message and time to
+// timestamp. Attributes inside groups are left alone.
+func cloudLoggingAttr(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) > 0 {
+		return a
+	}
+	switch a.Key {
+	case slog.LevelKey:
+		level, _ := a.Value.Any().(slog.Level)
+		return slog.String("severity", cloudSeverity(level))
+	case slog.MessageKey:
+		a.Key = "message"
+	case slog.TimeKey:
+		a.Key = "timestamp"
+	}
+	return a
+}
+
+// cloudSeverity maps a slog level to a Cloud Logging LogSeverity name.
+func cloudSeverity(level slog.Level) string {
+	switch {
+	case level < slog.LevelInfo:
+		return "DEBUG"
+	case level < slog.LevelWarn:
+		return "INFO"
+	case level < slog.LevelError:
+		return "WARNING"
+	default:
+		return "ERROR"
+	}
+}
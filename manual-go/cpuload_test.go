@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCPULoadInterval(t *testing.T) {
+	for _, tt := range []struct {
+		ms    int
+		limit time.Duration
+		want  time.Duration
+	}{
+		{0, 5 * time.Second, time.Second},
+		{250, 5 * time.Second, 250 * time.Millisecond},
+		{0, 500 * time.Millisecond, 500 * time.Millisecond},
+	} {
+		if got := cpuLoadInterval(tt.ms, tt.limit); got != tt.want {
+			t.Errorf("cpuLoadInterval(%d, %s) = %s, want %s", tt.ms, tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestCPULoadReport(t *testing.T) {
+	orig := cpuPercent
+	defer func() { cpuPercent = orig }()
+
+	cpuPercent = func(ctx context.Context, interval time.Duration, perCPU bool) ([]float64, error) {
+		if !perCPU {
+			t.Error("Expected a per-core sample")
+		}
+		return []float64{10, 30, 95.5, 0}, nil
+	}
+	out := cpuLoadReport(context.Background(), 200*time.Millisecond)
+	for _, want := range []string{"Interval:         200ms", "Aggregate:        33.9%", "Core 0:           10.0%", "Core 2:           95.5%", "Core 3:           0.0%"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in:\n%s", want, out)
+		}
+	}
+
+	cpuPercent = func(context.Context, time.Duration, bool) ([]float64, error) {
+		return nil, errors.New("no /proc/stat")
+	}
+	if out := cpuLoadReport(context.Background(), time.Second); !strings.Contains(out, "Error retrieving CPU load: no /proc/stat") {
+		t.Errorf("Expected an error line, got:\n%s", out)
+	}
+}
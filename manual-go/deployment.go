@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// metadataBaseURL is the GCE metadata server's instance directory, which
+	// Cloud Run serves too.
+	metadataBaseURL = "http://metadata.google.internal/computeMetadata/v1/instance/"
+	// metadataTimeout bounds each metadata request, so the section stays fast
+	// where the server is unreachable.
+	metadataTimeout = time.Second
+)
+
+// deploymentInfo identifies the Cloud Run revision serving the process.
+type deploymentInfo struct {
+	Service       string `json:"service"`
+	Revision      string `json:"revision"`
+	Configuration string `json:"configuration"`
+	Region        string `json:"region,omitempty"`
+	Zone          string `json:"zone,omitempty"`
+}
+
+// metadataLocation asks the metadata server for the region and zone once per
+// process, since neither changes while it runs. Either is empty when the
+// server does not answer.
+var metadataLocation = sync.OnceValues(func() (region, zone string) {
+	return fetchLocation(metadataBaseURL)
+})
+
+// fetchLocation reads the region and zone under base. The server returns
+// full resource names such as "projects/123/regions/us-central1", of which
+// only the last element is kept.
+func fetchLocation(base string) (region, zone string) {
+	if v := fetchMetadata(base + "region"); v != "" {
+		region = path.Base(v)
+	}
+	if v := fetchMetadata(base + "zone"); v != "" {
+		zone = path.Base(v)
+	}
+	return region, zone
+}
+
+// fetchMetadata returns one metadata value, or "" on any failure.
+func fetchMetadata(url string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), metadataTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return ""
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return ""
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return ""
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(body))
+}
+
+// readDeployment returns the Cloud Run deployment from K_SERVICE, K_REVISION
+// and K_CONFIGURATION, with the region and zone from the metadata server when
+// metadata is set. The boolean is false when not running on Cloud Run.
+func readDeployment(metadata bool) (deploymentInfo, bool) {
+	d := deploymentInfo{
+		Service:       os.Getenv("K_SERVICE"),
+		Revision:      os.Getenv("K_REVISION"),
+		Configuration: os.Getenv("K_CONFIGURATION"),
+	}
+	if d.Service == "" {
+		return d, false
+	}
+	if metadata {
+		d.Region, d.Zone = metadataLocation()
+	}
+	return d, true
+}
+
+// format renders the deployment fields in the report's label style.
+func (d deploymentInfo) format() string {
+	orUnknown := func(v string) string {
+		if v == "" {
+			return "unknown"
+		}
+		return v
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Service:          %s\n", d.Service))
+	sb.WriteString(fmt.Sprintf("Revision:         %s\n", orUnknown(d.Revision)))
+	sb.WriteString(fmt.Sprintf("Configuration:    %s\n", orUnknown(d.Configuration)))
+	sb.WriteString(fmt.Sprintf("Region:           %s\n", orUnknown(d.Region)))
+	sb.WriteString(fmt.Sprintf("Zone:             %s\n", orUnknown(d.Zone)))
+	return sb.String()
+}
+
+// deploymentSection is the Deployment section of the system report. It is
+// omitted when not running on Cloud Run.
+func deploymentSection(metadata bool) (string, error) {
+	d, ok := readDeployment(metadata)
+	if !ok {
+		return "", nil
+	}
+	return "\nDeployment\n----------\n" + d.format(), nil
+}
+
+// deploymentReport is the deployment_info tool result.
+func deploymentReport(metadata bool) string {
+	d, ok := readDeployment(metadata)
+	if !ok {
+		return "Not running on Cloud Run (K_SERVICE is not set)\n"
+	}
+	return "Deployment Information\n======================\n\n" + d.format()
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFetchLocation(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata-Flavor") != "Google" {
+			http.Error(w, "missing Metadata-Flavor", http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/region":
+			w.Write([]byte("projects/123/regions/us-central1"))
+		case "/zone":
+			w.Write([]byte("projects/123/zones/us-central1-1\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	region, zone := fetchLocation(srv.URL + "/")
+	if region != "us-central1" || zone != "us-central1-1" {
+		t.Errorf("Expected us-central1 and us-central1-1, got %q and %q", region, zone)
+	}
+	if region, zone := fetchLocation(srv.URL + "/missing/"); region != "" || zone != "" {
+		t.Errorf("Expected no location from a failing server, got %q and %q", region, zone)
+	}
+}
+
+func TestDeploymentSection(t *testing.T) {
+	t.Setenv("K_SERVICE", "")
+	if out, err := deploymentSection(false); err != nil || out != "" {
+		t.Errorf("Expected the section to be omitted off Cloud Run, got %q, %v", out, err)
+	}
+	if out := deploymentReport(false); !strings.Contains(out, "Not running on Cloud Run") {
+		t.Errorf("Expected the off Cloud Run note, got %q", out)
+	}
+
+	t.Setenv("K_SERVICE", "sysutils")
+	t.Setenv("K_REVISION", "sysutils-00042-abc")
+	t.Setenv("K_CONFIGURATION", "")
+	out, err := deploymentSection(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Deployment\n", "sysutils", "sysutils-00042-abc", "Configuration:    unknown", "Region:           unknown"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in:\n%s", want, out)
+		}
+	}
+}
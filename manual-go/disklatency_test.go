@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/disk"
+)
+
+func TestCollectDiskLatency(t *testing.T) {
+	origList, origStat := listPartitions, statUsage
+	defer func() { listPartitions, statUsage = origList, origStat }()
+
+	listPartitions = func(all bool) ([]disk.PartitionStat, error) {
+		return []disk.PartitionStat{
+			{Mountpoint: "/", Fstype: "ext4"},
+			{Mountpoint: "/mnt/nfs", Fstype: "nfs"},
+		}, nil
+	}
+	hung := make(chan struct{})
+	defer close(hung)
+	statUsage = func(path string) (*disk.UsageStat, error) {
+		if path == "/mnt/nfs" {
+			<-hung
+			return nil, errors.New("stale")
+		}
+		return &disk.UsageStat{}, nil
+	}
+
+	start := time.Now()
+	out, err := collectDiskLatency(context.Background(), time.Second, 50*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the hung mount to be bounded by the timeout, took %s", elapsed)
+	}
+	if !strings.Contains(out, "/mnt/nfs") || !strings.Contains(out, "TIMEOUT") || !strings.Contains(out, "1 of 2 mounts") {
+		t.Errorf("Expected the hung mount flagged, got:\n%s", out)
+	}
+}
+
+func TestDiskLatencyInput(t *testing.T) {
+	if th, to := (diskLatencyInput{}).durations(); th != defaultSlowMountThreshold || to != defaultMountProbeTimeout {
+		t.Errorf("Expected defaults, got %s %s", th, to)
+	}
+	for _, in := range []diskLatencyInput{{ThresholdMS: -1}, {TimeoutMS: 60000}} {
+		if err := in.validate(); !errors.Is(err, errInvalidInput) {
+			t.Errorf("Expected invalid input for %+v, got %v", in, err)
+		}
+	}
+}
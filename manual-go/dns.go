@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// dnsTestTimeout bounds the optional test resolution of dns_info.
+const dnsTestTimeout = 5 * time.Second
+
+// dnsConfig is the resolver configuration dns_info reports.
+type dnsConfig struct {
+	Nameservers []string
+	Search      []string
+	Options     []string
+}
+
+// dnsInfoInput is the dns_info tool input.
+type dnsInfoInput struct {
+	Hostname string `json:"hostname,omitempty" jsonschema:"host name to resolve as a test, reporting latency and addresses (overrides DNS_TEST_HOSTNAME); empty skips the test unless DNS_TEST_HOSTNAME is set"`
+}
+
+// validate rejects a hostname the resolver could not be asked about.
+func (in dnsInfoInput) validate() error {
+	if in.Hostname != "" && !validHostname(in.Hostname) {
+		return fmt.Errorf("%w: hostname %q is not a valid host name", errInvalidInput, in.Hostname)
+	}
+	return nil
+}
+
+// parseResolvConf reads resolv.conf(5) syntax. As in the resolver, a later
+// search or domain line replaces an earlier one.
+func parseResolvConf(r io.Reader) dnsConfig {
+	var cfg dnsConfig
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := sc.Text()
+		if i := strings.IndexAny(line, "#;"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "nameserver":
+			cfg.Nameservers = append(cfg.Nameservers, fields[1])
+		case "search", "domain":
+			cfg.Search = fields[1:]
+		case "options":
+			cfg.Options = append(cfg.Options, fields[1:]...)
+		}
+	}
+	return cfg
+}
+
+// validHostname reports whether host can be sent to the resolver as is.
+func validHostname(host string) bool {
+	if host == "" || len(host) > 253 {
+		return false
+	}
+	for _, r := range host {
+		if !(r == '.' || r == '-' || r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// dnsInfoReport renders the resolver configuration and, when host is set,
+// the result and latency of resolving it with the system resolver. host must
+// already be validated. An
+// unreadable configuration is reported rather than failing the tool, since the
+// test resolution is still useful on its own.
+func dnsInfoReport(ctx context.Context, host string) string {
+	var sb strings.Builder
+	sb.WriteString("DNS Configuration Report\n")
+	sb.WriteString("========================\n\n")
+
+	cfg, source, err := readDNSConfig()
+	if err != nil {
+		sb.WriteString(fmt.Sprintf("Error reading %s: %v\n", source, err))
+	} else {
+		sb.WriteString(fmt.Sprintf("Source: %s\n", source))
+		sb.WriteString(fmt.Sprintf("Nameservers: %s\n", listOrNone(cfg.Nameservers)))
+		sb.WriteString(fmt.Sprintf("Search Domains: %s\n", listOrNone(cfg.Search)))
+		if len(cfg.Options) > 0 {
+			sb.WriteString(fmt.Sprintf("Options: %s\n", strings.Join(cfg.Options, " ")))
+		}
+	}
+
+	if host == "" {
+		return sb.String()
+	}
+	ctx, cancel := context.WithTimeout(ctx, dnsTestTimeout)
+	defer cancel()
+	start := time.Now()
+	addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+	elapsed := time.Since(start).Round(time.Millisecond)
+	sb.WriteString(fmt.Sprintf("\nTest Resolution: %s\n", host))
+	if err != nil {
+		sb.WriteString(fmt.Sprintf("  Result: FAILED after %v: %v\n", elapsed, err))
+	} else {
+		sb.WriteString(fmt.Sprintf("  Result: %s\n", strings.Join(addrs, ", ")))
+		sb.WriteString(fmt.Sprintf("  Latency: %v\n", elapsed))
+	}
+	return sb.String()
+}
+
+// listOrNone joins items for a report line, or says there are none.
+func listOrNone(items []string) string {
+	if len(items) == 0 {
+		return "(none)"
+	}
+	return strings.Join(items, ", ")
+}
@@ -0,0 +1,31 @@
+//go:build !windows
+
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDNSInfoReport(t *testing.T) {
+	old := resolvConfPath
+	t.Cleanup(func() { resolvConfPath = old })
+
+	resolvConfPath = filepath.Join(t.TempDir(), "missing")
+	out := dnsInfoReport(context.Background(), "localhost")
+	if !strings.Contains(out, "Error reading") || !strings.Contains(out, "Test Resolution: localhost") {
+		t.Errorf("Expected a read error and the test resolution, got:\n%s", out)
+	}
+
+	resolvConfPath = filepath.Join(t.TempDir(), "resolv.conf")
+	if err := os.WriteFile(resolvConfPath, []byte("nameserver 1.1.1.1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	out = dnsInfoReport(context.Background(), "")
+	if !strings.Contains(out, "Nameservers: 1.1.1.1") || !strings.Contains(out, "Search Domains: (none)") || strings.Contains(out, "Test Resolution") {
+		t.Errorf("Unexpected report:\n%s", out)
+	}
+}
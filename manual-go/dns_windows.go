@@ -0,0 +1,52 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// readDNSConfig collects the DNS servers and suffixes of the adapters that
+// are up, as reported by GetAdaptersAddresses.
+func readDNSConfig() (dnsConfig, string, error) {
+	const source = "GetAdaptersAddresses"
+	size := uint32(15000)
+	var buf []byte
+	for range 3 {
+		buf = make([]byte, size)
+		err := windows.GetAdaptersAddresses(windows.AF_UNSPEC, windows.GAA_FLAG_SKIP_ANYCAST|windows.GAA_FLAG_SKIP_MULTICAST, 0, (*windows.IpAdapterAddresses)(unsafe.Pointer(&buf[0])), &size)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, windows.ERROR_BUFFER_OVERFLOW) {
+			return dnsConfig{}, source, err
+		}
+		buf = nil
+	}
+	if buf == nil {
+		return dnsConfig{}, source, errors.New("adapter list kept growing")
+	}
+
+	var cfg dnsConfig
+	for aa := (*windows.IpAdapterAddresses)(unsafe.Pointer(&buf[0])); aa != nil; aa = aa.Next {
+		if aa.OperStatus != windows.IfOperStatusUp {
+			continue
+		}
+		for ds := aa.FirstDnsServerAddress; ds != nil; ds = ds.Next {
+			if ip := ds.Address.IP(); ip != nil && !slices.Contains(cfg.Nameservers, ip.String()) {
+				cfg.Nameservers = append(cfg.Nameservers, ip.String())
+			}
+		}
+		if suffix := windows.UTF16PtrToString(aa.DnsSuffix); suffix != "" && !slices.Contains(cfg.Search, suffix) {
+			cfg.Search = append(cfg.Search, suffix)
+		}
+		for s := aa.FirstDnsSuffix; s != nil; s = s.Next {
+			if suffix := windows.UTF16ToString(s.String[:]); suffix != "" && !slices.Contains(cfg.Search, suffix) {
+				cfg.Search = append(cfg.Search, suffix)
+			}
+		}
+	}
+	return cfg, source, nil
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestFormatDoctor(t *testing.T) {
+	out, failed := formatDoctor([]doctorCheck{
+		{Name: "ok", Status: doctorPass, Remedy: "hidden remedy"},
+		{Name: "soft", Status: doctorWarn, Remedy: "warn remedy"},
+	})
+	if failed {
+		t.Error("Expected warnings alone not to count as a failure")
+	}
+	if strings.Contains(out, "hidden remedy") || !strings.Contains(out, "warn remedy") {
+		t.Errorf("Expected remedies only for checks that did not pass, got: %s", out)
+	}
+
+	_, failed = formatDoctor([]doctorCheck{{Name: "bad", Status: doctorFail}})
+	if !failed {
+		t.Error("Expected a FAIL check to be reported as a failure")
+	}
+}
+
+func TestCheckPortInUse(t *testing.T) {
+	ln, err := net.Listen("tcp", "0.0.0.0:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer ln.Close()
+
+	_, port, _ := net.SplitHostPort(ln.Addr().String())
+	if c := checkPort(port); c.Status != doctorFail {
+		t.Errorf("Expected FAIL for a port in use, got %s: %s", c.Status, c.Detail)
+	}
+}
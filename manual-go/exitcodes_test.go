@@ -0,0 +1,78 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// TestMain lets exit-code tests run main in a child process: when
+// EXIT_CODE_TEST_ARGS is set, the test binary runs main with those arguments
+// instead of the tests.
+func TestMain(m *testing.M) {
+	if args, ok := os.LookupEnv("EXIT_CODE_TEST_ARGS"); ok {
+		os.Args = append([]string{os.Args[0]}, strings.Fields(args)...)
+		main()
+		os.Exit(exitOK)
+	}
+	os.Exit(m.Run())
+}
+
+// runMainExitCode runs main with args and env in a child process and returns
+// its exit code. ENV_FILE is cleared so a local .env cannot interfere.
+func runMainExitCode(t *testing.T, args string, env ...string) int {
+	t.Helper()
+	cmd := exec.Command(os.Args[0])
+	cmd.Env = append(os.Environ(), "EXIT_CODE_TEST_ARGS="+args, "ENV_FILE=")
+	cmd.Env = append(cmd.Env, env...)
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return exitOK
+}
+
+func TestExitCodes(t *testing.T) {
+	tests := []struct {
+		name string
+		args string
+		env  []string
+		want int
+	}{
+		{"help", "help", nil, exitOK},
+		{"unknown command", "bogus", nil, exitConfig},
+		{"invalid config", "config", []string{"MAX_UPTIME=-1s"}, exitConfig},
+		{"missing key", "check --offline", []string{"MCP_API_KEY="}, exitAuth},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := runMainExitCode(t, tt.args, tt.env...); got != tt.want {
+				t.Errorf("Expected exit code %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestUnknownCommandOutput(t *testing.T) {
+	cmd := exec.Command(os.Args[0])
+	cmd.Env = append(os.Environ(), "EXIT_CODE_TEST_ARGS=bogus", "ENV_FILE=")
+	var stdout, stderr strings.Builder
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != exitConfig {
+		t.Fatalf("Expected exit code %d, got %v", exitConfig, err)
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("Expected nothing on stdout, got %q", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "Unknown command: bogus") || !strings.Contains(stderr.String(), usageText) {
+		t.Errorf("Expected the error and the usage on stderr, got %q", stderr.String())
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// toolInput is implemented by every tool input struct. The go-sdk derives the
+// JSON schema from the struct's json and jsonschema tags and rejects
+// arguments of the wrong type; validate covers what a schema cannot express,
+// such as ranges, caps and enumerations. Errors should wrap errInvalidInput.
+type toolInput interface {
+	validate() error
+}
+
+// emptyInput is the input of tools that take no arguments.
+type emptyInput struct{}
+
+func (emptyInput) validate() error { return nil }
+
+// validated runs the input's validate before h, reporting a failure as a
+// JSON-RPC invalid params error so handlers only see accepted input.
+func validated[In toolInput](h mcp.ToolHandlerFor[In, any]) mcp.ToolHandlerFor[In, any] {
+	return func(ctx context.Context, request *mcp.CallToolRequest, input In) (*mcp.CallToolResult, any, error) {
+		if err := input.validate(); err != nil {
+			return nil, nil, toolError(err)
+		}
+		return h(ctx, request, input)
+	}
+}
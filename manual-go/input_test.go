@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestToolInputValidate(t *testing.T) {
+	negative := -1
+	tests := []struct {
+		name  string
+		input toolInput
+		valid bool
+	}{
+		{"empty", emptyInput{}, true},
+		{"system info defaults", systemInfoInput{}, true},
+		{"system info markdown", systemInfoInput{Format: "markdown", Timezone: "Europe/Berlin"}, true},
+		{"system info bad format", systemInfoInput{Format: "html"}, false},
+		{"system info swap sample over cap", systemInfoInput{SwapSampleMS: 10001}, false},
+		{"system info budget", systemInfoInput{MaxLines: 40, MaxChars: 2000}, true},
+		{"system info negative max_lines", systemInfoInput{MaxLines: -1}, false},
+		{"disk usage json", diskUsageInput{Format: "json"}, true},
+		{"disk usage bad format", diskUsageInput{Format: "xml"}, false},
+		{"disk usage negative min", diskUsageInput{MinTotalMB: &negative}, false},
+		{"disk latency cap", diskLatencyInput{TimeoutMS: 30000}, true},
+		{"disk latency over cap", diskLatencyInput{TimeoutMS: 30001}, false},
+		{"recent logs cap", recentLogsInput{Lines: maxLogTailLines}, true},
+		{"recent logs over cap", recentLogsInput{Lines: maxLogTailLines + 1}, false},
+		{"recent logs negative", recentLogsInput{Lines: -1}, false},
+		{"top processes page", topProcessesInput{Offset: 40, Limit: 20, SortBy: "fds"}, true},
+		{"top processes negative offset", topProcessesInput{Offset: -1}, false},
+		{"top processes negative limit", topProcessesInput{Limit: -1}, false},
+		{"top processes bad sort", topProcessesInput{SortBy: "pid"}, false},
+	}
+	for _, tt := range tests {
+		err := tt.input.validate()
+		if tt.valid && err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		}
+		if !tt.valid && !errors.Is(err, errInvalidInput) {
+			t.Errorf("%s: expected invalid input, got: %v", tt.name, err)
+		}
+	}
+}
+
+func TestValidatedSkipsHandlerOnBadInput(t *testing.T) {
+	called := false
+	h := validated(func(ctx context.Context, request *mcp.CallToolRequest, input recentLogsInput) (*mcp.CallToolResult, any, error) {
+		called = true
+		return &mcp.CallToolResult{}, nil, nil
+	})
+
+	_, _, err := h(context.Background(), nil, recentLogsInput{Lines: maxLogTailLines + 1})
+	var wireErr *jsonrpc.Error
+	if !errors.As(err, &wireErr) || wireErr.Code != jsonrpc.CodeInvalidParams {
+		t.Fatalf("Expected an invalid params error, got: %v", err)
+	}
+	if called {
+		t.Error("Handler ran for invalid input")
+	}
+
+	if _, _, err := h(context.Background(), nil, recentLogsInput{Lines: 10}); err != nil || !called {
+		t.Errorf("Expected handler to run for valid input, err=%v", err)
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultKeyCacheTTL is how long a fetched API key is trusted before it is
+// fetched again, when KEY_CACHE_TTL is unset.
+const defaultKeyCacheTTL = 10 * time.Minute
+
+// keyCacheRetry caps the wait before retrying a failed refresh, so a short
+// outage of the key API does not leave a rotated key unseen for a whole TTL.
+const keyCacheRetry = time.Minute
+
+// keyCache re-fetches the expected API key into pending once it is older
+// than ttl, so a key rotated in Google Cloud is picked up without a restart.
+// Requests keep reading pending, whose key is swapped atomically: they never
+// wait on a refresh or see a partial one. A failed refresh keeps serving the
+// last known good key.
+type keyCache struct {
+	pending *pendingKey
+	ttl     time.Duration
+	fetch   func(context.Context) (string, error)
+	timeout time.Duration
+	pin     string
+
+	expires atomic.Int64 // UnixNano
+}
+
+// newKeyCache returns a cache of cfg's project key, fetched with fetch.
+func newKeyCache(cfg *Config, pending *pendingKey, fetch func(context.Context) (string, error)) *keyCache {
+	return &keyCache{pending: pending, ttl: cfg.KeyCacheTTL, fetch: fetch, timeout: cfg.KeyFetchTimeout, pin: cfg.KeyFingerprint}
+}
+
+// expiry returns when the cached key is due to be fetched again.
+func (c *keyCache) expiry() time.Time {
+	return time.Unix(0, c.expires.Load())
+}
+
+// refresh fetches the key once. Only a key that was found and matches
+// MCP_API_KEY_FINGERPRINT replaces the cached one and resets the expiry; on
+// error the cached key is left alone.
+func (c *keyCache) refresh(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	key, err := c.fetch(ctx)
+	if err == nil && key == "" {
+		err = errors.New("no API key found")
+	}
+	if err == nil {
+		err = checkKeyFingerprint(key, c.pin)
+	}
+	if err != nil {
+		return err
+	}
+	if old := c.pending.key.Load(); old == nil || *old != key {
+		slog.Info("API key changed", "key", fingerprint(key))
+	}
+	c.pending.set(key)
+	c.expires.Store(time.Now().Add(c.ttl).UnixNano())
+	return nil
+}
+
+// start waits for the initial fetch, then refreshes the key each time it
+// expires from a single goroutine. A failed refresh is retried after
+// keyCacheRetry, or the TTL if that is shorter. The returned stop function
+// ends the goroutine and waits for it.
+func (c *keyCache) start() (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.pending.ready:
+		}
+		c.expires.Store(time.Now().Add(c.ttl).UnixNano())
+		timer := time.NewTimer(c.ttl)
+		defer timer.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-timer.C:
+			}
+			if err := c.refresh(ctx); err != nil {
+				retry := min(c.ttl, keyCacheRetry)
+				c.expires.Store(time.Now().Add(retry).UnixNano())
+				slog.Warn("API key refresh failed; keeping the last known good key", "error", err, "retry_in", retry)
+			}
+			timer.Reset(time.Until(c.expiry()))
+		}
+	})
+	slog.Info("API key cache started", "ttl", c.ttl)
+	return func() {
+		cancel()
+		wg.Wait()
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestKeyCacheRefresh(t *testing.T) {
+	pending := startKeyFetch(func() (string, error) { return "old-key", nil })
+	<-pending.ready
+	var next atomic.Pointer[string]
+	var fetchErr atomic.Pointer[error]
+	cache := newKeyCache(&Config{KeyCacheTTL: time.Hour, KeyFetchTimeout: time.Second}, pending, func(context.Context) (string, error) {
+		if err := fetchErr.Load(); err != nil {
+			return "", *err
+		}
+		return *next.Load(), nil
+	})
+	current := func() string { key, _ := pending.wait(context.Background()); return key }
+
+	rotated := "new-key"
+	next.Store(&rotated)
+	if err := cache.refresh(context.Background()); err != nil || current() != "new-key" {
+		t.Fatalf("Expected the rotated key, got %q, %v", current(), err)
+	}
+	if until := time.Until(cache.expiry()); until < 59*time.Minute {
+		t.Errorf("Expected the expiry a TTL away, got %s", until)
+	}
+
+	empty := ""
+	next.Store(&empty)
+	if err := cache.refresh(context.Background()); err == nil || current() != "new-key" {
+		t.Errorf("Expected an empty fetch to keep the last good key, got %q, %v", current(), err)
+	}
+	failed := errors.New("permission denied")
+	fetchErr.Store(&failed)
+	if err := cache.refresh(context.Background()); !errors.Is(err, failed) || current() != "new-key" {
+		t.Errorf("Expected a failed fetch to keep the last good key, got %q, %v", current(), err)
+	}
+}
+
+func TestKeyCacheBackgroundRefresh(t *testing.T) {
+	pending := startKeyFetch(func() (string, error) { return "old-key", nil })
+	var fetches atomic.Int32
+	cache := newKeyCache(&Config{KeyCacheTTL: 10 * time.Millisecond, KeyFetchTimeout: time.Second}, pending, func(context.Context) (string, error) {
+		if fetches.Add(1) > 1 {
+			return "", errors.New("key API unavailable")
+		}
+		return "new-key", nil
+	})
+	stop := cache.start()
+	defer stop()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for fetches.Load() < 3 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if n := fetches.Load(); n < 3 {
+		t.Fatalf("Expected repeated refreshes, got %d", n)
+	}
+	if key, _ := pending.wait(context.Background()); key != "new-key" {
+		t.Errorf("Expected failed refreshes to keep the last good key, got %q", key)
+	}
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+)
+
+// pendingKey is the expected API key being fetched in the background. The
+// fetch starts with the server so that a cold start does not block the first
+// MCP request, and health checks never wait on it. The key can later be
+// swapped atomically by a manual refresh.
+type pendingKey struct {
+	ready chan struct{}
+	key   atomic.Pointer[string]
+	// err is why the fetched key was rejected; it is set before ready is
+	// closed and never changes afterwards.
+	err error
+}
+
+// startKeyFetch runs fetch in the background and returns immediately. A
+// fetch error rejects the key for good: requests that need it get a 503
+// and the server is expected to shut down.
+func startKeyFetch(fetch func() (string, error)) *pendingKey {
+	p := &pendingKey{ready: make(chan struct{})}
+	go func() {
+		defer close(p.ready)
+		key, err := fetch()
+		if err != nil {
+			p.err = err
+			key = ""
+		}
+		// A manual refresh that finished first has the newer key
+		p.key.CompareAndSwap(nil, &key)
+	}()
+	return p
+}
+
+// rejected returns the fetch error once the fetch has finished, or nil.
+func (p *pendingKey) rejected() error {
+	select {
+	case <-p.ready:
+		return p.err
+	default:
+		return nil
+	}
+}
+
+// wait blocks until the fetch finishes or ctx is done. The boolean reports
+// whether the fetch finished; the key may still be empty if none was found.
+func (p *pendingKey) wait(ctx context.Context) (string, bool) {
+	select {
+	case <-p.ready:
+		return *p.key.Load(), true
+	case <-ctx.Done():
+		return "", false
+	}
+}
+
+func (p *pendingKey) set(key string) {
+	p.key.Store(&key)
+}
+
+// apiKeyCheck reports whether the expected API key is established, without
+// waiting for a fetch in progress. A missing key only fails the check when
+// requests are refused because of it.
+func apiKeyCheck(cfg *Config, pending *pendingKey) func(context.Context) healthCheck {
+	return func(context.Context) healthCheck {
+		check := healthCheck{Name: "api_key", Status: healthOK}
+		select {
+		case <-pending.ready:
+		default:
+			if cfg.AuthMode != "none" {
+				check.Status, check.Detail = healthWarn, "fetch in progress"
+				return check
+			}
+		}
+		switch key := pending.key.Load(); {
+		case cfg.AuthMode == "none":
+			check.Detail = "checks disabled (AUTH_MODE=none)"
+		case pending.rejected() != nil:
+			check.Status, check.Detail = healthFail, "rejected: "+pending.rejected().Error()
+		case key != nil && *key != "":
+			check.Detail = "established"
+		case cfg.AuthMode == "any" && cfg.BearerToken != "":
+			check.Status, check.Detail = healthWarn, "not established; only the bearer token is accepted"
+		case !cfg.RequireAPIKey:
+			check.Status, check.Detail = healthWarn, "not established; serving without a key check (REQUIRE_API_KEY=false)"
+		default:
+			check.Status, check.Detail = healthFail, "not established; MCP requests are refused"
+		}
+		return check
+	}
+}
+
+// apiKeyReady reports whether API keys can be checked, for /readyz: the
+// key fetch has finished and either found a key or the missing key is
+// explicitly allowed, by REQUIRE_API_KEY=false or a bearer token under
+// AUTH_MODE=any. Other auth modes never wait on the key.
+func apiKeyReady(cfg *Config, pending *pendingKey) error {
+	if cfg.AuthMode != "apikey" && cfg.AuthMode != "any" {
+		return nil
+	}
+	select {
+	case <-pending.ready:
+	default:
+		return errors.New("API key fetch still in progress")
+	}
+	if apiKeyCheck(cfg, pending)(context.Background()).Status == healthFail {
+		return errors.New("API key not established")
+	}
+	return nil
+}
+
+// newKeyRefreshHandler serves POST /admin/refresh-key, which re-fetches the
+// expected key immediately so a rotated key is picked up without a restart.
+// It runs behind the regular auth check; when an admin token is configured,
+// the request must also carry it in the x-admin-token header.
+func newKeyRefreshHandler(cfg *Config, pending *pendingKey, fetch func(context.Context) (string, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if cfg.AdminToken != "" && !secretEqual(r.Header.Get("x-admin-token"), cfg.AdminToken) {
+			slog.Warn("Key refresh rejected: invalid admin token")
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		switch {
+		case cfg.AuthMode == "none":
+			http.Error(w, "Conflict: API key checks are disabled (AUTH_MODE=none)", http.StatusConflict)
+			return
+		case cfg.APIKey != "":
+			http.Error(w, "Conflict: MCP_API_KEY is set; there is no fetched key to refresh", http.StatusConflict)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), cfg.KeyFetchTimeout)
+		defer cancel()
+		key, err := fetch(ctx)
+		if err == nil {
+			err = checkKeyFingerprint(key, cfg.KeyFingerprint)
+		}
+		if err != nil {
+			slog.Error("Manual API key refresh failed", "error", err)
+			http.Error(w, "Key refresh failed: "+err.Error(), http.StatusBadGateway)
+			return
+		}
+		pending.set(key)
+		slog.Info("API key refreshed manually", "key", fingerprint(key))
+		fmt.Fprintf(w, "Key refreshed (%s)\n", fingerprint(key))
+	}
+}
+
+// fetchProjectKey fetches the expected key from the current project.
+func (c *Config) fetchProjectKey(ctx context.Context) (string, error) {
+	if c.Offline {
+		return "", errOffline
+	}
+	projectID := getProjectID()
+	if projectID == "" {
+		return "", fmt.Errorf("no Google Cloud project configured")
+	}
+	return fetchMCPAPIKey(ctx, projectID)
+}
+
+// errKeyPinMismatch rejects a fetched key that does not match
+// MCP_API_KEY_FINGERPRINT.
+var errKeyPinMismatch = errors.New("fetched API key does not match the pinned fingerprint")
+
+// resolveExpectedKey returns the key requests are checked against: MCP_API_KEY
+// when set, otherwise the key fetched from the Google Cloud project. A
+// fetched key that does not match MCP_API_KEY_FINGERPRINT is an
+// errKeyPinMismatch.
+func resolveExpectedKey(cfg *Config) (string, error) {
+	expectedKey := cfg.APIKey
+	if expectedKey == "" && (cfg.AuthMode == "apikey" || cfg.AuthMode == "any") {
+		projectID := cfg.fetchProject()
+		if projectID != "" {
+			ctx, cancel := context.WithTimeout(context.Background(), cfg.KeyFetchTimeout)
+			defer cancel()
+			expectedKey, _ = fetchMCPAPIKey(ctx, projectID)
+		}
+		// A key that does not match the pin most likely comes from the wrong
+		// project; serving with it would authenticate the wrong callers.
+		if err := checkKeyFingerprint(expectedKey, cfg.KeyFingerprint); expectedKey != "" && err != nil {
+			slog.Error("Rejecting the fetched API key; shutting down", "error", err)
+			return "", fmt.Errorf("%w: %v", errKeyPinMismatch, err)
+		}
+	}
+
+	switch {
+	case cfg.AuthMode == "none":
+		slog.Warn("AUTH_MODE=none: API key checks are disabled")
+	case cfg.AuthMode == "any":
+		slog.Info("AUTH_MODE=any: accepting an API key or bearer token", "api_key", expectedKey != "", "bearer_token", cfg.BearerToken != "")
+	case expectedKey != "":
+		slog.Info("Effective API Key established")
+	case !cfg.RequireAPIKey:
+		slog.Warn("No API Key found. Serving MCP requests without a key check because REQUIRE_API_KEY=false.")
+	default:
+		slog.Error("No API Key found. Rejecting MCP requests until one is established; set REQUIRE_API_KEY=false to serve without a key check.")
+	}
+	return expectedKey, nil
+}
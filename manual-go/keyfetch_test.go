@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPendingKeyWait(t *testing.T) {
+	release := make(chan struct{})
+	p := startKeyFetch(func() (string, error) {
+		<-release
+		return "fetched-key", nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, ok := p.wait(ctx); ok {
+		t.Fatal("Expected wait to time out while the fetch is still running")
+	}
+
+	close(release)
+	key, ok := p.wait(context.Background())
+	if !ok || key != "fetched-key" {
+		t.Errorf("Expected fetched-key once ready, got %q (ready=%v)", key, ok)
+	}
+}
+
+func TestKeyRefreshHandler(t *testing.T) {
+	pending := startKeyFetch(func() (string, error) { return "old-key", nil })
+	pending.wait(context.Background())
+
+	cfg := &Config{AuthMode: "apikey", AdminToken: "admin", KeyFetchTimeout: time.Second}
+	handler := newKeyRefreshHandler(cfg, pending, func(ctx context.Context) (string, error) {
+		return "new-key", nil
+	})
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/admin/refresh-key", nil))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 without the admin token, got %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/admin/refresh-key", nil)
+	req.Header.Set("x-admin-token", "admix")
+	handler(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 with a wrong admin token, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/admin/refresh-key", nil)
+	req.Header.Set("x-admin-token", "admin")
+	handler(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if key, _ := pending.wait(context.Background()); key != "new-key" {
+		t.Errorf("Expected the refreshed key to be swapped in, got %q", key)
+	}
+}
+
+func TestKeyRefreshHandlerFailure(t *testing.T) {
+	pending := startKeyFetch(func() (string, error) { return "old-key", nil })
+	pending.wait(context.Background())
+
+	cfg := &Config{AuthMode: "apikey", KeyFetchTimeout: time.Second}
+	handler := newKeyRefreshHandler(cfg, pending, func(ctx context.Context) (string, error) {
+		return "", errors.New("not found")
+	})
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/admin/refresh-key", nil))
+	if rec.Code != http.StatusBadGateway {
+		t.Errorf("Expected 502 on fetch failure, got %d", rec.Code)
+	}
+	if key, _ := pending.wait(context.Background()); key != "old-key" {
+		t.Errorf("Expected the old key to be kept on failure, got %q", key)
+	}
+}
+
+func TestKeyRefreshHandlerFingerprintMismatch(t *testing.T) {
+	pending := startKeyFetch(func() (string, error) { return "old-key", nil })
+	pending.wait(context.Background())
+
+	pin, _ := parseKeyFingerprint(fingerprint("old-key"))
+	cfg := &Config{AuthMode: "apikey", KeyFetchTimeout: time.Second, KeyFingerprint: pin}
+	handler := newKeyRefreshHandler(cfg, pending, func(ctx context.Context) (string, error) {
+		return "other-project-key", nil
+	})
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/admin/refresh-key", nil))
+	if rec.Code != http.StatusBadGateway {
+		t.Errorf("Expected 502 for a key that does not match the pin, got %d", rec.Code)
+	}
+	if key, _ := pending.wait(context.Background()); key != "old-key" {
+		t.Errorf("Expected the old key to be kept, got %q", key)
+	}
+}
+
+func TestAPIKeyCheck(t *testing.T) {
+	tests := []struct {
+		cfg    Config
+		key    string
+		status string
+	}{
+		{Config{AuthMode: "apikey", RequireAPIKey: true}, "k", healthOK},
+		{Config{AuthMode: "apikey", RequireAPIKey: true}, "", healthFail},
+		{Config{AuthMode: "apikey"}, "", healthWarn},
+		{Config{AuthMode: "any", BearerToken: "t", RequireAPIKey: true}, "", healthWarn},
+		{Config{AuthMode: "none"}, "", healthOK},
+	}
+	for _, tt := range tests {
+		p := startKeyFetch(func() (string, error) { return tt.key, nil })
+		<-p.ready
+		if check := apiKeyCheck(&tt.cfg, p)(context.Background()); check.Status != tt.status {
+			t.Errorf("%+v with key %q: expected %s, got %+v", tt.cfg, tt.key, tt.status, check)
+		}
+	}
+
+	release := make(chan struct{})
+	defer close(release)
+	blocked := startKeyFetch(func() (string, error) { <-release; return "", nil })
+	if check := apiKeyCheck(&Config{AuthMode: "apikey"}, blocked)(context.Background()); check.Status != healthWarn || check.Detail != "fetch in progress" {
+		t.Errorf("Expected a pending fetch to warn, got %+v", check)
+	}
+}
+
+func TestAPIKeyReady(t *testing.T) {
+	tests := []struct {
+		cfg   Config
+		key   string
+		ready bool
+	}{
+		{Config{AuthMode: "apikey", RequireAPIKey: true}, "k", true},
+		{Config{AuthMode: "apikey", RequireAPIKey: true}, "", false},
+		{Config{AuthMode: "apikey"}, "", true},
+		{Config{AuthMode: "any", BearerToken: "t", RequireAPIKey: true}, "", true},
+		{Config{AuthMode: "bearer", RequireAPIKey: true}, "", true},
+	}
+	for _, tt := range tests {
+		p := startKeyFetch(func() (string, error) { return tt.key, nil })
+		<-p.ready
+		if err := apiKeyReady(&tt.cfg, p); (err == nil) != tt.ready {
+			t.Errorf("%+v with key %q: expected ready=%v, got %v", tt.cfg, tt.key, tt.ready, err)
+		}
+	}
+
+	release := make(chan struct{})
+	defer close(release)
+	blocked := startKeyFetch(func() (string, error) { <-release; return "k", nil })
+	if err := apiKeyReady(&Config{AuthMode: "apikey"}, blocked); err == nil {
+		t.Error("Expected a pending fetch to be unready")
+	}
+	if err := apiKeyReady(&Config{AuthMode: "none"}, blocked); err != nil {
+		t.Errorf("Expected AUTH_MODE=none not to wait on the fetch, got %v", err)
+	}
+}
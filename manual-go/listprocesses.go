@@ -0,0 +1,84 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// defaultListProcessLimit is the number of processes list_processes shows
+// when the call gives no limit.
+const defaultListProcessLimit = 20
+
+// listProcessSortKeys are the orders list_processes can list processes in;
+// the first is the default.
+var listProcessSortKeys = []string{"mem", "cpu", "pid"}
+
+// listProcessesInput is the list_processes tool input.
+type listProcessesInput struct {
+	SortBy string `json:"sort_by,omitempty" jsonschema:"order to list processes in: mem (default, largest resident memory first), cpu or pid"`
+	Limit  int    `json:"limit,omitempty" jsonschema:"number of processes to return (default 20, at most PROCESS_PAGE_MAX)"`
+}
+
+// validate rejects unknown sort keys and a negative limit. The limit cap is
+// a server setting and is checked by the handler.
+func (in listProcessesInput) validate() error {
+	if in.SortBy != "" && !slices.Contains(listProcessSortKeys, in.SortBy) {
+		return fmt.Errorf("%w: unsupported sort_by %q: must be one of %s", errInvalidInput, in.SortBy, strings.Join(listProcessSortKeys, ", "))
+	}
+	if in.Limit < 0 {
+		return fmt.Errorf("%w: limit %d must not be negative", errInvalidInput, in.Limit)
+	}
+	return nil
+}
+
+// listProcessDetails gathers the processes for list_processes on the same
+// worker pool as top_processes, replaceable in tests.
+var listProcessDetails = func(ctx context.Context, workers int) ([]processDetail, int, error) {
+	return inspectProcesses(ctx, workers, inspectProcessDetail)
+}
+
+// formatProcessRows renders procs, already sorted by key and cut to the
+// limit, out of the listed processes.
+func formatProcessRows(procs []processDetail, key string, listed int) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Sorted by %s; showing %d of %d processes\n\n", key, len(procs), listed))
+	sb.WriteString(fmt.Sprintf("%-10s %-20s %12s %8s\n", "PID", "Name", "Memory", "CPU %"))
+	sb.WriteString("---------------------------------------------------------\n")
+	for _, p := range procs {
+		sb.WriteString(fmt.Sprintf("%-10d %-20s %12s %8.1f\n", p.PID, p.Name, formatBytes(p.RSS, unitsIEC), p.CPU))
+	}
+	return sb.String()
+}
+
+// listProcesses is the list_processes report: the first limit processes in
+// the order of sortBy, one of listProcessSortKeys, gathered by at most
+// workers goroutines. Empty and zero inputs take the defaults; callers
+// validate them first. If ctx expires before every process is inspected,
+// the report lists those that were and says so.
+func listProcesses(ctx context.Context, workers int, sortBy string, limit int) string {
+	key := cmp.Or(sortBy, listProcessSortKeys[0])
+	limit = cmp.Or(limit, defaultListProcessLimit)
+
+	var sb strings.Builder
+	sb.WriteString("Process List Report\n")
+	sb.WriteString("===================\n\n")
+
+	procs, total, err := listProcessDetails(ctx, workers)
+	if err != nil && procs == nil {
+		sb.WriteString(fmt.Sprintf("Error retrieving processes: %v\n", err))
+		return sb.String()
+	}
+	order := key
+	if key == "mem" {
+		order = "memory"
+	}
+	sortProcesses(procs, order)
+	sb.WriteString(formatProcessRows(procs[:min(limit, len(procs))], key, len(procs)))
+	if err != nil {
+		sb.WriteString(fmt.Sprintf("\nNote: collection stopped early (%v); inspected %d of %d processes\n", err, len(procs), total))
+	}
+	return sb.String()
+}
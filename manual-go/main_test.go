@@ -1,19 +1,25 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestCollectDiskUsage(t *testing.T) {
-	output := collectDiskUsage()
+	output := collectDiskUsage(diskReportOptions{})
 	if !strings.Contains(output, "Disk Usage Report") {
 		t.Errorf("Expected output to contain 'Disk Usage Report', got: %s", output)
 	}
 }
 
 func TestCollectSystemInfo(t *testing.T) {
-	output := collectSystemInfo("test status")
+	output := collectSystemInfo(context.Background(), "test status", false, systemInfoOptions{})
 	if !strings.Contains(output, "System Information Report") {
 		t.Errorf("Expected output to contain 'System Information Report', got: %s", output)
 	}
@@ -21,3 +27,133 @@ func TestCollectSystemInfo(t *testing.T) {
 		t.Errorf("Expected output to contain 'test status', got: %s", output)
 	}
 }
+
+func TestAuthBypassLimitedToHealthPaths(t *testing.T) {
+	cfg := &Config{AuthMode: "apikey", APIKey: "good-key", KeyWaitTimeout: time.Second}
+	pending := startKeyFetch(func() (string, error) { return cfg.APIKey, nil })
+	handler, _ := newHandler(cfg, pending, nil)
+
+	tests := []struct {
+		method, path, key string
+		want              int
+	}{
+		{http.MethodGet, "/", "", http.StatusOK},
+		{http.MethodGet, "/healthz", "", http.StatusOK},
+		{http.MethodGet, "/healthz/probe", "", http.StatusOK},
+		{http.MethodGet, "/livez", "", http.StatusOK},
+		{http.MethodGet, "/readyz", "", http.StatusOK},
+		{http.MethodPost, "/mcp", "", http.StatusUnauthorized},
+		{http.MethodPost, "/mcp", "bad-key", http.StatusUnauthorized},
+		{http.MethodGet, "/foo", "bad-key", http.StatusUnauthorized},
+		{http.MethodGet, "/healthz/", "", http.StatusUnauthorized},
+		{http.MethodGet, "/report/disk", "bad-key", http.StatusUnauthorized},
+		{http.MethodGet, "/report/disk", "good-key", http.StatusOK},
+		{http.MethodGet, "/stats", "bad-key", http.StatusUnauthorized},
+		{http.MethodGet, "/stats", "good-key", http.StatusOK},
+		{http.MethodGet, "/debug/load", "bad-key", http.StatusUnauthorized},
+		{http.MethodGet, "/debug/load", "good-key", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		if tt.key != "" {
+			req.Header.Set("x-goog-api-key", tt.key)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("%s %s (key %q): expected %d, got %d", tt.method, tt.path, tt.key, tt.want, rec.Code)
+		}
+	}
+}
+
+func TestAPIKeyAuthorization(t *testing.T) {
+	cfg := &Config{AuthMode: "apikey", APIKey: "good-key", KeyWaitTimeout: time.Second}
+	handler, _ := newHandler(cfg, startKeyFetch(func() (string, error) { return cfg.APIKey, nil }), nil)
+	for key, want := range map[string]int{
+		"good-key":  http.StatusOK,
+		"good-kez":  http.StatusUnauthorized,
+		"good":      http.StatusUnauthorized,
+		"good-key2": http.StatusUnauthorized,
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/stats", nil)
+		req.Header.Set("x-goog-api-key", key)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Errorf("key %q: expected %d, got %d", key, want, rec.Code)
+		}
+	}
+}
+
+func TestRequireAPIKeyWithoutKey(t *testing.T) {
+	for _, require := range []bool{true, false} {
+		cfg := &Config{AuthMode: "apikey", RequireAPIKey: require, KeyWaitTimeout: time.Second}
+		handler, _ := newHandler(cfg, startKeyFetch(func() (string, error) { return "", nil }), nil)
+		want := http.StatusServiceUnavailable
+		if !require {
+			want = http.StatusOK
+		}
+		for _, path := range []string{"/stats", "/readyz"} {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+			if rec.Code != want {
+				t.Errorf("REQUIRE_API_KEY=%v without a key: expected %d from %s, got %d", require, want, path, rec.Code)
+			}
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/livez", nil))
+		if rec.Code != http.StatusOK {
+			t.Errorf("Expected /livez to pass without a key, got %d", rec.Code)
+		}
+	}
+}
+
+func TestRejectedKeyRefusesRequests(t *testing.T) {
+	// Even REQUIRE_API_KEY=false must not serve without a key once the
+	// fetched one was rejected; the server is shutting down instead.
+	cfg := &Config{AuthMode: "apikey", KeyWaitTimeout: time.Second}
+	pending := startKeyFetch(func() (string, error) { return "", errKeyPinMismatch })
+	<-pending.ready
+	if err := pending.rejected(); !errors.Is(err, errKeyPinMismatch) {
+		t.Fatalf("Expected the pin mismatch to be kept, got %v", err)
+	}
+	handler, _ := newHandler(cfg, pending, nil)
+	for _, path := range []string{"/stats", "/readyz"} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Errorf("Expected %s to return 503 with a rejected key, got %d", path, rec.Code)
+		}
+	}
+}
+
+func TestOfflineKeyStatus(t *testing.T) {
+	status, found := offlineKeyStatus("some-key")
+	if !found || !strings.Contains(status, "[FOUND]") || !strings.Contains(status, "[SKIPPED]") {
+		t.Errorf("Expected a found key with the cloud match skipped, got %v: %s", found, status)
+	}
+	if status, found := offlineKeyStatus(""); found || !strings.Contains(status, "[NOT FOUND]") {
+		t.Errorf("Expected a missing key, got %v: %s", found, status)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	for _, command := range []string{"info", "disk", "processes", "check", "config", "doctor"} {
+		if !strings.Contains(usageText, "\n  "+command+" ") {
+			t.Errorf("Expected usage to list %q", command)
+		}
+	}
+	if !isHelp("--help") || !isHelp("-h") || !isHelp("help") || isHelp("info") {
+		t.Error("Expected help, -h and --help to be recognized as help")
+	}
+}
+
+func TestSystemSectionNames(t *testing.T) {
+	var names []string
+	for _, s := range systemSections(systemInfoOptions{}) {
+		names = append(names, s.name)
+	}
+	if !slices.Equal(names, systemSectionNames) {
+		t.Errorf("systemSectionNames %q does not match systemSections %q", systemSectionNames, names)
+	}
+}
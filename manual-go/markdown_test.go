@@ -0,0 +1,27 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMarkdownReport(t *testing.T) {
+	text := "System Information Report\n" +
+		"=========================\n\n" +
+		"CPU Information\n" +
+		"---------------\n" +
+		"Number of Cores:  4\n" +
+		"Note:             a|b\n" +
+		"(2 idle interfaces hidden; set include_idle to list them)\n"
+	got := markdownReport(text)
+	for _, want := range []string{
+		"# System Information Report\n\n## CPU Information\n\n",
+		"| Field | Value |\n| --- | --- |\n| Number of Cores | 4 |\n",
+		`| Note | a\|b |` + "\n\n",
+		"(2 idle interfaces hidden; set include_idle to list them)\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected %q in Markdown report, got:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shirou/gopsutil/v3/mem"
+)
+
+// memoryBreakdown lists the categories behind "Used Memory" for the
+// memory_detail input: truly free memory, memory available without swapping,
+// and the buffers, page cache and shared memory the kernel can mostly
+// reclaim. gopsutil fills these from /proc/meminfo on Linux; categories a
+// platform leaves at zero are omitted rather than shown as 0 B.
+func memoryBreakdown(v *mem.VirtualMemoryStat) string {
+	var sb strings.Builder
+	for _, c := range []struct {
+		label string
+		value uint64
+	}{
+		{"Free Memory:", v.Free},
+		{"Available Memory:", v.Available},
+		{"Buffers:", v.Buffers},
+		{"Cached:", v.Cached},
+		{"Shared:", v.Shared},
+	} {
+		if c.value > 0 {
+			sb.WriteString(fmt.Sprintf("%-18s%s\n", c.label, formatBytes(c.value, unitsIEC)))
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/shirou/gopsutil/v3/mem"
+)
+
+func TestMemoryBreakdown(t *testing.T) {
+	out := memoryBreakdown(&mem.VirtualMemoryStat{Free: 1 << 30, Available: 3 << 30, Cached: 2 << 30, Buffers: 256 << 20})
+	for _, want := range []string{"Free Memory:      1.0 GiB", "Available Memory: 3.0 GiB", "Buffers:          256.0 MiB", "Cached:           2.0 GiB"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "Shared") {
+		t.Errorf("Expected unreported categories to be omitted:\n%s", out)
+	}
+	if out := memoryBreakdown(&mem.VirtualMemoryStat{}); out != "" {
+		t.Errorf("Expected nothing from an empty stat, got %q", out)
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"errors"
+	"log/slog"
+)
+
+// errOffline is returned in place of an outbound call while OFFLINE is set.
+var errOffline = errors.New("disabled by OFFLINE=true")
+
+// offlineDisabled lists what OFFLINE=true turns off, for the startup log.
+var offlineDisabled = []string{"gcloud project lookup", "API key fetch (gcloud and API Keys API)", "doctor credential and gcloud checks", "report webhook", "OTLP trace export", "deployment metadata lookup", "--remote"}
+
+// logOffline announces offline mode and the features it disables; it logs
+// nothing when offline is false.
+func logOffline(offline bool) {
+	if offline {
+		slog.Warn("OFFLINE=true: outbound network and cloud calls are disabled; only MCP_API_KEY is used for auth", "disabled", offlineDisabled)
+	}
+}
+
+// fetchProject returns the Google Cloud project the API key is fetched from:
+// GOOGLE_CLOUD_PROJECT, else the gcloud config. It is always empty with
+// OFFLINE set, so callers never shell out to gcloud or fetch a key.
+func (c *Config) fetchProject() string {
+	if c.Offline {
+		return ""
+	}
+	return getProjectID()
+}
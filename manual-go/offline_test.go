@@ -0,0 +1,29 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestOfflineSkipsCloudCalls(t *testing.T) {
+	old := gcloudProjectID
+	t.Cleanup(func() { gcloudProjectID = old })
+	gcloudProjectID = func() string {
+		t.Error("Expected no gcloud call in offline mode")
+		return "project"
+	}
+
+	cfg := &Config{Offline: true, AuthMode: "apikey", APIKey: "key", Port: "0"}
+	if p := cfg.fetchProject(); p != "" {
+		t.Errorf("Expected no project offline, got %q", p)
+	}
+	if _, err := cfg.fetchProjectKey(context.Background()); !errors.Is(err, errOffline) {
+		t.Errorf("Expected errOffline, got %v", err)
+	}
+	for _, c := range runDoctor(context.Background(), cfg) {
+		if c.Name == "Project resolution" || c.Name == "API key fetch" {
+			t.Errorf("Expected the cloud checks to be skipped offline, got %+v", c)
+		}
+	}
+}
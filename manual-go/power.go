@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// battery is the charge of one battery as the platform reports it.
+type battery struct {
+	Name    string
+	Percent int
+	State   string
+}
+
+// powerSection reports each battery's charge and charging state, for the
+// laptops these tools also run on locally. Hosts without a battery, which is
+// every server and container, get no section at all, and neither does a
+// platform whose battery info cannot be read.
+func powerSection() (string, error) {
+	batteries, err := readBatteries()
+	if err != nil || len(batteries) == 0 {
+		return "", nil
+	}
+	var sb strings.Builder
+	sb.WriteString("\nPower\n")
+	sb.WriteString("-----\n")
+	for _, b := range batteries {
+		sb.WriteString(fmt.Sprintf("%-18s: %d%% (%s)\n", b.Name, b.Percent, b.State))
+	}
+	return sb.String(), nil
+}
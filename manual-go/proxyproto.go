@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// proxyHeaderTimeout bounds how long a new connection may take to send
+	// its PROXY protocol header.
+	proxyHeaderTimeout = 5 * time.Second
+	// proxyV1MaxLen is the longest v1 header the specification allows,
+	// including the trailing CRLF.
+	proxyV1MaxLen = 107
+)
+
+// proxyV2Signature opens every PROXY protocol v2 header.
+var proxyV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+// proxyListener accepts connections from a load balancer that prepends the
+// PROXY protocol header (ENABLE_PROXY_PROTOCOL). Connections report the client
+// address from the header as their RemoteAddr, so it reaches r.RemoteAddr and
+// the request log.
+type proxyListener struct {
+	net.Listener
+}
+
+// Accept wraps the next connection. The header is read lazily by the
+// connection's own goroutine so that a slow client cannot stall Accept.
+func (l proxyListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &proxyConn{Conn: c, r: bufio.NewReader(c)}, nil
+}
+
+// proxyConn is a connection whose first bytes are a PROXY protocol header.
+// Connections without a valid header are closed.
+type proxyConn struct {
+	net.Conn
+	r      *bufio.Reader
+	once   sync.Once
+	remote net.Addr
+	err    error
+}
+
+// readHeader consumes the header on first use.
+func (c *proxyConn) readHeader() {
+	c.once.Do(func() {
+		c.Conn.SetReadDeadline(time.Now().Add(proxyHeaderTimeout))
+		c.remote, c.err = readProxyHeader(c.r)
+		c.Conn.SetReadDeadline(time.Time{})
+		if c.err != nil {
+			slog.Warn("Rejected connection without a valid PROXY protocol header", "peer", c.Conn.RemoteAddr().String(), "error", c.err)
+			c.Conn.Close()
+		}
+	})
+}
+
+func (c *proxyConn) Read(p []byte) (int, error) {
+	c.readHeader()
+	if c.err != nil {
+		return 0, c.err
+	}
+	return c.r.Read(p)
+}
+
+// RemoteAddr is the client address from the header, or the peer's own
+// address for LOCAL headers, such as load balancer health checks, and for
+// address families the header does not carry.
+func (c *proxyConn) RemoteAddr() net.Addr {
+	c.readHeader()
+	if c.remote != nil {
+		return c.remote
+	}
+	return c.Conn.RemoteAddr()
+}
+
+// readProxyHeader reads a v1 or v2 PROXY protocol header. A nil address with
+// a nil error means the header is valid but names no client.
+func readProxyHeader(r *bufio.Reader) (net.Addr, error) {
+	if sig, err := r.Peek(len(proxyV2Signature)); err == nil && bytes.Equal(sig, proxyV2Signature) {
+		return readProxyV2(r)
+	}
+	if prefix, err := r.Peek(6); err != nil || string(prefix) != "PROXY " {
+		return nil, errors.New("missing PROXY protocol header")
+	}
+	return readProxyV1(r)
+}
+
+// readProxyV1 parses a text header such as
+//
+//	PROXY TCP4 203.0.113.7 10.0.0.5 51234 8080\r\n
+func readProxyV1(r *bufio.Reader) (net.Addr, error) {
+	line, err := r.ReadSlice('\n')
+	if err != nil || len(line) > proxyV1MaxLen || !bytes.HasSuffix(line, []byte("\r\n")) {
+		return nil, errors.New("malformed PROXY v1 header")
+	}
+	fields := strings.Fields(string(line[:len(line)-2]))
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return nil, nil
+	}
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return nil, fmt.Errorf("malformed PROXY v1 header %q", line)
+	}
+	ip := net.ParseIP(fields[2])
+	if ip == nil || (ip.To4() != nil) != (fields[1] == "TCP4") {
+		return nil, fmt.Errorf("invalid PROXY v1 source address %q", fields[2])
+	}
+	port, err := strconv.ParseUint(fields[4], 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PROXY v1 source port %q", fields[4])
+	}
+	return &net.TCPAddr{IP: ip, Port: int(port)}, nil
+}
+
+// readProxyV2 parses a binary header. TLVs after the addresses are skipped.
+func readProxyV2(r *bufio.Reader) (net.Addr, error) {
+	var hdr [16]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return nil, fmt.Errorf("short PROXY v2 header: %w", err)
+	}
+	if hdr[12]>>4 != 2 {
+		return nil, fmt.Errorf("unsupported PROXY protocol version %d", hdr[12]>>4)
+	}
+	body := make([]byte, binary.BigEndian.Uint16(hdr[14:16]))
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, fmt.Errorf("short PROXY v2 header: %w", err)
+	}
+	switch hdr[12] & 0x0f {
+	case 0x0: // LOCAL
+		return nil, nil
+	case 0x1: // PROXY
+	default:
+		return nil, fmt.Errorf("unsupported PROXY v2 command %d", hdr[12]&0x0f)
+	}
+	switch hdr[13] {
+	case 0x11: // TCP over IPv4
+		if len(body) < 12 {
+			return nil, errors.New("short PROXY v2 IPv4 address block")
+		}
+		return &net.TCPAddr{IP: net.IP(body[0:4]), Port: int(binary.BigEndian.Uint16(body[8:10]))}, nil
+	case 0x21: // TCP over IPv6
+		if len(body) < 36 {
+			return nil, errors.New("short PROXY v2 IPv6 address block")
+		}
+		return &net.TCPAddr{IP: net.IP(body[0:16]), Port: int(binary.BigEndian.Uint16(body[32:34]))}, nil
+	default:
+		return nil, nil
+	}
+}
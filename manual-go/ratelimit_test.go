@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestParseToolRateLimits(t *testing.T) {
+	limits, err := parseToolRateLimits("top_processes=6/m, disk_latency=1/10s")
+	if err != nil {
+		t.Fatalf("parseToolRateLimits failed: %v", err)
+	}
+	if got := limits.String(); got != "disk_latency=1/10s,top_processes=6/1m0s" {
+		t.Errorf("Unexpected limits: %s", got)
+	}
+	if limits, err := parseToolRateLimits(""); err != nil || limits != nil {
+		t.Errorf("Expected no limits for an empty value, got %v, %v", limits, err)
+	}
+	for _, bad := range []string{"ping=1/s", "top_processes", "top_processes=0/m", "top_processes=5/fortnight", "top_processes=5/-1s"} {
+		if _, err := parseToolRateLimits(bad); err == nil {
+			t.Errorf("Expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestToolRateLimiter(t *testing.T) {
+	now := time.Unix(0, 0)
+	tc := toolsConfig{"top_processes": {Name: "procs"}}
+	l := newToolRateLimiter(toolRateLimits{"top_processes": {Calls: 2, Per: time.Minute}}, tc)
+	l.now = func() time.Time { return now }
+
+	for i := range 2 {
+		if _, ok := l.allow("a", "procs"); !ok {
+			t.Fatalf("Call %d should be allowed", i+1)
+		}
+	}
+	wait, ok := l.allow("a", "procs")
+	if ok || wait != 30*time.Second {
+		t.Errorf("Expected third call to wait 30s, got %v, %v", wait, ok)
+	}
+	if _, ok := l.allow("b", "procs"); !ok {
+		t.Error("Another client should have its own budget")
+	}
+	if _, ok := l.allow("a", "disk_usage"); !ok {
+		t.Error("Tools without a limit should not be throttled")
+	}
+	now = now.Add(30 * time.Second)
+	if _, ok := l.allow("a", "procs"); !ok {
+		t.Error("Expected a token after the refill interval")
+	}
+
+	if newToolRateLimiter(nil, tc) != nil {
+		t.Error("Expected a nil limiter without limits")
+	}
+}
+
+func TestRateClient(t *testing.T) {
+	clientOf := func(req *http.Request) string {
+		var got string
+		h := withRateClient(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r.Header.Get(rateClientHeader)
+		}))
+		h.ServeHTTP(httptest.NewRecorder(), req)
+		return got
+	}
+	// Unverified headers must not pick the bucket, or rotating them would
+	// buy a fresh budget with every request.
+	for _, header := range []string{"x-api-key", "Authorization", rateClientHeader} {
+		for _, value := range []string{"one", "two"} {
+			req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+			req.Header.Set(header, value)
+			if got := clientOf(req); got != "addr 192.0.2.1" {
+				t.Errorf("%s %q: expected the remote address, got %q", header, value, got)
+			}
+		}
+	}
+	req := withVerifiedClient(httptest.NewRequest(http.MethodPost, "/mcp", nil), "auth apikey")
+	if got := clientOf(req); got != "auth apikey" {
+		t.Errorf("Expected the verified identity, got %q", got)
+	}
+}
+
+func TestToolRateLimiterCap(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := newToolRateLimiter(toolRateLimits{"top_processes": {Calls: 1, Per: time.Hour}}, nil)
+	l.now = func() time.Time { return now }
+	for i := range maxRateBuckets + 10 {
+		now = now.Add(time.Millisecond)
+		l.allow(strconv.Itoa(i), "top_processes")
+	}
+	if len(l.buckets) > maxRateBuckets {
+		t.Errorf("Expected at most %d buckets, got %d", maxRateBuckets, len(l.buckets))
+	}
+	if _, ok := l.buckets["0\x00top_processes"]; ok {
+		t.Error("Expected the least recently used bucket to be evicted")
+	}
+	if _, ok := l.allow(strconv.Itoa(maxRateBuckets+9), "top_processes"); ok {
+		t.Error("Expected the newest client to keep its exhausted bucket")
+	}
+}
+
+func TestToolRateLimitAcrossSessions(t *testing.T) {
+	limits := toolRateLimits{"runtime_info": {Calls: 1, Per: time.Minute}}
+	handler, _ := newHandler(&Config{AuthMode: "none", ToolRateLimits: limits}, startKeyFetch(func() (string, error) { return "", nil }), nil)
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	ctx := context.Background()
+	client := mcp.NewClient(&mcp.Implementation{Name: "test"}, nil)
+	call := func() error {
+		session, err := client.Connect(ctx, &mcp.StreamableClientTransport{Endpoint: srv.URL + "/mcp"}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer session.Close()
+		_, err = session.CallTool(ctx, &mcp.CallToolParams{Name: "runtime_info"})
+		return err
+	}
+	if err := call(); err != nil {
+		t.Fatalf("Expected the first call to be allowed, got %v", err)
+	}
+	// A new session from the same client shares the exhausted budget.
+	if err := call(); err == nil || !strings.Contains(err.Error(), errRateLimited.Error()) {
+		t.Errorf("Expected a second session to be rate limited, got %v", err)
+	}
+}
+
+func TestRateLimitError(t *testing.T) {
+	var wireErr *jsonrpc.Error
+	if !errors.As(rateLimitError("top_processes", 1500*time.Millisecond), &wireErr) {
+		t.Fatal("Expected a JSON-RPC error")
+	}
+	if wireErr.Code != codeRateLimited {
+		t.Errorf("Unexpected code %d", wireErr.Code)
+	}
+	if string(wireErr.Data) != `{"retry_after_ms":1500,"tool":"top_processes"}` {
+		t.Errorf("Unexpected data %s", wireErr.Data)
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithRequestLogSampling(t *testing.T) {
+	var buf bytes.Buffer
+	orig := slog.Default()
+	defer slog.SetDefault(orig)
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/denied" {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte("OK"))
+	})
+
+	withRequestLog(h, 0, time.Hour).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/ok", nil))
+	if buf.Len() != 0 {
+		t.Errorf("Expected successful request to be sampled out, got: %s", buf.String())
+	}
+
+	withRequestLog(h, 0, time.Hour).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/denied", nil))
+	if !strings.Contains(buf.String(), `"status":401`) {
+		t.Errorf("Expected unauthorized request to always be logged, got: %s", buf.String())
+	}
+
+	buf.Reset()
+	withRequestLog(h, 1, time.Hour).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/ok", nil))
+	if !strings.Contains(buf.String(), `"path":"/ok"`) || !strings.Contains(buf.String(), `"status":200`) {
+		t.Errorf("Expected successful request to be logged at sample 1, got: %s", buf.String())
+	}
+}
+
+func TestWithRequestLogSlow(t *testing.T) {
+	var buf bytes.Buffer
+	orig := slog.Default()
+	defer slog.SetDefault(orig)
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+
+	const call = `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"full_report","arguments":{}}}`
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != call {
+			t.Errorf("Expected the handler to see the full body, got %q", body)
+		}
+		time.Sleep(20 * time.Millisecond)
+	})
+
+	withRequestLog(h, 0, time.Millisecond).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(call)))
+	out := buf.String()
+	for _, want := range []string{`"level":"WARN"`, `"msg":"Slow request"`, `"path":"/mcp"`, `"tool":"full_report"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %s in the slow request log, got: %s", want, out)
+		}
+	}
+
+	buf.Reset()
+	stream := httptest.NewRequest(http.MethodGet, "/mcp", nil)
+	stream.Header.Set("Accept", "text/event-stream")
+	withRequestLog(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { time.Sleep(5 * time.Millisecond) }), 0, time.Millisecond).ServeHTTP(httptest.NewRecorder(), stream)
+	if buf.Len() != 0 {
+		t.Errorf("Expected event streams not to be logged as slow, got: %s", buf.String())
+	}
+
+	withRequestLog(h, 0, time.Hour).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(call)))
+	if buf.Len() != 0 {
+		t.Errorf("Expected no log under the threshold, got: %s", buf.String())
+	}
+}
+
+func TestPeekToolName(t *testing.T) {
+	for body, want := range map[string]string{
+		`{"method":"tools/call","params":{"name":"dns_info"}}`: "dns_info",
+		`{"method":"tools/list"}`:                              "",
+		`[{"method":"tools/call"}]`:                            "",
+		`not json`:                                             "",
+	} {
+		if got := peekToolName(httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body))); got != want {
+			t.Errorf("peekToolName(%s) = %q, want %q", body, got, want)
+		}
+	}
+	if got := peekToolName(httptest.NewRequest(http.MethodGet, "/mcp", nil)); got != "" {
+		t.Errorf("Expected no tool for a GET, got %q", got)
+	}
+}
+
+func TestStatusRecorderFlushes(t *testing.T) {
+	w := httptest.NewRecorder()
+	var rec http.ResponseWriter = &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	f, ok := rec.(http.Flusher)
+	if !ok {
+		t.Fatal("Expected statusRecorder to implement http.Flusher")
+	}
+	f.Flush()
+	if !w.Flushed {
+		t.Error("Expected flush to reach the underlying writer")
+	}
+}
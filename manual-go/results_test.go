@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestTruncateResult(t *testing.T) {
+	if got := truncateResult("short", 100); got != "short" {
+		t.Errorf("Expected short text unchanged, got %q", got)
+	}
+	if got := truncateResult(strings.Repeat("x", 500), 0); len(got) != 500 {
+		t.Errorf("Expected no cap at 0, got %d bytes", len(got))
+	}
+	got := truncateResult(strings.Repeat("é", 100), 51)
+	if len(got) > 51 || !strings.HasSuffix(got, resultTruncatedMarker) || !utf8.ValidString(got) {
+		t.Errorf("Expected valid UTF-8 capped at 51 bytes with a marker, got %d bytes: %q", len(got), got)
+	}
+}
+
+func TestResultSizeMiddleware(t *testing.T) {
+	next := func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: strings.Repeat("x", 1000)}}}, nil
+	}
+	res, err := resultSizeMiddleware(100)(next)(context.Background(), "tools/call", &mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := res.(*mcp.CallToolResult).Content[0].(*mcp.TextContent).Text
+	if len(text) > 100 || !strings.HasSuffix(text, "[truncated]\n") {
+		t.Errorf("Expected result capped at 100 bytes, got %d: %q", len(text), text)
+	}
+}
+
+func TestResultSizeMiddlewareJSON(t *testing.T) {
+	text := `{"data":"` + strings.Repeat("x", 1000) + `"}`
+	next := func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: text}}}, nil
+	}
+	_, err := resultSizeMiddleware(100)(next)(context.Background(), "tools/call", &mcp.CallToolRequest{})
+	var wireErr *jsonrpc.Error
+	if !errors.As(err, &wireErr) || wireErr.Code != codeResultTooLarge {
+		t.Fatalf("Expected a result-too-large error, got %v", err)
+	}
+	if want := fmt.Sprintf(`{"bytes":%d,"limit":100}`, len(text)); string(wireErr.Data) != want {
+		t.Errorf("Unexpected data %s, want %s", wireErr.Data, want)
+	}
+
+	res, err := resultSizeMiddleware(0)(next)(context.Background(), "tools/call", &mcp.CallToolRequest{})
+	if err != nil || res.(*mcp.CallToolResult).Content[0].(*mcp.TextContent).Text != text {
+		t.Errorf("Expected JSON unchanged without a cap, got %v", err)
+	}
+}
+
+func TestJSONToolOverResultCap(t *testing.T) {
+	handler, _ := newHandler(&Config{AuthMode: "none", MaxResultBytes: 64}, startKeyFetch(func() (string, error) { return "", nil }), nil)
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	ctx := context.Background()
+	client := mcp.NewClient(&mcp.Implementation{Name: "test"}, nil)
+	session, err := client.Connect(ctx, &mcp.StreamableClientTransport{Endpoint: srv.URL + "/mcp"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+	_, err = session.CallTool(ctx, &mcp.CallToolParams{Name: "system_info_json"})
+	if err == nil || !strings.Contains(err.Error(), errResultTooLarge.Error()) {
+		t.Errorf("Expected system_info_json over the cap to fail as too large, got %v", err)
+	}
+}
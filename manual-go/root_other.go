@@ -0,0 +1,8 @@
+//go:build !unix
+
+package main
+
+// runningAsRoot is always false where there is no uid 0, such as Windows.
+func runningAsRoot() bool {
+	return false
+}
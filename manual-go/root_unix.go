@@ -0,0 +1,9 @@
+//go:build unix
+
+package main
+
+import "os"
+
+func runningAsRoot() bool {
+	return os.Geteuid() == 0
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"runtime"
+	"strings"
+)
+
+// collectRuntimeInfo reports the Go runtime serving this process, as opposed
+// to the host it runs on. A GOMAXPROCS above the cgroup CPU quota is flagged,
+// since on CPU-limited instances such as Cloud Run it leads to throttling.
+func collectRuntimeInfo() string {
+	var sb strings.Builder
+	sb.WriteString("Runtime Info\n")
+	sb.WriteString("============\n\n")
+	sb.WriteString(fmt.Sprintf("Go Version:       %s\n", runtime.Version()))
+	sb.WriteString(fmt.Sprintf("OS/Arch:          %s/%s\n", runtime.GOOS, runtime.GOARCH))
+	sb.WriteString(fmt.Sprintf("NumCPU:           %d\n", runtime.NumCPU()))
+	sb.WriteString(fmt.Sprintf("GOMAXPROCS:       %d\n", runtime.GOMAXPROCS(0)))
+	sb.WriteString(fmt.Sprintf("Goroutines:       %d\n", runtime.NumGoroutine()))
+	if vcpu, ok := cgroupCPULimit(); ok {
+		sb.WriteString(fmt.Sprintf("CPU Quota:        %.2f vCPU (cgroup quota)\n", vcpu))
+		if note := gomaxprocsNote(runtime.GOMAXPROCS(0), vcpu); note != "" {
+			sb.WriteString("\n" + note + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// quotaProcs is the GOMAXPROCS that fits a vCPU quota: the quota rounded up
+// to whole CPUs, at least 1.
+func quotaProcs(vcpu float64) int {
+	return max(int(math.Ceil(vcpu)), 1)
+}
+
+// gomaxprocsNote warns when GOMAXPROCS exceeds the whole vCPUs of the quota.
+func gomaxprocsNote(maxprocs int, vcpu float64) string {
+	limit := quotaProcs(vcpu)
+	if maxprocs <= limit {
+		return ""
+	}
+	return fmt.Sprintf("Warning: GOMAXPROCS (%d) exceeds the CPU quota (%.2f vCPU); set GOMAXPROCS=%d to avoid throttling.", maxprocs, vcpu, limit)
+}
+
+// applyAutoMaxprocs lowers GOMAXPROCS to fit the cgroup CPU quota when
+// AUTO_MAXPROCS is on, so fractional-CPU Cloud Run instances do not run more
+// scheduler threads than they have CPU for. An explicit GOMAXPROCS in the
+// environment always wins, and GOMAXPROCS is never raised. Recent Go runtimes
+// apply a similar limit themselves; this makes the choice explicit and logged.
+func applyAutoMaxprocs(enabled bool) {
+	current := runtime.GOMAXPROCS(0)
+	switch vcpu, ok := cgroupCPULimit(); {
+	case !enabled:
+		slog.Info("AUTO_MAXPROCS disabled", "gomaxprocs", current)
+	case os.Getenv("GOMAXPROCS") != "":
+		slog.Info("GOMAXPROCS set explicitly", "gomaxprocs", current)
+	case !ok:
+		slog.Info("No CPU quota found, keeping GOMAXPROCS", "gomaxprocs", current)
+	case quotaProcs(vcpu) < current:
+		runtime.GOMAXPROCS(quotaProcs(vcpu))
+		slog.Info("GOMAXPROCS lowered to CPU quota", "gomaxprocs", quotaProcs(vcpu), "previous", current, "cpu_quota", vcpu)
+	default:
+		slog.Info("GOMAXPROCS fits CPU quota", "gomaxprocs", current, "cpu_quota", vcpu)
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestCollectRuntimeInfo(t *testing.T) {
+	out := collectRuntimeInfo()
+	for _, want := range []string{"Runtime Info", runtime.Version(), runtime.GOOS + "/" + runtime.GOARCH, "GOMAXPROCS:", "Goroutines:"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in runtime info, got: %s", want, out)
+		}
+	}
+}
+
+func TestGomaxprocsNote(t *testing.T) {
+	if note := gomaxprocsNote(8, 1); !strings.Contains(note, "GOMAXPROCS=1") {
+		t.Errorf("Expected a warning suggesting GOMAXPROCS=1, got %q", note)
+	}
+	if note := gomaxprocsNote(2, 1.5); note != "" {
+		t.Errorf("Expected no warning within the rounded-up quota, got %q", note)
+	}
+}
+
+func TestApplyAutoMaxprocs(t *testing.T) {
+	orig := runtime.GOMAXPROCS(0)
+	defer runtime.GOMAXPROCS(orig)
+	t.Setenv("GOMAXPROCS", "")
+
+	dir := t.TempDir()
+	origRoot := cgroupRoot
+	defer func() { cgroupRoot = origRoot }()
+	cgroupRoot = dir
+	if err := os.WriteFile(filepath.Join(dir, "cpu.max"), []byte("50000 100000\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	runtime.GOMAXPROCS(4)
+	applyAutoMaxprocs(false)
+	if got := runtime.GOMAXPROCS(0); got != 4 {
+		t.Errorf("Expected GOMAXPROCS unchanged when disabled, got %d", got)
+	}
+	applyAutoMaxprocs(true)
+	if got := runtime.GOMAXPROCS(0); got != 1 {
+		t.Errorf("Expected GOMAXPROCS lowered to 1 for a 0.5 vCPU quota, got %d", got)
+	}
+}
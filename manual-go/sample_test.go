@@ -0,0 +1,31 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCheckSampleInterval(t *testing.T) {
+	tests := []struct {
+		ms int
+		ok bool
+	}{
+		{-1, false},
+		{0, true},
+		{1, true},
+		{4999, true},
+		{5000, true},
+		{5001, false},
+		{1 << 40, false},
+	}
+	for _, tt := range tests {
+		err := checkSampleInterval("interval_ms", tt.ms, 5*time.Second)
+		if tt.ok && err != nil {
+			t.Errorf("%d: unexpected error %v", tt.ms, err)
+		}
+		if !tt.ok && !(errors.Is(err, errInvalidInput)) {
+			t.Errorf("%d: expected an invalid input error, got %v", tt.ms, err)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/shirou/gopsutil/v3/host"
+)
+
+func TestCollectSessions(t *testing.T) {
+	orig := loginSessions
+	defer func() { loginSessions = orig }()
+
+	loginSessions = func(context.Context) ([]host.UserStat, error) {
+		return []host.UserStat{
+			{User: "alice", Terminal: "pts/0", Host: "203.0.113.7", Started: 1767225600},
+			{User: "root", Terminal: "tty1", Started: 1767229200},
+		}, nil
+	}
+	out := collectSessions(context.Background())
+	for _, want := range []string{"alice", "pts/0", "203.0.113.7", "2026-01-01T00:00:00Z", "tty1", "Total: 2 session(s)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in:\n%s", want, out)
+		}
+	}
+
+	for err, want := range map[error]string{
+		fs.ErrNotExist:                  "No login records",
+		errors.New("permission denied"): "Login sessions unavailable: permission denied",
+		nil:                             "No users logged in",
+	} {
+		loginSessions = func(context.Context) ([]host.UserStat, error) { return nil, err }
+		if out := collectSessions(context.Background()); !strings.Contains(out, want) {
+			t.Errorf("Expected %q for %v, got:\n%s", want, err, out)
+		}
+	}
+}
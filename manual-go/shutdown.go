@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultShutdownGrace bounds how long a graceful shutdown waits for
+// in-flight requests when SHUTDOWN_GRACE_SECONDS is unset. It matches the
+// window Cloud Run allows after SIGTERM.
+const defaultShutdownGrace = 10 * time.Second
+
+// shuttingDown is set once a graceful shutdown starts, so the health check
+// can report 503 and the load balancer stops routing to the instance while
+// in-flight requests drain.
+var shuttingDown atomic.Bool
+
+// drainTimer waits out the drain delay; tests replace it.
+var drainTimer = time.After
+
+// shutdownAfter gracefully shuts the servers down once d has elapsed. The
+// returned channel is closed when in-flight requests have drained or the
+// grace period ran out, so the caller can wait for it after Serve returns
+// http.ErrServerClosed.
+func shutdownAfter(d, drain, grace time.Duration, reason string, servers ...*http.Server) <-chan struct{} {
+	done := make(chan struct{})
+	time.AfterFunc(d, func() {
+		defer close(done)
+		shutdownServers(reason, drain, grace, servers...)
+	})
+	return done
+}
+
+// shutdownOnSignal gracefully shuts the servers down once ctx, normally from
+// signal.NotifyContext, is done. The returned channel is closed as in
+// shutdownAfter.
+func shutdownOnSignal(ctx context.Context, drain, grace time.Duration, servers ...*http.Server) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		<-ctx.Done()
+		defer close(done)
+		shutdownServers(context.Cause(ctx).Error(), drain, grace, servers...)
+	}()
+	return done
+}
+
+// shutdownServers marks the instance as shutting down and, after the drain
+// delay, stops every server accepting connections and waits up to a shared
+// grace period for active requests before closing the rest. During the drain
+// delay the servers keep serving, so load balancers polling the health
+// check see the 503 and stop routing before the listener goes away. The log
+// counts the public listener's connections, tracked by serverConns, that
+// drained and that had to be closed.
+func shutdownServers(reason string, drain, grace time.Duration, servers ...*http.Server) {
+	shuttingDown.Store(true)
+	if drain > 0 {
+		slog.Info("Draining before shutdown", "reason", reason, "drain_delay", drain)
+		<-drainTimer(drain)
+	}
+	open := serverConns.active.Load()
+	slog.Info("Shutting down gracefully", "reason", reason, "grace_period", grace, "open_connections", open)
+	ctx, cancel := context.WithTimeout(context.Background(), grace)
+	defer cancel()
+	timedOut := make([]bool, len(servers))
+	var wg sync.WaitGroup
+	for i, srv := range servers {
+		wg.Go(func() {
+			if err := srv.Shutdown(ctx); err != nil {
+				slog.Warn("Graceful shutdown timed out; closing remaining connections", "address", srv.Addr, "error", err)
+				timedOut[i] = true
+			}
+		})
+	}
+	wg.Wait()
+	closed := serverConns.active.Load()
+	for i, srv := range servers {
+		if timedOut[i] {
+			srv.Close()
+		}
+	}
+	slog.Info("Shutdown complete", "drained_connections", max(open-closed, 0), "closed_connections", closed)
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// smartctlTimeout bounds each smartctl run, since a sleeping or failing
+// disk can take a long time to answer.
+const smartctlTimeout = 5 * time.Second
+
+// runSmartctl runs smartctl with args, replaceable in tests. A missing
+// binary returns an error wrapping exec.ErrNotFound.
+var runSmartctl = func(ctx context.Context, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, smartctlTimeout)
+	defer cancel()
+	return exec.CommandContext(ctx, "smartctl", args...).Output()
+}
+
+// smartctl exit status bits meaning the device could not be queried at all:
+// a bad command line or a device that failed to open (usually permissions).
+// The higher bits report disk problems and still come with usable output.
+const smartctlUnusable = 1<<0 | 1<<1
+
+// smartDevice is one device from smartctl --scan, with the -d type needed
+// to query it again.
+type smartDevice struct {
+	Name string
+	Type string
+}
+
+// parseSmartScan reads smartctl --scan output, e.g.
+//
+//	/dev/sda -d sat # /dev/sda [SAT], ATA device
+func parseSmartScan(out []byte) []smartDevice {
+	var devices []smartDevice
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		line, _, _ := strings.Cut(sc.Text(), "#")
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		d := smartDevice{Name: fields[0]}
+		if len(fields) >= 3 && fields[1] == "-d" {
+			d.Type = fields[2]
+		}
+		devices = append(devices, d)
+	}
+	return devices
+}
+
+// smartHealth is the health of one device. Reallocated and Temperature are
+// "" when the device does not report them.
+type smartHealth struct {
+	Device      smartDevice
+	Status      string
+	Reallocated string
+	Temperature string
+}
+
+// parseSmartHealth reads smartctl -H -A output. ATA and NVMe drives report
+// "SMART overall-health self-assessment test result: PASSED", SCSI drives
+// "SMART Health Status: OK". The reallocated sector count is ATA attribute 5
+// (the grown defect list on SCSI) and the temperature ATA attribute 194, or
+// the NVMe and SCSI temperature lines. ok is false when there is no health
+// line, e.g. for a device without SMART support.
+func parseSmartHealth(out []byte) (h smartHealth, ok bool) {
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if label, value, found := strings.Cut(line, ":"); found {
+			value = strings.TrimSpace(value)
+			switch label {
+			case "SMART overall-health self-assessment test result", "SMART Health Status":
+				h.Status = value
+			case "Elements in grown defect list":
+				h.Reallocated = value
+			case "Temperature", "Current Drive Temperature":
+				if f := strings.Fields(value); len(f) > 0 {
+					h.Temperature = f[0] + " C"
+				}
+			}
+			continue
+		}
+		// ATA attribute rows: ID NAME FLAG VALUE WORST THRESH TYPE UPDATED
+		// WHEN_FAILED RAW_VALUE...
+		f := strings.Fields(line)
+		if len(f) < 10 {
+			continue
+		}
+		switch f[0] {
+		case "5":
+			h.Reallocated = f[9]
+		case "194":
+			h.Temperature = f[9] + " C"
+		}
+	}
+	return h, h.Status != ""
+}
+
+// querySmartDevice runs smartctl -H -A on one device. ok is false when the
+// device cannot be queried or has no SMART health.
+func querySmartDevice(ctx context.Context, d smartDevice) (smartHealth, bool) {
+	args := []string{"-H", "-A"}
+	if d.Type != "" {
+		args = append(args, "-d", d.Type)
+	}
+	out, err := runSmartctl(ctx, append(args, d.Name)...)
+	if exitErr := (*exec.ExitError)(nil); errors.As(err, &exitErr) {
+		if exitErr.ExitCode()&smartctlUnusable != 0 {
+			return smartHealth{}, false
+		}
+	} else if err != nil {
+		return smartHealth{}, false
+	}
+	h, ok := parseSmartHealth(out)
+	h.Device = d
+	return h, ok
+}
+
+// formatSmartHealth renders one row per device.
+func formatSmartHealth(healths []smartHealth) string {
+	orDash := func(v string) string {
+		if v == "" {
+			return "-"
+		}
+		return v
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%-16s %-10s %-10s %-12s %s\n", "Device", "Type", "Health", "Reallocated", "Temperature"))
+	for _, h := range healths {
+		sb.WriteString(fmt.Sprintf("%-16s %-10s %-10s %-12s %s\n", h.Device.Name, orDash(h.Device.Type), h.Status, orDash(h.Reallocated), orDash(h.Temperature)))
+	}
+	return sb.String()
+}
+
+// collectDiskHealth is the disk_health report: the SMART health of every
+// device smartctl can find. Devices smartctl cannot open, usually for lack of
+// privileges, or that have no SMART support are left out; a host without
+// smartctl, such as most containers, says so instead of failing.
+func collectDiskHealth(ctx context.Context) string {
+	var sb strings.Builder
+	sb.WriteString("Disk Health Report\n")
+	sb.WriteString("==================\n\n")
+
+	out, err := runSmartctl(ctx, "--scan")
+	if errors.Is(err, exec.ErrNotFound) {
+		sb.WriteString("smartctl not found: install smartmontools to report disk health\n")
+		return sb.String()
+	}
+	if err != nil {
+		sb.WriteString(fmt.Sprintf("Device scan failed: %v\n", err))
+		return sb.String()
+	}
+	devices := parseSmartScan(out)
+	var healths []smartHealth
+	failing := 0
+	for _, d := range devices {
+		h, ok := querySmartDevice(ctx, d)
+		if !ok {
+			continue
+		}
+		if h.Status != "PASSED" && h.Status != "OK" {
+			failing++
+		}
+		healths = append(healths, h)
+	}
+	if len(healths) == 0 {
+		sb.WriteString(fmt.Sprintf("No SMART health available for %d device(s) found (smartctl usually needs root or raw device access)\n", len(devices)))
+		return sb.String()
+	}
+	sb.WriteString(formatSmartHealth(healths))
+	if skipped := len(devices) - len(healths); skipped > 0 {
+		sb.WriteString(fmt.Sprintf("\nNote: %d device(s) skipped, not readable or without SMART support\n", skipped))
+	}
+	if failing > 0 {
+		sb.WriteString(fmt.Sprintf("\nWARNING: %d device(s) not reporting healthy\n", failing))
+	}
+	return sb.String()
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// API key sources, in the order they are checked by extractAPIKey.
+const (
+	sourceGoogHeader = "x-goog-api-key"
+	sourceAPIHeader  = "x-api-key"
+	sourceQueryParam = "apiKey query"
+	sourceMissing    = "missing"
+)
+
+// authSourceStats counts how often each API key source is used so that the
+// query-parameter path can be deprecated based on real traffic. It is safe
+// for concurrent use.
+type authSourceStats struct {
+	googHeader atomic.Int64
+	apiHeader  atomic.Int64
+	queryParam atomic.Int64
+	missing    atomic.Int64
+}
+
+func (s *authSourceStats) record(source string) {
+	switch source {
+	case sourceGoogHeader:
+		s.googHeader.Add(1)
+	case sourceAPIHeader:
+		s.apiHeader.Add(1)
+	case sourceQueryParam:
+		s.queryParam.Add(1)
+	default:
+		s.missing.Add(1)
+	}
+}
+
+func (s *authSourceStats) report() string {
+	var sb strings.Builder
+	sb.WriteString("API Key Source Statistics\n")
+	sb.WriteString("=========================\n\n")
+	sb.WriteString(fmt.Sprintf("%-18s%d\n", sourceGoogHeader+":", s.googHeader.Load()))
+	sb.WriteString(fmt.Sprintf("%-18s%d\n", sourceAPIHeader+":", s.apiHeader.Load()))
+	sb.WriteString(fmt.Sprintf("%-18s%d\n", sourceQueryParam+":", s.queryParam.Load()))
+	sb.WriteString(fmt.Sprintf("%-18s%d\n", sourceMissing+":", s.missing.Load()))
+	return sb.String()
+}
+
+// extractAPIKey returns the API key presented by the request and the source
+// it was read from.
+func extractAPIKey(r *http.Request) (string, string) {
+	if key := r.Header.Get("x-goog-api-key"); key != "" {
+		return key, sourceGoogHeader
+	}
+	if key := r.Header.Get("x-api-key"); key != "" {
+		return key, sourceAPIHeader
+	}
+	if key := r.URL.Query().Get("apiKey"); key != "" {
+		return key, sourceQueryParam
+	}
+	return "", sourceMissing
+}
+
+// requestStats counts requests since the process started, for environments
+// without an external metrics system. Health checks are not counted. It is
+// safe for concurrent use.
+type requestStats struct {
+	start        time.Time
+	total        atomic.Int64
+	unauthorized atomic.Int64
+}
+
+func newRequestStats() *requestStats {
+	return &requestStats{start: time.Now()}
+}
+
+// serveStats serves /stats as JSON.
+func (s *requestStats) serveStats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"total_requests":        s.total.Load(),
+		"unauthorized_requests": s.unauthorized.Load(),
+		"uptime_seconds":        int64(time.Since(s.start).Seconds()),
+		"active_connections":    serverConns.active.Load(),
+		"accepted_connections":  serverConns.accepted.Load(),
+	})
+}
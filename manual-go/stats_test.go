@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestExtractAPIKeyRecordsSource(t *testing.T) {
+	var stats authSourceStats
+
+	tests := []struct {
+		name       string
+		header     string
+		url        string
+		wantKey    string
+		wantSource string
+	}{
+		{"goog header", "x-goog-api-key", "/mcp", "k1", sourceGoogHeader},
+		{"api header", "x-api-key", "/mcp", "k2", sourceAPIHeader},
+		{"query param", "", "/mcp?apiKey=k3", "k3", sourceQueryParam},
+		{"missing", "", "/mcp", "", sourceMissing},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", tt.url, nil)
+			if tt.header != "" {
+				r.Header.Set(tt.header, tt.wantKey)
+			}
+			key, source := extractAPIKey(r)
+			if key != tt.wantKey || source != tt.wantSource {
+				t.Errorf("Expected (%q, %q), got (%q, %q)", tt.wantKey, tt.wantSource, key, source)
+			}
+			stats.record(source)
+		})
+	}
+
+	report := stats.report()
+	for _, source := range []string{sourceGoogHeader, sourceAPIHeader, sourceQueryParam, sourceMissing} {
+		if !strings.Contains(report, source+":") {
+			t.Errorf("Expected report to contain %q, got: %s", source, report)
+		}
+	}
+	if stats.queryParam.Load() != 1 {
+		t.Errorf("Expected 1 query param use, got %d", stats.queryParam.Load())
+	}
+}
+
+func TestRequestStats(t *testing.T) {
+	stats := newRequestStats()
+	stats.total.Add(3)
+	stats.unauthorized.Add(1)
+
+	rec := httptest.NewRecorder()
+	stats.serveStats(rec, httptest.NewRequest(http.MethodGet, "/stats", nil))
+	var got map[string]int64
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("Expected JSON, got %q: %v", rec.Body.String(), err)
+	}
+	if got["total_requests"] != 3 || got["unauthorized_requests"] != 1 {
+		t.Errorf("Unexpected counters: %v", got)
+	}
+	if _, ok := got["uptime_seconds"]; !ok {
+		t.Errorf("Expected uptime_seconds, got: %v", got)
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"runtime"
+	"slices"
+	"time"
+)
+
+// hostReport is the structured form of the report's host section.
+type hostReport struct {
+	SystemName string    `json:"system_name"`
+	OSName     string    `json:"os_name"`
+	HostName   string    `json:"host_name"`
+	BootTime   time.Time `json:"boot_time"`
+	ServerTime time.Time `json:"server_time"`
+}
+
+// cpuReport is the structured form of the report's CPU section.
+type cpuReport struct {
+	Cores         int     `json:"cores"`
+	AllocatedVCPU float64 `json:"allocated_vcpu,omitempty"`
+}
+
+// usageReport is the size and use of memory or swap, in bytes.
+type usageReport struct {
+	Total   uint64  `json:"total"`
+	Used    uint64  `json:"used"`
+	Percent float64 `json:"percent"`
+}
+
+// systemInfoJSON is the system_info_json result, the structured counterpart
+// of the text system report. CollectedAt is an RFC 3339 UTC timestamp. A
+// section that failed is left out and its error recorded under the section
+// name.
+type systemInfoJSON struct {
+	CollectedAt string             `json:"collected_at"`
+	Host        *hostReport        `json:"host,omitempty"`
+	CPU         *cpuReport         `json:"cpu,omitempty"`
+	Memory      *usageReport       `json:"memory,omitempty"`
+	Swap        *usageReport       `json:"swap,omitempty"`
+	Interfaces  []networkInterface `json:"interfaces"`
+	Errors      map[string]string  `json:"errors,omitempty"`
+}
+
+// systemInfoJSONInput is the system_info_json tool input.
+type systemInfoJSONInput struct {
+	IncludeIdle bool `json:"include_idle,omitempty" jsonschema:"also list network interfaces with no RX or TX traffic"`
+}
+
+func (systemInfoJSONInput) validate() error { return nil }
+
+// collectSystemInfoJSON gathers the host, CPU, memory, swap and network
+// values of the system report from opts.Source. Interfaces follow
+// opts.Interfaces and opts.IncludeIdle as in the text report.
+func collectSystemInfoJSON(opts systemInfoOptions) systemInfoJSON {
+	src := metricsSourceOr(opts.Source)
+	report := systemInfoJSON{CollectedAt: time.Now().UTC().Format(time.RFC3339), Interfaces: []networkInterface{}}
+	fail := func(section string, err error) {
+		if report.Errors == nil {
+			report.Errors = make(map[string]string)
+		}
+		report.Errors[section] = err.Error()
+	}
+
+	if h, err := src.HostInfo(); err != nil {
+		fail("host", err)
+	} else {
+		report.Host = &hostReport{
+			SystemName: runtime.GOOS,
+			OSName:     h.OS,
+			HostName:   h.Hostname,
+			BootTime:   time.Unix(int64(h.BootTime), 0).UTC(),
+			ServerTime: time.Now().UTC(),
+		}
+	}
+	if cores, err := src.CPUCounts(true); err != nil {
+		fail("cpu", err)
+	} else {
+		report.CPU = &cpuReport{Cores: cores}
+		if vcpu, ok := cgroupCPULimit(); ok {
+			report.CPU.AllocatedVCPU = vcpu
+		}
+	}
+	if v, err := src.VirtualMemory(); err != nil {
+		fail("memory", err)
+	} else {
+		report.Memory = &usageReport{Total: v.Total, Used: v.Used, Percent: v.UsedPercent}
+	}
+	if s, err := src.SwapMemory(); err != nil {
+		fail("swap", err)
+	} else {
+		report.Swap = &usageReport{Total: s.Total, Used: s.Used, Percent: s.UsedPercent}
+	}
+	if interfaces, err := collectInterfaces(src); err != nil {
+		fail("network", err)
+	} else {
+		report.Interfaces = slices.DeleteFunc(interfaces, func(n networkInterface) bool {
+			return !opts.Interfaces.matches(n.Name) || (!opts.IncludeIdle && n.isIdle())
+		})
+	}
+	return report
+}
+
+// systemInfoJSONReport renders collectSystemInfoJSON as indented JSON.
+func systemInfoJSONReport(opts systemInfoOptions) (string, error) {
+	out, err := json.MarshalIndent(collectSystemInfoJSON(opts), "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(out) + "\n", nil
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// toolNames are the default names of every tool the server can register,
+// including ones gated behind other settings. TOOLS_CONFIG_FILE entries are
+// keyed by these names.
+var toolNames = []string{"local_system_info", "system_info_json", "runtime_info", "cpu_load", "disk_usage", "disk_latency", "full_report", "top_processes", "list_processes", "fd_usage", "listening_ports", "sessions_info", "disk_health", "dns_info", "baseline_check", "deployment_info", "auth_source_stats", "recent_logs"}
+
+// toolOverride customizes a single tool. Unset fields keep the default.
+type toolOverride struct {
+	Enabled     *bool  `json:"enabled,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// toolsConfig maps default tool names to their overrides. A nil toolsConfig
+// leaves every tool as built.
+type toolsConfig map[string]toolOverride
+
+// loadToolsConfig reads and validates the TOOLS_CONFIG_FILE document, e.g.
+//
+//	{"top_processes": {"enabled": false}, "disk_usage": {"name": "disks"}}
+//
+// An empty path returns a nil config.
+func loadToolsConfig(path string) (toolsConfig, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TOOLS_CONFIG_FILE: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var tc toolsConfig
+	if err := dec.Decode(&tc); err != nil {
+		return nil, fmt.Errorf("invalid TOOLS_CONFIG_FILE %q: %w", path, err)
+	}
+	if err := tc.validate(); err != nil {
+		return nil, fmt.Errorf("invalid TOOLS_CONFIG_FILE %q: %w", path, err)
+	}
+	return tc, nil
+}
+
+// validate rejects unknown tools and renames that would collide with another
+// tool's name.
+func (tc toolsConfig) validate() error {
+	for key := range tc {
+		if !slices.Contains(toolNames, key) {
+			return fmt.Errorf("unknown tool %q", key)
+		}
+	}
+	seen := make(map[string]string, len(toolNames))
+	for _, key := range toolNames {
+		name := tc.name(key)
+		if other, ok := seen[name]; ok {
+			return fmt.Errorf("tools %q and %q both named %q", other, key, name)
+		}
+		seen[name] = key
+	}
+	return nil
+}
+
+// name returns the name a tool is registered under.
+func (tc toolsConfig) name(key string) string {
+	if o, ok := tc[key]; ok && o.Name != "" {
+		return o.Name
+	}
+	return key
+}
+
+// apply rewrites t with its override and reports whether it is enabled.
+func (tc toolsConfig) apply(t *mcp.Tool) bool {
+	o, ok := tc[t.Name]
+	if !ok {
+		return true
+	}
+	if o.Enabled != nil && !*o.Enabled {
+		return false
+	}
+	if o.Name != "" {
+		t.Name = o.Name
+	}
+	if o.Description != "" {
+		t.Description = o.Description
+	}
+	return true
+}
+
+// readOnlyTool annotates a tool that only reads host state, so MCP hosts can
+// run it without asking for confirmation. openWorld marks tools that reach
+// beyond the host, such as DNS lookups or the metadata server queried for
+// the Deployment section. A tool that changes state must spell out its own
+// annotations instead.
+func readOnlyTool(openWorld bool) *mcp.ToolAnnotations {
+	destructive := false
+	return &mcp.ToolAnnotations{ReadOnlyHint: true, IdempotentHint: true, DestructiveHint: &destructive, OpenWorldHint: &openWorld}
+}
+
+// addTool registers a tool after applying its TOOLS_CONFIG_FILE override,
+// skipping it when disabled. The handler runs only for input that passes
+// validate.
+func addTool[In toolInput](server *mcp.Server, tc toolsConfig, t *mcp.Tool, h mcp.ToolHandlerFor[In, any]) {
+	if !tc.apply(t) {
+		return
+	}
+	mcp.AddTool(server, t, validated(h))
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func writeToolsConfig(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "tools.json")
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadToolsConfig(t *testing.T) {
+	if tc, err := loadToolsConfig(""); err != nil || tc != nil {
+		t.Fatalf("Expected nil config for empty path, got %v, %v", tc, err)
+	}
+
+	path := writeToolsConfig(t, `{"top_processes": {"enabled": false}, "disk_usage": {"name": "disks", "description": "Mounted filesystems"}}`)
+	tc, err := loadToolsConfig(path)
+	if err != nil {
+		t.Fatalf("loadToolsConfig returned error: %v", err)
+	}
+	disk := &mcp.Tool{Name: "disk_usage", Description: "Disk usage"}
+	if !tc.apply(disk) || disk.Name != "disks" || disk.Description != "Mounted filesystems" {
+		t.Errorf("Expected disk_usage renamed, got %+v", disk)
+	}
+	if tc.apply(&mcp.Tool{Name: "top_processes"}) {
+		t.Error("Expected top_processes disabled")
+	}
+	info := &mcp.Tool{Name: "local_system_info", Description: "System info"}
+	if !tc.apply(info) || info.Name != "local_system_info" {
+		t.Errorf("Expected local_system_info unchanged, got %+v", info)
+	}
+}
+
+func TestLoadToolsConfigRejectsInvalid(t *testing.T) {
+	tests := map[string]string{
+		"malformed":     `{"disk_usage": `,
+		"unknown tool":  `{"ping": {"enabled": true}}`,
+		"unknown field": `{"disk_usage": {"title": "Disks"}}`,
+		"name clash":    `{"disk_usage": {"name": "local_system_info"}}`,
+	}
+	for name, body := range tests {
+		if _, err := loadToolsConfig(writeToolsConfig(t, body)); err == nil || !strings.Contains(err.Error(), "TOOLS_CONFIG_FILE") {
+			t.Errorf("%s: expected TOOLS_CONFIG_FILE error, got %v", name, err)
+		}
+	}
+	if _, err := loadToolsConfig(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("Expected error for missing file")
+	}
+}
+
+func TestToolsAnnotatedReadOnly(t *testing.T) {
+	handler, _ := newHandler(&Config{AuthMode: "none", PortsEnabled: true, SessionsEnabled: true, DiskHealthEnabled: true, DNSInfoEnabled: true, LogTailEnabled: true}, startKeyFetch(func() (string, error) { return "", nil }), nil)
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	ctx := context.Background()
+	client := mcp.NewClient(&mcp.Implementation{Name: "test"}, nil)
+	session, err := client.Connect(ctx, &mcp.StreamableClientTransport{Endpoint: srv.URL + "/mcp"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+	res, err := session.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Tools) != len(toolNames) {
+		t.Errorf("Expected all %d tools to be listed, got %d", len(toolNames), len(res.Tools))
+	}
+	for _, tool := range res.Tools {
+		a := tool.Annotations
+		if a == nil || !a.ReadOnlyHint || a.DestructiveHint == nil || *a.DestructiveHint || a.OpenWorldHint == nil {
+			t.Errorf("Expected %s to be annotated read-only, got %+v", tool.Name, a)
+		}
+	}
+}
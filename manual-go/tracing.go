@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"slices"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// tracer creates the collector spans. It is resolved through the global
+// provider, so it is a no-op until setupTracing installs a real one.
+var tracer = otel.Tracer("manual-go")
+
+// setupTracing installs an OTLP/HTTP tracer provider when
+// OTEL_EXPORTER_OTLP_ENDPOINT is set; the exporter reads the standard OTEL_*
+// variables itself. When the endpoint is unset, or OFFLINE is set, the global
+// no-op provider is kept. The returned function flushes and stops the
+// exporter.
+func setupTracing(ctx context.Context, cfg *Config, serviceName string) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	if cfg.OTelEndpoint == "" || cfg.Offline {
+		return func(context.Context) error { return nil }, nil
+	}
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", serviceName))),
+	)
+	otel.SetTracerProvider(tp)
+	return tp.Shutdown, nil
+}
+
+// withTracing wraps h so that every request except health checks gets a span,
+// continuing the trace from an incoming traceparent header.
+func withTracing(h http.Handler, serviceName string) http.Handler {
+	return otelhttp.NewHandler(h, serviceName, otelhttp.WithFilter(func(r *http.Request) bool {
+		return !slices.Contains([]string{"/", "/healthz", "/healthz/probe", "/livez", "/readyz"}, r.URL.Path)
+	}))
+}
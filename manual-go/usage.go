@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// usageText lists the commands and flags of the CLI.
+const usageText = `Usage: manual-go [command] [flags]
+
+With no command, starts the MCP server on $PORT (default 8080).
+
+Commands:
+  info                  Print the system information report (requires a valid API key)
+    --resolve-vendor    Annotate MAC addresses with their vendor
+    --memory-detail     Break used memory down by category
+    --remote USER@HOST  Run the report on another host over ssh
+  disk                  Print the disk usage report
+    --json              Print JSON instead of text
+    --show-device       Include the device backing each mount
+    --keep-duplicates   List every mount separately
+    --exact-bytes       Show exact byte counts instead of IEC units
+    --remote USER@HOST  Run the report on another host over ssh
+  processes             Print the top processes by memory usage
+  check                 Verify MCP_API_KEY against the key in the project
+    --offline           Only check that a key is provided; skip the cloud fetch
+  config                Print the resolved configuration
+    --json              Print JSON instead of text
+  doctor                Diagnose the local setup
+  version, --version    Print the version, git commit and build date
+  help, -h, --help      Show this help
+`
+
+// isHelp reports whether command asks for the usage text.
+func isHelp(command string) bool {
+	return command == "help" || command == "-h" || command == "--help"
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprint(w, usageText)
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// waitForTCPRetry is the pause between connection attempts to a dependency.
+const waitForTCPRetry = 500 * time.Millisecond
+
+// parseWaitForTCP parses WAIT_FOR_TCP, a comma-separated list of host:port
+// endpoints.
+func parseWaitForTCP(s string) ([]string, error) {
+	var endpoints []string
+	for item := range strings.SplitSeq(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if _, port, err := net.SplitHostPort(item); err != nil || port == "" {
+			return nil, fmt.Errorf("invalid WAIT_FOR_TCP entry %q: want host:port", item)
+		}
+		endpoints = append(endpoints, item)
+	}
+	return endpoints, nil
+}
+
+// waitForTCP blocks until every endpoint accepts a TCP connection or timeout
+// elapses, and returns the endpoints that never did. Endpoints are polled
+// concurrently, so the wait is bounded by timeout however many there are.
+func waitForTCP(endpoints []string, timeout time.Duration) []string {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	var (
+		mu      sync.Mutex
+		pending []string
+		wg      sync.WaitGroup
+	)
+	for _, addr := range endpoints {
+		wg.Go(func() {
+			var d net.Dialer
+			for {
+				conn, err := d.DialContext(ctx, "tcp", addr)
+				if err == nil {
+					conn.Close()
+					slog.Info("Dependency reachable", "endpoint", addr)
+					return
+				}
+				select {
+				case <-ctx.Done():
+					mu.Lock()
+					pending = append(pending, addr)
+					mu.Unlock()
+					return
+				case <-time.After(waitForTCPRetry):
+				}
+			}
+		})
+	}
+	wg.Wait()
+	return pending
+}
+
+// waitForDependencies runs the WAIT_FOR_TCP startup wait. Unreachable
+// dependencies are logged but do not stop the server, which can still
+// report on the host without them.
+func waitForDependencies(endpoints []string, timeout time.Duration) {
+	if len(endpoints) == 0 {
+		return
+	}
+	slog.Info("Waiting for WAIT_FOR_TCP endpoints", "endpoints", endpoints, "timeout", timeout)
+	start := time.Now()
+	if pending := waitForTCP(endpoints, timeout); len(pending) > 0 {
+		slog.Warn("Starting before all WAIT_FOR_TCP endpoints were reachable", "unreachable", pending, "waited", time.Since(start).Round(time.Millisecond))
+		return
+	}
+	slog.Info("All WAIT_FOR_TCP endpoints reachable", "waited", time.Since(start).Round(time.Millisecond))
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestReportWebhookDeliverRetries(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer t" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if body, _ := io.ReadAll(r.Body); r.Header.Get("X-Report-Signature") != signReport(string(body), "k") {
+			http.Error(w, "bad signature", http.StatusBadRequest)
+			return
+		}
+		if calls.Add(1) == 1 {
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	h := newReportWebhook(&Config{WebhookURL: srv.URL, WebhookHeader: "Authorization: Bearer t", ReportSigningKey: "k"})
+	h.backoff = time.Millisecond
+	if err := h.deliver(context.Background(), []byte(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("Expected one retry after a 503, got %d calls", n)
+	}
+
+	h.headerValue = "Bearer wrong"
+	calls.Store(0)
+	if err := h.deliver(context.Background(), []byte(`{}`)); err == nil {
+		t.Error("Expected a 401 to fail the delivery")
+	}
+	if n := calls.Load(); n != 0 {
+		t.Errorf("Expected no retry of a 401, got %d handled calls", n)
+	}
+}
+
+func TestRunReportWebhookStops(t *testing.T) {
+	delivered := make(chan struct{}, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case delivered <- struct{}{}:
+		default:
+		}
+	}))
+	defer srv.Close()
+
+	h := newReportWebhook(&Config{WebhookURL: srv.URL})
+	stop := runReportWebhook(h, 10*time.Millisecond, func(context.Context) (string, error) { return `{}`, nil })
+	select {
+	case <-delivered:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected a delivery")
+	}
+	stop()
+
+	stop = runReportWebhook(h, 10*time.Millisecond, func(context.Context) (string, error) { return "", errors.New("collect failed") })
+	stop()
+}
+
+func TestParseWebhookHeader(t *testing.T) {
+	if name, value, err := parseWebhookHeader("X-Api-Key:  abc "); err != nil || name != "X-Api-Key" || value != "abc" {
+		t.Errorf("Unexpected parse: %q %q %v", name, value, err)
+	}
+	for _, v := range []string{"Bearer abc", "Authorization:", ": abc", "Bad Name: abc"} {
+		if _, _, err := parseWebhookHeader(v); err == nil {
+			t.Errorf("Expected %q to be rejected", v)
+		}
+	}
+	if err := validateWebhookURL("ftp://collector"); err == nil {
+		t.Error("Expected a non-HTTP URL to be rejected")
+	}
+}
@@ -0,0 +1,23 @@
+package main
+
+import "testing"
+
+func TestAdminAddr(t *testing.T) {
+	if got := adminAddr("9090"); got != "127.0.0.1:9090" {
+		t.Errorf("Expected a bare port to bind to localhost, got %s", got)
+	}
+	if got := adminAddr("0.0.0.0:9090"); got != "0.0.0.0:9090" {
+		t.Errorf("Expected host:port to be kept, got %s", got)
+	}
+}
+
+func TestValidateAdminPort(t *testing.T) {
+	if err := validateAdminPort("9090", "8080"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	for _, bad := range []string{"8080", "0", "70000", "admin", "[::1"} {
+		if err := validateAdminPort(bad, "8080"); err == nil {
+			t.Errorf("Expected ADMIN_PORT %q to be rejected", bad)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+)
+
+// resolveAuthMode sets cfg.AuthMode from AUTH_MODE. When AUTH_MODE is unset,
+// the mode inferred from the rest of the environment is used instead so that
+// existing deployments keep working.
+func resolveAuthMode(cfg *Config, inferred string, supported ...string) error {
+	mode := strings.ToLower(strings.TrimSpace(os.Getenv("AUTH_MODE")))
+	if mode == "" {
+		cfg.AuthMode, cfg.AuthModeInferred = inferred, true
+		return nil
+	}
+	if !slices.Contains(supported, mode) {
+		return fmt.Errorf("invalid AUTH_MODE %q: supported modes are %s", mode, strings.Join(supported, ", "))
+	}
+	cfg.AuthMode = mode
+	return nil
+}
+
+// validateAuth verifies that the environment required by the chosen auth
+// mode is present, so the server fails fast instead of running unsecured.
+func (c *Config) validateAuth() error {
+	if c.DebugLoad && c.AuthMode == "none" {
+		return fmt.Errorf("ENABLE_DEBUG_LOAD requires authentication; it cannot be used with AUTH_MODE=none")
+	}
+	if c.SessionsEnabled && c.AuthMode == "none" {
+		return fmt.Errorf("SESSIONS_INFO_ENABLED requires authentication; it cannot be used with AUTH_MODE=none")
+	}
+	if c.AuthMode == "iap" && os.Getenv("K_SERVICE") == "" {
+		return fmt.Errorf("AUTH_MODE=iap requires running on Cloud Run behind Identity-Aware Proxy (K_SERVICE is not set)")
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+package main
+
+import "testing"
+
+func TestValidateAuth(t *testing.T) {
+	t.Setenv("K_SERVICE", "")
+	if err := (&Config{AuthMode: "iap"}).validateAuth(); err == nil {
+		t.Error("Expected iap mode outside Cloud Run to be rejected")
+	}
+	t.Setenv("K_SERVICE", "proxy-go")
+	if err := (&Config{AuthMode: "iap"}).validateAuth(); err != nil {
+		t.Errorf("Expected iap mode on Cloud Run to be valid, got: %v", err)
+	}
+	if err := (&Config{AuthMode: "none"}).validateAuth(); err != nil {
+		t.Errorf("Expected none mode to be valid, got: %v", err)
+	}
+	if err := (&Config{AuthMode: "none", SessionsEnabled: true}).validateAuth(); err == nil {
+		t.Error("Expected sessions_info without authentication to be rejected")
+	}
+}
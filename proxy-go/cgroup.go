@@ -0,0 +1,41 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// cgroupRoot is where the cgroup v2 hierarchy is mounted. Tests point it at a
+// temporary directory.
+var cgroupRoot = "/sys/fs/cgroup"
+
+// cgroupCPULimit returns the vCPU allowance from the cgroup v2 cpu.max quota.
+// It reports false when there is no quota (bare metal, or "max") or the file
+// cannot be read.
+func cgroupCPULimit() (float64, bool) {
+	data, err := os.ReadFile(filepath.Join(cgroupRoot, "cpu.max"))
+	if err != nil {
+		return 0, false
+	}
+	return parseCPUMax(string(data))
+}
+
+// parseCPUMax parses the "<quota> <period>" format of cpu.max, where quota is
+// "max" when unlimited.
+func parseCPUMax(s string) (float64, bool) {
+	fields := strings.Fields(s)
+	if len(fields) != 2 || fields[0] == "max" {
+		return 0, false
+	}
+	quota, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, false
+	}
+	period, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil || period <= 0 {
+		return 0, false
+	}
+	return quota / period, true
+}
@@ -0,0 +1,526 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config holds the fully-resolved runtime configuration. It is populated once
+// from the environment by loadConfig and passed to the server and CLI paths.
+type Config struct {
+	Port              string
+	AdminPort         string
+	AuthMode          string
+	AuthModeInferred  bool
+	ClientLogging     bool
+	ClientLogLevel    slog.Level
+	TLSCertFile       string
+	TLSKeyFile        string
+	TLSMinVersion     string
+	TLSCipherProfile  string
+	ProcessWorkers    int
+	ProcessDeadline   time.Duration
+	ProcessPageMax    int
+	MaxResultBytes    int
+	DiskMinTotalMB    int
+	DiskWarnPercent   float64
+	DiskFailPercent   float64
+	ProbeTimeout      time.Duration
+	SectionRetries    int
+	RetryThreshold    int
+	SectionPriority   []string
+	BackgroundRefresh bool
+	RefreshInterval   time.Duration
+	MaxUptime         time.Duration
+	ShutdownGrace     time.Duration
+	ShutdownDrain     time.Duration
+	MaxHeaderBytes    int
+	MaxDecompressed   int
+	TCPKeepAlive      time.Duration
+	ProxyProtocol     bool
+	WaitForTCP        []string
+	WaitForTimeout    time.Duration
+	MaxSampleInterval time.Duration
+	ReportSigningKey  string
+	WebhookURL        string
+	WebhookInterval   time.Duration
+	WebhookHeader     string
+	AutoMaxprocs      bool
+	AllowRoot         bool
+	DebugTiming       bool
+	DebugLoad         bool
+	NetIfaceInclude   string
+	NetIfaceExclude   string
+	OUIFile           string
+	RemoteBinary      string
+	IfaceFilter       interfaceFilter
+	RequestLogSample  float64
+	SlowThreshold     time.Duration
+	CloudLogging      bool
+	LogOutput         string
+	LogTailEnabled    bool
+	PortsEnabled      bool
+	SessionsEnabled   bool
+	DiskHealthEnabled bool
+	DNSInfoEnabled    bool
+	DNSTestHost       string
+	BaselinesFile     string
+	LogTailFile       string
+	ToolsConfigFile   string
+	Tools             toolsConfig
+	ToolRateLimits    toolRateLimits
+	UpstreamURL       string
+	UpstreamToken     string
+}
+
+// configEntry is a single printable setting. Secrets are stored already
+// redacted so entries can be printed or serialized without further care.
+type configEntry struct {
+	Key   string
+	Label string
+	Value any
+}
+
+func loadConfig() (*Config, error) {
+	if err := loadEnvFile(); err != nil {
+		return nil, err
+	}
+	cfg := &Config{
+		Port: os.Getenv("PORT"),
+	}
+	if cfg.Port == "" {
+		cfg.Port = "8080"
+	}
+	if cfg.AdminPort = os.Getenv("ADMIN_PORT"); cfg.AdminPort != "" {
+		if err := validateAdminPort(cfg.AdminPort, cfg.Port); err != nil {
+			return nil, err
+		}
+	}
+
+	var err error
+	if err := resolveAuthMode(cfg, "none", "none", "iap"); err != nil {
+		return nil, err
+	}
+	if cfg.ClientLogging, err = envBool("MCP_CLIENT_LOGGING", false); err != nil {
+		return nil, err
+	}
+	if v := os.Getenv("MCP_CLIENT_LOG_LEVEL"); v != "" {
+		if err := cfg.ClientLogLevel.UnmarshalText([]byte(v)); err != nil {
+			return nil, fmt.Errorf("invalid MCP_CLIENT_LOG_LEVEL %q: %w", v, err)
+		}
+	}
+	if cfg.ProcessWorkers, err = envInt("PROCESS_WORKERS", 8); err != nil {
+		return nil, err
+	}
+	if cfg.ProcessWorkers < 1 {
+		return nil, fmt.Errorf("invalid PROCESS_WORKERS %d: must be at least 1", cfg.ProcessWorkers)
+	}
+	if cfg.ProcessDeadline, err = envDuration("PROCESS_DEADLINE", 5*time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.ProcessPageMax, err = envInt("PROCESS_PAGE_MAX", defaultProcessPageMax); err != nil {
+		return nil, err
+	}
+	if cfg.ProcessPageMax < 1 {
+		return nil, fmt.Errorf("invalid PROCESS_PAGE_MAX %d: must be at least 1", cfg.ProcessPageMax)
+	}
+	if cfg.MaxResultBytes, err = envInt("MAX_RESULT_BYTES", defaultMaxResultBytes); err != nil {
+		return nil, err
+	}
+	if cfg.MaxResultBytes < 0 {
+		return nil, fmt.Errorf("invalid MAX_RESULT_BYTES %d: must not be negative", cfg.MaxResultBytes)
+	}
+	if cfg.DiskMinTotalMB, err = envInt("DISK_MIN_TOTAL_MB", 0); err != nil {
+		return nil, err
+	}
+	if cfg.DiskMinTotalMB < 0 {
+		return nil, fmt.Errorf("invalid DISK_MIN_TOTAL_MB %d: must not be negative", cfg.DiskMinTotalMB)
+	}
+	if cfg.DiskWarnPercent, err = envFloat("HEALTH_DISK_WARN_PERCENT", 90); err != nil {
+		return nil, err
+	}
+	if cfg.DiskFailPercent, err = envFloat("HEALTH_DISK_FAIL_PERCENT", 95); err != nil {
+		return nil, err
+	}
+	if !(cfg.DiskWarnPercent > 0 && cfg.DiskWarnPercent <= cfg.DiskFailPercent && cfg.DiskFailPercent <= 100) {
+		return nil, fmt.Errorf("invalid HEALTH_DISK_WARN_PERCENT %v and HEALTH_DISK_FAIL_PERCENT %v: need 0 < warn <= fail <= 100", cfg.DiskWarnPercent, cfg.DiskFailPercent)
+	}
+	if cfg.ProbeTimeout, err = envDuration("HEALTH_PROBE_TIMEOUT", defaultHealthProbeTimeout); err != nil {
+		return nil, err
+	}
+	if cfg.SectionRetries, err = envInt("SYSTEM_INFO_RETRIES", 0); err != nil {
+		return nil, err
+	}
+	if cfg.SectionRetries < 0 {
+		return nil, fmt.Errorf("invalid SYSTEM_INFO_RETRIES %d: must not be negative", cfg.SectionRetries)
+	}
+	if cfg.RetryThreshold, err = envInt("SYSTEM_INFO_RETRY_THRESHOLD", 1); err != nil {
+		return nil, err
+	}
+	if cfg.RetryThreshold < 0 {
+		return nil, fmt.Errorf("invalid SYSTEM_INFO_RETRY_THRESHOLD %d: must not be negative", cfg.RetryThreshold)
+	}
+	if cfg.SectionPriority, err = parseSectionPriority(os.Getenv("REPORT_SECTION_PRIORITY"), systemSectionNames); err != nil {
+		return nil, err
+	}
+	if cfg.BackgroundRefresh, err = envBool("ENABLE_BACKGROUND_REFRESH", false); err != nil {
+		return nil, err
+	}
+	if cfg.RefreshInterval, err = envDuration("BACKGROUND_REFRESH_INTERVAL", defaultRefreshInterval); err != nil {
+		return nil, err
+	}
+	if cfg.MaxUptime, err = envDuration("MAX_UPTIME", 0); err != nil {
+		return nil, err
+	}
+	graceSeconds, err := envInt("SHUTDOWN_GRACE_SECONDS", int(defaultShutdownGrace/time.Second))
+	if err != nil {
+		return nil, err
+	}
+	if graceSeconds <= 0 {
+		return nil, fmt.Errorf("invalid SHUTDOWN_GRACE_SECONDS %d: must be positive", graceSeconds)
+	}
+	cfg.ShutdownGrace = time.Duration(graceSeconds) * time.Second
+	drainSeconds, err := envInt("SHUTDOWN_DRAIN_SECONDS", 0)
+	if err != nil {
+		return nil, err
+	}
+	if drainSeconds < 0 {
+		return nil, fmt.Errorf("invalid SHUTDOWN_DRAIN_SECONDS %d: must not be negative", drainSeconds)
+	}
+	cfg.ShutdownDrain = time.Duration(drainSeconds) * time.Second
+	if cfg.MaxHeaderBytes, err = envInt("MAX_HEADER_BYTES", http.DefaultMaxHeaderBytes); err != nil {
+		return nil, err
+	}
+	if cfg.MaxHeaderBytes <= 0 {
+		return nil, fmt.Errorf("invalid MAX_HEADER_BYTES %d: must be positive", cfg.MaxHeaderBytes)
+	}
+	if cfg.MaxDecompressed, err = envInt("MAX_DECOMPRESSED_BYTES", defaultMaxDecompressed); err != nil {
+		return nil, err
+	}
+	if cfg.MaxDecompressed <= 0 {
+		return nil, fmt.Errorf("invalid MAX_DECOMPRESSED_BYTES %d: must be positive", cfg.MaxDecompressed)
+	}
+	// TCP_KEEPALIVE follows net.ListenConfig: 0 keeps Go's default period and
+	// a negative value disables keepalives
+	if cfg.TCPKeepAlive, err = envSignedDuration("TCP_KEEPALIVE", 0); err != nil {
+		return nil, err
+	}
+	if cfg.ProxyProtocol, err = envBool("ENABLE_PROXY_PROTOCOL", false); err != nil {
+		return nil, err
+	}
+	if cfg.WaitForTCP, err = parseWaitForTCP(os.Getenv("WAIT_FOR_TCP")); err != nil {
+		return nil, err
+	}
+	if cfg.WaitForTimeout, err = envDuration("WAIT_FOR_TIMEOUT", 30*time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.WaitForTimeout <= 0 {
+		return nil, fmt.Errorf("invalid WAIT_FOR_TIMEOUT %v: must be positive", cfg.WaitForTimeout)
+	}
+	if cfg.MaxSampleInterval, err = envDuration("MAX_SAMPLE_INTERVAL", defaultMaxSampleInterval); err != nil {
+		return nil, err
+	}
+	if cfg.MaxSampleInterval <= 0 {
+		return nil, fmt.Errorf("invalid MAX_SAMPLE_INTERVAL %v: must be positive", cfg.MaxSampleInterval)
+	}
+	cfg.ReportSigningKey = os.Getenv("REPORT_SIGNING_KEY")
+	if cfg.WebhookURL = os.Getenv("REPORT_WEBHOOK_URL"); cfg.WebhookURL != "" {
+		if err := validateWebhookURL(cfg.WebhookURL); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.WebhookInterval, err = envDuration("REPORT_WEBHOOK_INTERVAL", defaultWebhookInterval); err != nil {
+		return nil, err
+	}
+	if cfg.WebhookInterval <= 0 {
+		return nil, fmt.Errorf("invalid REPORT_WEBHOOK_INTERVAL %v: must be positive", cfg.WebhookInterval)
+	}
+	if cfg.WebhookHeader = os.Getenv("REPORT_WEBHOOK_AUTH_HEADER"); cfg.WebhookHeader != "" {
+		if _, _, err := parseWebhookHeader(cfg.WebhookHeader); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.AutoMaxprocs, err = envBool("AUTO_MAXPROCS", true); err != nil {
+		return nil, err
+	}
+	if cfg.AllowRoot, err = envBool("ALLOW_ROOT", true); err != nil {
+		return nil, err
+	}
+	if cfg.DebugTiming, err = envBool("DEBUG_TIMING", false); err != nil {
+		return nil, err
+	}
+	if cfg.DebugLoad, err = envBool("ENABLE_DEBUG_LOAD", false); err != nil {
+		return nil, err
+	}
+	cfg.NetIfaceInclude = os.Getenv("NET_IFACE_INCLUDE")
+	cfg.NetIfaceExclude = os.Getenv("NET_IFACE_EXCLUDE")
+	if cfg.IfaceFilter, err = newInterfaceFilter(cfg.NetIfaceInclude, cfg.NetIfaceExclude); err != nil {
+		return nil, err
+	}
+	cfg.OUIFile = os.Getenv("OUI_FILE")
+	if cfg.RemoteBinary = os.Getenv("REMOTE_BINARY"); cfg.RemoteBinary == "" {
+		cfg.RemoteBinary = "proxy-go"
+	}
+	if err := loadOUIFile(cfg.OUIFile); err != nil {
+		return nil, err
+	}
+	if cfg.RequestLogSample, err = envFloat("REQUEST_LOG_SAMPLE", 1.0); err != nil {
+		return nil, err
+	}
+	if !(cfg.RequestLogSample >= 0 && cfg.RequestLogSample <= 1) {
+		return nil, fmt.Errorf("invalid REQUEST_LOG_SAMPLE %v: must be between 0.0 and 1.0", cfg.RequestLogSample)
+	}
+	if cfg.SlowThreshold, err = envDuration("SLOW_REQUEST_THRESHOLD", 2*time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.CloudLogging, err = envBool("LOG_CLOUD_LOGGING", false); err != nil {
+		return nil, err
+	}
+	cfg.LogOutput = os.Getenv("LOG_OUTPUT")
+	if cfg.LogTailEnabled, err = envBool("LOG_TAIL_ENABLED", false); err != nil {
+		return nil, err
+	}
+	if cfg.PortsEnabled, err = envBool("LISTENING_PORTS_ENABLED", false); err != nil {
+		return nil, err
+	}
+	if cfg.SessionsEnabled, err = envBool("SESSIONS_INFO_ENABLED", false); err != nil {
+		return nil, err
+	}
+	if cfg.DiskHealthEnabled, err = envBool("DISK_HEALTH_ENABLED", false); err != nil {
+		return nil, err
+	}
+	if cfg.DNSInfoEnabled, err = envBool("DNS_INFO_ENABLED", false); err != nil {
+		return nil, err
+	}
+	cfg.DNSTestHost = os.Getenv("DNS_TEST_HOSTNAME")
+	if cfg.DNSTestHost != "" && !validHostname(cfg.DNSTestHost) {
+		return nil, fmt.Errorf("invalid DNS_TEST_HOSTNAME %q", cfg.DNSTestHost)
+	}
+	cfg.BaselinesFile = os.Getenv("BASELINES_FILE")
+	if cfg.LogTailFile = os.Getenv("LOG_TAIL_FILE"); cfg.LogTailFile == "" {
+		cfg.LogTailFile = defaultLogTailFile()
+	}
+	if err := validateLogTailFile(cfg.LogTailFile); err != nil {
+		return nil, err
+	}
+	cfg.ToolsConfigFile = os.Getenv("TOOLS_CONFIG_FILE")
+	if cfg.Tools, err = loadToolsConfig(cfg.ToolsConfigFile); err != nil {
+		return nil, err
+	}
+	if cfg.ToolRateLimits, err = parseToolRateLimits(os.Getenv("TOOL_RATE_LIMITS")); err != nil {
+		return nil, err
+	}
+	cfg.UpstreamURL = os.Getenv("UPSTREAM_MCP_URL")
+	cfg.UpstreamToken = os.Getenv("UPSTREAM_BEARER_TOKEN")
+	if cfg.UpstreamURL != "" {
+		if _, err := parseUpstreamURL(cfg.UpstreamURL); err != nil {
+			return nil, fmt.Errorf("invalid %w", err)
+		}
+	}
+	if err := loadTLSConfig(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func envBool(name string, def bool) (bool, error) {
+	v := os.Getenv(name)
+	if v == "" {
+		return def, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s %q: %w", name, v, err)
+	}
+	return b, nil
+}
+
+func envInt(name string, def int) (int, error) {
+	v := os.Getenv(name)
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %w", name, v, err)
+	}
+	return n, nil
+}
+
+func envFloat(name string, def float64) (float64, error) {
+	v := os.Getenv(name)
+	if v == "" {
+		return def, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %w", name, v, err)
+	}
+	return f, nil
+}
+
+// envDuration reads a positive Go duration, or def when name is unset. A
+// zero def therefore means "off" and cannot be set explicitly.
+func envDuration(name string, def time.Duration) (time.Duration, error) {
+	d, err := envSignedDuration(name, def)
+	if v := os.Getenv(name); err == nil && v != "" && d <= 0 {
+		return 0, fmt.Errorf("invalid %s %q: must be positive", name, v)
+	}
+	return d, err
+}
+
+// envSignedDuration is envDuration for settings where zero and negative
+// values carry a meaning of their own.
+func envSignedDuration(name string, def time.Duration) (time.Duration, error) {
+	v := os.Getenv(name)
+	if v == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %w", name, v, err)
+	}
+	return d, nil
+}
+
+// fingerprint returns a short, non-reversible identifier for a secret so it
+// can be printed or logged without exposing the value.
+func fingerprint(secret string) string {
+	if secret == "" {
+		return "(not set)"
+	}
+	sum := sha256.Sum256([]byte(secret))
+	return "sha256:" + hex.EncodeToString(sum[:])[:12]
+}
+
+// redactURL shows a URL that may carry a token in its path or query as its
+// scheme and host plus the fingerprint of the whole URL.
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return fingerprint(raw)
+	}
+	return u.Scheme + "://" + u.Host + " " + fingerprint(raw)
+}
+
+// sectionRetry is the system report retry policy from SYSTEM_INFO_RETRIES
+// and SYSTEM_INFO_RETRY_THRESHOLD.
+func (c *Config) sectionRetry() sectionRetry {
+	return sectionRetry{Attempts: c.SectionRetries, Threshold: c.RetryThreshold}
+}
+
+func (c *Config) entries() []configEntry {
+	authModeSource := "AUTH_MODE"
+	if c.AuthModeInferred {
+		authModeSource = "inferred"
+	}
+	return []configEntry{
+		{"port", "Port", c.Port},
+		{"admin_port", "Admin Port", c.AdminPort},
+		{"auth_mode", "Auth Mode", c.AuthMode},
+		{"auth_mode_source", "Auth Mode Source", authModeSource},
+		{"client_logging", "Client Logging", c.ClientLogging},
+		{"client_log_level", "Client Log Level", c.ClientLogLevel.String()},
+		{"tls_enabled", "TLS Enabled", c.tlsEnabled()},
+		{"tls_cert_file", "TLS Cert File", c.TLSCertFile},
+		{"tls_min_version", "TLS Min Version", c.TLSMinVersion},
+		{"tls_cipher_profile", "TLS Cipher Profile", c.TLSCipherProfile},
+		{"process_workers", "Process Workers", c.ProcessWorkers},
+		{"process_deadline", "Process Deadline", c.ProcessDeadline.String()},
+		{"process_page_max", "Process Page Max", c.ProcessPageMax},
+		{"max_result_bytes", "Max Result Bytes", c.MaxResultBytes},
+		{"report_signing_key", "Report Signing Key", fingerprint(c.ReportSigningKey)},
+		{"report_webhook_url", "Report Webhook URL", redactURL(c.WebhookURL)},
+		{"report_webhook_interval", "Report Webhook Interval", c.WebhookInterval.String()},
+		{"report_webhook_auth_header", "Report Webhook Auth Header", fingerprint(c.WebhookHeader)},
+		{"disk_min_total_mb", "Disk Min Total MB", c.DiskMinTotalMB},
+		{"health_disk_warn_percent", "Disk Warn Percent", c.DiskWarnPercent},
+		{"health_disk_fail_percent", "Disk Fail Percent", c.DiskFailPercent},
+		{"health_probe_timeout", "Probe Timeout", c.ProbeTimeout.String()},
+		{"system_info_retries", "System Info Retries", c.SectionRetries},
+		{"system_info_retry_threshold", "System Info Retry Threshold", c.RetryThreshold},
+		{"report_section_priority", "Report Section Priority", strings.Join(c.SectionPriority, ",")},
+		{"background_refresh", "Background Refresh", c.BackgroundRefresh},
+		{"background_refresh_interval", "Refresh Interval", c.RefreshInterval.String()},
+		{"max_uptime", "Max Uptime", c.MaxUptime.String()},
+		{"shutdown_grace_seconds", "Shutdown Grace", c.ShutdownGrace.String()},
+		{"shutdown_drain_seconds", "Shutdown Drain", c.ShutdownDrain.String()},
+		{"max_header_bytes", "Max Header Bytes", c.MaxHeaderBytes},
+		{"max_decompressed_bytes", "Max Decompressed", c.MaxDecompressed},
+		{"tcp_keepalive", "TCP Keepalive", c.TCPKeepAlive.String()},
+		{"enable_proxy_protocol", "PROXY Protocol", c.ProxyProtocol},
+		{"wait_for_tcp", "Wait For TCP", strings.Join(c.WaitForTCP, ",")},
+		{"wait_for_timeout", "Wait For Timeout", c.WaitForTimeout.String()},
+		{"max_sample_interval", "Max Sample Interval", c.MaxSampleInterval.String()},
+		{"auto_maxprocs", "Auto GOMAXPROCS", c.AutoMaxprocs},
+		{"allow_root", "Allow Root", c.AllowRoot},
+		{"debug_timing", "Debug Timing", c.DebugTiming},
+		{"enable_debug_load", "Debug Load", c.DebugLoad},
+		{"net_iface_include", "Iface Include", c.NetIfaceInclude},
+		{"net_iface_exclude", "Iface Exclude", c.NetIfaceExclude},
+		{"oui_file", "OUI File", c.OUIFile},
+		{"remote_binary", "Remote Binary", c.RemoteBinary},
+		{"request_log_sample", "Request Log Sample", c.RequestLogSample},
+		{"slow_request_threshold", "Slow Request", c.SlowThreshold.String()},
+		{"log_cloud_logging", "Cloud Logging", c.CloudLogging},
+		{"log_output", "Log Output", c.LogOutput},
+		{"log_tail_enabled", "Log Tail Enabled", c.LogTailEnabled},
+		{"log_tail_file", "Log Tail File", c.LogTailFile},
+		{"listening_ports_enabled", "Listening Ports", c.PortsEnabled},
+		{"sessions_info_enabled", "Sessions Info", c.SessionsEnabled},
+		{"disk_health_enabled", "Disk Health", c.DiskHealthEnabled},
+		{"dns_info_enabled", "DNS Info", c.DNSInfoEnabled},
+		{"dns_test_hostname", "DNS Test Hostname", c.DNSTestHost},
+		{"baselines_file", "Baselines File", c.BaselinesFile},
+		{"tools_config_file", "Tools Config File", c.ToolsConfigFile},
+		{"tool_rate_limits", "Tool Rate Limits", c.ToolRateLimits.String()},
+		{"upstream_mcp_url", "Upstream MCP URL", redactUpstreamURL(c.UpstreamURL)},
+		{"upstream_bearer_token", "Upstream Token", fingerprint(c.UpstreamToken)},
+	}
+}
+
+func formatConfig(c *Config, asJSON bool) (string, error) {
+	entries := c.entries()
+	if asJSON {
+		m := make(map[string]any, len(entries))
+		for _, e := range entries {
+			m[e.Key] = e.Value
+		}
+		out, err := json.MarshalIndent(m, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(out) + "\n", nil
+	}
+
+	// Size the label column from the longest label so the values line up.
+	width := 0
+	for _, e := range entries {
+		width = max(width, len(e.Label)+1)
+	}
+	var sb strings.Builder
+	sb.WriteString("Effective Configuration\n")
+	sb.WriteString("=======================\n\n")
+	for _, e := range entries {
+		sb.WriteString(fmt.Sprintf("%-*s %v\n", width, e.Label+":", e.Value))
+	}
+	return sb.String(), nil
+}
+
+func hasFlag(args []string, flag string) bool {
+	for _, arg := range args {
+		if arg == flag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestConnStatsTrack(t *testing.T) {
+	var c connStats
+	for _, state := range []http.ConnState{http.StateNew, http.StateActive, http.StateIdle, http.StateNew, http.StateClosed, http.StateNew, http.StateHijacked} {
+		c.track(nil, state)
+	}
+	if active, accepted := c.active.Load(), c.accepted.Load(); active != 1 || accepted != 3 {
+		t.Errorf("Expected 1 active of 3 accepted, got %d of %d", active, accepted)
+	}
+}
+
+func TestListenAndServeCountsConnections(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	var c connStats
+	srv := &http.Server{Addr: addr, ConnState: c.track, Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})}
+	errc := make(chan error, 1)
+	go func() { errc <- listenAndServe(srv, 30*time.Second, false, "", "") }()
+
+	var resp *http.Response
+	for range 50 {
+		if resp, err = http.Get("http://" + addr); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if n := c.accepted.Load(); n != 1 {
+		t.Errorf("Expected 1 accepted connection, got %d", n)
+	}
+	srv.Close()
+	if err := <-errc; !errors.Is(err, http.ErrServerClosed) {
+		t.Errorf("Expected ErrServerClosed, got %v", err)
+	}
+}
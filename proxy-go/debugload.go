@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Limits for /debug/load runs. A run holds the request open for its whole
+// duration, so it is kept well under typical proxy timeouts.
+const (
+	defaultDebugLoadDuration = 5 * time.Second
+	maxDebugLoadDuration     = 30 * time.Second
+	maxDebugLoadConcurrency  = 16
+	defaultDebugLoadTool     = "local_system_info"
+)
+
+// debugLoadTargets are the report collectors behind each tool that
+// /debug/load can exercise, keyed by default tool name.
+func debugLoadTargets(cfg *Config) map[string]func(context.Context) {
+	return map[string]func(context.Context){
+		"local_system_info": func(ctx context.Context) {
+			collectSystemInfo(ctx, cfg.DebugTiming, systemInfoOptions{Interfaces: cfg.IfaceFilter})
+		},
+		"runtime_info": func(ctx context.Context) { collectRuntimeInfo() },
+		"disk_usage":   func(ctx context.Context) { collectDiskUsage(diskReportOptions{}) },
+		"top_processes": func(ctx context.Context) {
+			ctx, cancel := context.WithTimeout(ctx, cfg.ProcessDeadline)
+			defer cancel()
+			collectTopProcesses(ctx, cfg.ProcessWorkers)
+		},
+	}
+}
+
+// latencyStats summarizes per-call latencies in milliseconds.
+type latencyStats struct {
+	Min float64 `json:"min"`
+	P50 float64 `json:"p50"`
+	P95 float64 `json:"p95"`
+	P99 float64 `json:"p99"`
+	Max float64 `json:"max"`
+}
+
+// loadResult is the /debug/load response.
+type loadResult struct {
+	Tool        string       `json:"tool"`
+	Concurrency int          `json:"concurrency"`
+	DurationMS  int64        `json:"duration_ms"`
+	Iterations  int          `json:"iterations"`
+	Throughput  float64      `json:"throughput_per_sec"`
+	LatencyMS   latencyStats `json:"latency_ms"`
+}
+
+// runLoad calls fn back to back from concurrency workers until duration has
+// passed or ctx is done, and summarizes the calls.
+func runLoad(ctx context.Context, fn func(context.Context), duration time.Duration, concurrency int) loadResult {
+	ctx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+
+	start := time.Now()
+	var mu sync.Mutex
+	var latencies []time.Duration
+	var wg sync.WaitGroup
+	for range concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var local []time.Duration
+			for ctx.Err() == nil {
+				t := time.Now()
+				fn(ctx)
+				local = append(local, time.Since(t))
+			}
+			mu.Lock()
+			latencies = append(latencies, local...)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	result := loadResult{
+		Concurrency: concurrency,
+		DurationMS:  elapsed.Milliseconds(),
+		Iterations:  len(latencies),
+		Throughput:  float64(len(latencies)) / elapsed.Seconds(),
+	}
+	if len(latencies) > 0 {
+		slices.Sort(latencies)
+		at := func(q float64) float64 {
+			return latencyMS(latencies[int(q*float64(len(latencies)-1))])
+		}
+		result.LatencyMS = latencyStats{Min: at(0), P50: at(0.50), P95: at(0.95), P99: at(0.99), Max: at(1)}
+	}
+	return result
+}
+
+func latencyMS(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// serveDebugLoad benchmarks one tool's collector. Query parameters: tool
+// (default local_system_info), duration (Go duration, default 5s, max 30s)
+// and concurrency (default 1, max 16). It is a debug feature: only routed
+// when ENABLE_DEBUG_LOAD is set, and every run is logged as a warning.
+func serveDebugLoad(targets map[string]func(context.Context)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		tool := q.Get("tool")
+		if tool == "" {
+			tool = defaultDebugLoadTool
+		}
+		fn, ok := targets[tool]
+		if !ok {
+			http.Error(w, fmt.Sprintf("unknown tool %q", tool), http.StatusBadRequest)
+			return
+		}
+		duration := defaultDebugLoadDuration
+		if v := q.Get("duration"); v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil || d <= 0 || d > maxDebugLoadDuration {
+				http.Error(w, fmt.Sprintf("invalid duration %q: must be positive and at most %s", v, maxDebugLoadDuration), http.StatusBadRequest)
+				return
+			}
+			duration = d
+		}
+		concurrency := 1
+		if v := q.Get("concurrency"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 || n > maxDebugLoadConcurrency {
+				http.Error(w, fmt.Sprintf("invalid concurrency %q: must be between 1 and %d", v, maxDebugLoadConcurrency), http.StatusBadRequest)
+				return
+			}
+			concurrency = n
+		}
+
+		slog.Warn("DEBUG: load run started", "tool", tool, "duration", duration.String(), "concurrency", concurrency)
+		result := runLoad(r.Context(), fn, duration, concurrency)
+		result.Tool = tool
+		slog.Warn("DEBUG: load run finished", "tool", tool, "iterations", result.Iterations, "throughput_per_sec", result.Throughput)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(result)
+	}
+}
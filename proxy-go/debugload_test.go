@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRunLoad(t *testing.T) {
+	fn := func(ctx context.Context) { time.Sleep(time.Millisecond) }
+	result := runLoad(context.Background(), fn, 50*time.Millisecond, 2)
+	if result.Iterations == 0 || result.Throughput <= 0 {
+		t.Fatalf("Expected some iterations, got %+v", result)
+	}
+	l := result.LatencyMS
+	if l.Min <= 0 || l.Min > l.P50 || l.P50 > l.P99 || l.P99 > l.Max {
+		t.Errorf("Expected ordered latency percentiles, got %+v", l)
+	}
+}
+
+func TestServeDebugLoad(t *testing.T) {
+	targets := map[string]func(context.Context){"noop": func(ctx context.Context) {}}
+	handler := serveDebugLoad(targets)
+
+	for _, query := range []string{"?tool=missing", "?tool=noop&duration=1h", "?tool=noop&concurrency=0"} {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodGet, "/debug/load"+query, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", query, rec.Code)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/debug/load?tool=noop&duration=20ms&concurrency=2", nil))
+	var result loadResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatalf("Expected JSON, got %q: %v", rec.Body.String(), err)
+	}
+	if result.Tool != "noop" || result.Concurrency != 2 || result.Iterations == 0 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+}
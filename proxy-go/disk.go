@@ -0,0 +1,359 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/disk"
+)
+
+// diskUsageRetryDelay is the pause before retrying a failed disk.Usage call.
+// It is kept tiny so a genuinely stuck mount still fails fast.
+const diskUsageRetryDelay = 10 * time.Millisecond
+
+// statUsage is the underlying usage call, replaceable in tests.
+var statUsage = disk.Usage
+
+// listPartitions is the underlying partition listing, replaceable in tests.
+// It serves the background refresh snapshot while one is running.
+var listPartitions = cachedPartitions
+
+// noPartitionsNote replaces the rows of a text report when the host has no
+// partitions at all, so an empty report does not look like a bug.
+const noPartitionsNote = "No partitions found (possibly a minimal container)\n"
+
+// diskUsage wraps disk.Usage with a single quick retry, since stat calls on
+// busy hosts occasionally fail transiently (e.g. EAGAIN).
+func diskUsage(src MetricsSource, mountpoint string) (*disk.UsageStat, error) {
+	usage, err := src.DiskUsage(mountpoint)
+	if err == nil {
+		return usage, nil
+	}
+	time.Sleep(diskUsageRetryDelay)
+	return src.DiskUsage(mountpoint)
+}
+
+// partitionUsage is the structured view of a mounted partition. Sizes are raw
+// byte counts; Error is set when usage could not be read.
+type partitionUsage struct {
+	Mountpoint string     `json:"mountpoint"`
+	Device     string     `json:"device,omitempty"`
+	Fstype     string     `json:"fstype"`
+	Used       uint64     `json:"used"`
+	Total      uint64     `json:"total"`
+	Free       uint64     `json:"free"`
+	Percent    float64    `json:"percent"`
+	Inodes     inodeUsage `json:"inodes"`
+	Error      string     `json:"error,omitempty"`
+	// AlsoMountedAt lists the other mountpoints of the same filesystem when
+	// duplicates are collapsed.
+	AlsoMountedAt []string `json:"also_mounted_at,omitempty"`
+}
+
+type inodeUsage struct {
+	Used    uint64  `json:"used"`
+	Total   uint64  `json:"total"`
+	Free    uint64  `json:"free"`
+	Percent float64 `json:"percent"`
+}
+
+// collectPartitions returns the usage of every mounted partition. A partition
+// whose usage cannot be read is still returned, with Error set.
+func collectPartitions(src MetricsSource) ([]partitionUsage, error) {
+	parts, err := src.Partitions(false)
+	if err == nil && len(parts) == 0 {
+		// Minimal and distroless containers may list no physical devices;
+		// fall back to every mount before concluding there are none.
+		parts, err = src.Partitions(true)
+	}
+	if err != nil {
+		return nil, err
+	}
+	result := make([]partitionUsage, 0, len(parts))
+	for _, p := range parts {
+		pu := partitionUsage{Mountpoint: p.Mountpoint, Device: p.Device, Fstype: p.Fstype}
+		usage, err := diskUsage(src, p.Mountpoint)
+		if err != nil {
+			pu.Error = err.Error()
+		} else {
+			pu.Used = usage.Used
+			pu.Total = usage.Total
+			pu.Free = usage.Free
+			pu.Percent = usage.UsedPercent
+			pu.Inodes = inodeUsage{
+				Used:    usage.InodesUsed,
+				Total:   usage.InodesTotal,
+				Free:    usage.InodesFree,
+				Percent: usage.InodesUsedPercent,
+			}
+		}
+		result = append(result, pu)
+	}
+	return result, nil
+}
+
+// mountUsage reads the usage of the single named mountpoint with one
+// disk.Usage call, the quickest way to watch one volume. A path that does not
+// exist or is not a mountpoint is an errInvalidInput.
+func mountUsage(src MetricsSource, mountpoint string) (partitionUsage, error) {
+	ok, err := isMountpoint(mountpoint)
+	if err != nil {
+		return partitionUsage{}, fmt.Errorf("%w: mountpoint %q: %v", errInvalidInput, mountpoint, err)
+	}
+	if !ok {
+		return partitionUsage{}, fmt.Errorf("%w: %q is not a mountpoint", errInvalidInput, mountpoint)
+	}
+	usage, err := diskUsage(src, mountpoint)
+	if err != nil {
+		return partitionUsage{}, fmt.Errorf("mountpoint %q: %w", mountpoint, err)
+	}
+	return partitionUsage{
+		Mountpoint: mountpoint,
+		Fstype:     usage.Fstype,
+		Used:       usage.Used,
+		Total:      usage.Total,
+		Free:       usage.Free,
+		Percent:    usage.UsedPercent,
+		Inodes: inodeUsage{
+			Used:    usage.InodesUsed,
+			Total:   usage.InodesTotal,
+			Free:    usage.InodesFree,
+			Percent: usage.InodesUsedPercent,
+		},
+	}, nil
+}
+
+// diskReportOptions controls the disk usage report. The zero value hides
+// devices, shows each filesystem once and includes partitions of any size.
+type diskReportOptions struct {
+	ShowDevice     bool
+	KeepDuplicates bool
+	// MinTotalMB omits partitions smaller than this many MiB, such as the
+	// tiny pseudo-filesystems of container hosts.
+	MinTotalMB int
+	// ExactBytes shows sizes as full byte counts instead of IEC units.
+	ExactBytes bool
+	// Mountpoint reports only that mount, read directly without listing
+	// partitions. MinTotalMB and duplicate collapsing do not apply to it.
+	Mountpoint string
+	// Source is where partitions and usage are read; nil reads the host.
+	Source MetricsSource
+}
+
+// size renders a byte count for the text and Markdown reports.
+func (o diskReportOptions) size(n uint64) string {
+	if o.ExactBytes {
+		return formatThousands(n) + " B"
+	}
+	return formatBytes(n, unitsIEC)
+}
+
+// filterMinTotal drops partitions whose total size is below minMB MiB.
+// Partitions whose usage could not be read are kept, since their size is
+// unknown.
+func filterMinTotal(parts []partitionUsage, minMB int) []partitionUsage {
+	if minMB <= 0 {
+		return parts
+	}
+	result := make([]partitionUsage, 0, len(parts))
+	for _, p := range parts {
+		if p.Error != "" || p.Total >= uint64(minMB)<<20 {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// dedupePartitions collapses mounts of the same filesystem, identified by
+// device and fstype, into the first one mounted. Bind mounts in containers
+// (e.g. /etc/hosts) otherwise repeat the host disk many times. Only devices
+// under /dev are collapsed: tmpfs, overlay and other virtual filesystems share
+// placeholder device names while being distinct.
+func dedupePartitions(parts []partitionUsage) []partitionUsage {
+	result := make([]partitionUsage, 0, len(parts))
+	seen := make(map[string]int)
+	for _, p := range parts {
+		if !strings.HasPrefix(p.Device, "/dev/") {
+			result = append(result, p)
+			continue
+		}
+		key := p.Device + "\x00" + p.Fstype
+		if i, ok := seen[key]; ok {
+			result[i].AlsoMountedAt = append(result[i].AlsoMountedAt, p.Mountpoint)
+			continue
+		}
+		seen[key] = len(result)
+		result = append(result, p)
+	}
+	return result
+}
+
+// reportPartitions returns the partitions shown in the disk usage report.
+func reportPartitions(opts diskReportOptions) ([]partitionUsage, error) {
+	if opts.Mountpoint != "" {
+		p, err := mountUsage(metricsSourceOr(opts.Source), opts.Mountpoint)
+		if err != nil {
+			return nil, err
+		}
+		return []partitionUsage{p}, nil
+	}
+	parts, err := collectPartitions(metricsSourceOr(opts.Source))
+	if err != nil {
+		return nil, err
+	}
+	parts = filterMinTotal(parts, opts.MinTotalMB)
+	if opts.KeepDuplicates {
+		return parts, nil
+	}
+	return dedupePartitions(parts), nil
+}
+
+// alsoMountedNote is the text report suffix naming collapsed mountpoints.
+func (p partitionUsage) alsoMountedNote() string {
+	if len(p.AlsoMountedAt) == 0 {
+		return ""
+	}
+	return " (also at " + strings.Join(p.AlsoMountedAt, ", ") + ")"
+}
+
+// label is the leading column of a text report row: the mountpoint, followed
+// by the device when requested.
+func (p partitionUsage) label(showDevice bool) string {
+	if showDevice {
+		return fmt.Sprintf("%-20s %-20s", p.Mountpoint, p.Device)
+	}
+	return p.Mountpoint
+}
+
+// reportLine is the text report row of a readable partition: byte usage,
+// then inode usage when the filesystem reports inodes. FAT, many network and
+// FUSE filesystems report none and get no inode columns. The byte percentage
+// is padded so the inode columns line up across rows.
+func (p partitionUsage) reportLine(opts diskReportOptions) string {
+	line := fmt.Sprintf("%-20s %-10s %10s / %10s used %-8s",
+		p.label(opts.ShowDevice), p.Fstype, opts.size(p.Used), opts.size(p.Total), fmt.Sprintf("(%.1f%%)", p.Percent))
+	if p.Inodes.Total > 0 {
+		line += fmt.Sprintf("  %10d / %10d inodes (%.1f%%)", p.Inodes.Used, p.Inodes.Total, p.Inodes.Percent)
+	} else {
+		line = strings.TrimRight(line, " ")
+	}
+	return line + p.alsoMountedNote()
+}
+
+// collectDiskUsageJSON renders the partition list as a JSON array. The device
+// is only included when ShowDevice is set.
+func collectDiskUsageJSON(opts diskReportOptions) (string, error) {
+	parts, err := reportPartitions(opts)
+	if err != nil {
+		return "", err
+	}
+	if !opts.ShowDevice {
+		for i := range parts {
+			parts[i].Device = ""
+		}
+	}
+	out, err := json.MarshalIndent(parts, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(out) + "\n", nil
+}
+
+// diskUsageInput is the disk_usage tool input.
+type diskUsageInput struct {
+	Format         string `json:"format,omitempty" jsonschema:"output format: text (default), json or markdown"`
+	ShowDevice     bool   `json:"show_device,omitempty" jsonschema:"include the device backing each mount"`
+	KeepDuplicates bool   `json:"keep_duplicates,omitempty" jsonschema:"list every mount separately instead of showing each filesystem once"`
+	MinTotalMB     *int   `json:"min_total_mb,omitempty" jsonschema:"omit partitions smaller than this many MiB; defaults to DISK_MIN_TOTAL_MB"`
+	ExactBytes     bool   `json:"exact_bytes,omitempty" jsonschema:"show exact byte counts with thousands separators instead of IEC units (text and markdown)"`
+	Mountpoint     string `json:"mountpoint,omitempty" jsonschema:"report only this mountpoint, read directly without listing every partition"`
+}
+
+// validate rejects unknown formats and a negative min_total_mb.
+func (in diskUsageInput) validate() error {
+	switch in.Format {
+	case "", "text", "json", "markdown":
+	default:
+		return fmt.Errorf("%w: unsupported format %q: must be text, json or markdown", errInvalidInput, in.Format)
+	}
+	if in.MinTotalMB != nil && *in.MinTotalMB < 0 {
+		return fmt.Errorf("%w: invalid min_total_mb %d: must not be negative", errInvalidInput, *in.MinTotalMB)
+	}
+	return nil
+}
+
+// options resolves the input against the configured DISK_MIN_TOTAL_MB.
+func (in diskUsageInput) options(minTotalMB int) diskReportOptions {
+	if in.MinTotalMB != nil {
+		minTotalMB = *in.MinTotalMB
+	}
+	return diskReportOptions{ShowDevice: in.ShowDevice, KeepDuplicates: in.KeepDuplicates, MinTotalMB: minTotalMB, ExactBytes: in.ExactBytes, Mountpoint: in.Mountpoint}
+}
+
+// diskUsageReport renders the disk usage report in the requested format.
+func diskUsageReport(format string, opts diskReportOptions) (string, error) {
+	if opts.MinTotalMB < 0 {
+		return "", fmt.Errorf("%w: invalid min_total_mb %d: must not be negative", errInvalidInput, opts.MinTotalMB)
+	}
+	switch format {
+	case "", "text":
+		if opts.Mountpoint != "" {
+			parts, err := reportPartitions(opts)
+			if err != nil {
+				return "", err
+			}
+			return formatDiskUsage(parts, opts), nil
+		}
+		return collectDiskUsage(opts), nil
+	case "json":
+		return collectDiskUsageJSON(opts)
+	case "markdown":
+		return collectDiskUsageMarkdown(opts)
+	default:
+		return "", fmt.Errorf("%w: unsupported format %q: must be text, json or markdown", errInvalidInput, format)
+	}
+}
+
+// serveDiskReport serves the disk usage report over HTTP. The format,
+// show_device, keep_duplicates, min_total_mb, exact_bytes and mountpoint query
+// parameters mirror the disk_usage tool input; minTotalMB is the configured
+// DISK_MIN_TOTAL_MB.
+func serveDiskReport(w http.ResponseWriter, r *http.Request, minTotalMB int) {
+	format := r.URL.Query().Get("format")
+	opts := diskReportOptions{MinTotalMB: minTotalMB}
+	opts.ShowDevice, _ = strconv.ParseBool(r.URL.Query().Get("show_device"))
+	opts.KeepDuplicates, _ = strconv.ParseBool(r.URL.Query().Get("keep_duplicates"))
+	opts.ExactBytes, _ = strconv.ParseBool(r.URL.Query().Get("exact_bytes"))
+	opts.Mountpoint = r.URL.Query().Get("mountpoint")
+	if v := r.URL.Query().Get("min_total_mb"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid min_total_mb %q", v), http.StatusBadRequest)
+			return
+		}
+		opts.MinTotalMB = n
+	}
+	report, err := diskUsageReport(format, opts)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, errInvalidInput) {
+			status = http.StatusBadRequest
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	switch format {
+	case "json":
+		w.Header().Set("Content-Type", "application/json")
+	case "markdown":
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	default:
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	}
+	w.Write([]byte(report))
+}
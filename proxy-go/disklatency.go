@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultSlowMountThreshold flags a mount whose probe took longer.
+	defaultSlowMountThreshold = 200 * time.Millisecond
+	// defaultMountProbeTimeout bounds each probe, so one hung mount cannot
+	// hold up the whole report.
+	defaultMountProbeTimeout = 2 * time.Second
+	// maxMountProbeTimeout caps the timeout_ms input.
+	maxMountProbeTimeout = 30 * time.Second
+)
+
+// diskLatencyInput is the disk_latency tool input.
+type diskLatencyInput struct {
+	ThresholdMS int `json:"threshold_ms,omitempty" jsonschema:"flag mounts whose probe takes longer than this many milliseconds (default 200)"`
+	TimeoutMS   int `json:"timeout_ms,omitempty" jsonschema:"give up on a mount after this many milliseconds (default 2000, max 30000)"`
+}
+
+// validate rejects negative values and a timeout above maxMountProbeTimeout.
+func (in diskLatencyInput) validate() error {
+	if in.ThresholdMS < 0 || in.TimeoutMS < 0 {
+		return fmt.Errorf("%w: threshold_ms and timeout_ms must not be negative", errInvalidInput)
+	}
+	if int64(in.TimeoutMS) > maxMountProbeTimeout.Milliseconds() {
+		return fmt.Errorf("%w: timeout_ms %d exceeds %d", errInvalidInput, in.TimeoutMS, maxMountProbeTimeout.Milliseconds())
+	}
+	return nil
+}
+
+// durations resolves validated input to the slow threshold and probe timeout.
+func (in diskLatencyInput) durations() (threshold, timeout time.Duration) {
+	threshold, timeout = defaultSlowMountThreshold, defaultMountProbeTimeout
+	if in.ThresholdMS > 0 {
+		threshold = time.Duration(in.ThresholdMS) * time.Millisecond
+	}
+	if in.TimeoutMS > 0 {
+		timeout = time.Duration(in.TimeoutMS) * time.Millisecond
+	}
+	return threshold, timeout
+}
+
+// mountLatency is the outcome of probing a single mount. TimedOut is set when
+// the probe did not return within the timeout; Duration is then the timeout.
+type mountLatency struct {
+	Mountpoint string
+	Fstype     string
+	Duration   time.Duration
+	TimedOut   bool
+	Err        error
+}
+
+// probeMount times a single usage call against mountpoint. The call runs in
+// its own goroutine: a stat on a hung network mount cannot be interrupted, so
+// on timeout it is abandoned and left to finish in the background.
+func probeMount(mountpoint string, timeout time.Duration) mountLatency {
+	done := make(chan error, 1)
+	start := time.Now()
+	go func() {
+		_, err := statUsage(mountpoint)
+		done <- err
+	}()
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+	select {
+	case err := <-done:
+		return mountLatency{Mountpoint: mountpoint, Duration: time.Since(start), Err: err}
+	case <-t.C:
+		return mountLatency{Mountpoint: mountpoint, Duration: timeout, TimedOut: true}
+	}
+}
+
+// collectDiskLatency probes every mount, network and virtual ones included,
+// concurrently, so the report takes at most about one timeout.
+func collectDiskLatency(ctx context.Context, threshold, timeout time.Duration) (string, error) {
+	parts, err := listPartitions(true)
+	if err != nil {
+		return "", err
+	}
+	results := make([]mountLatency, len(parts))
+	done := make(chan struct{}, len(parts))
+	for i, p := range parts {
+		go func() {
+			results[i] = probeMount(p.Mountpoint, timeout)
+			results[i].Fstype = p.Fstype
+			done <- struct{}{}
+		}()
+	}
+	for range parts {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+	return formatDiskLatency(results, threshold), nil
+}
+
+// formatDiskLatency renders the probe results, flagging slow and hung mounts.
+func formatDiskLatency(results []mountLatency, threshold time.Duration) string {
+	var sb strings.Builder
+	sb.WriteString("Disk Latency Report\n")
+	sb.WriteString("===================\n\n")
+	slow := 0
+	for _, r := range results {
+		flag := ""
+		switch {
+		case r.TimedOut:
+			flag = "  TIMEOUT"
+			slow++
+		case r.Err != nil:
+			flag = "  ERROR: " + r.Err.Error()
+		case r.Duration > threshold:
+			flag = "  SLOW"
+			slow++
+		}
+		sb.WriteString(fmt.Sprintf("%-30s %-10s %12s%s\n", r.Mountpoint, r.Fstype, r.Duration.Round(time.Microsecond), flag))
+	}
+	if len(results) == 0 {
+		sb.WriteString(noPartitionsNote)
+	}
+	sb.WriteString(fmt.Sprintf("\n%d of %d mounts slower than %s\n", slow, len(results), threshold))
+	return sb.String()
+}
@@ -0,0 +1,19 @@
+//go:build !windows
+
+package main
+
+import "os"
+
+// resolvConfPath is a variable so tests can point it at a fixture.
+var resolvConfPath = "/etc/resolv.conf"
+
+// readDNSConfig parses the system resolv.conf. It also returns the file it
+// read, for the report.
+func readDNSConfig() (dnsConfig, string, error) {
+	f, err := os.Open(resolvConfPath)
+	if err != nil {
+		return dnsConfig{}, resolvConfPath, err
+	}
+	defer f.Close()
+	return parseResolvConf(f), resolvConfPath, nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// defaultEnvFile is loaded when ENV_FILE is unset, if it exists.
+const defaultEnvFile = ".env"
+
+var envFileKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// loadEnvFile sets environment variables from the file named by ENV_FILE, or
+// from ./.env when ENV_FILE is unset and the file exists. Variables already
+// set in the real environment are never overridden, so where the environment
+// is configured directly this is a no-op. An empty ENV_FILE disables loading.
+func loadEnvFile() error {
+	path, explicit := os.LookupEnv("ENV_FILE")
+	if !explicit {
+		path = defaultEnvFile
+	} else if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) && !explicit {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("invalid ENV_FILE: %w", err)
+	}
+	vars, err := parseEnvFile(string(data))
+	if err != nil {
+		return fmt.Errorf("invalid ENV_FILE %q: %w", path, err)
+	}
+	for key, value := range vars {
+		if _, set := os.LookupEnv(key); !set {
+			os.Setenv(key, value)
+		}
+	}
+	return nil
+}
+
+// parseEnvFile parses KEY=VALUE lines. Blank lines and lines starting with #
+// are skipped, an "export " prefix is allowed, and a value wrapped in matching
+// single or double quotes is taken verbatim without them. A repeated key keeps
+// its last value.
+func parseEnvFile(data string) (map[string]string, error) {
+	vars := make(map[string]string)
+	for i, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || !envFileKey.MatchString(key) {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", i+1)
+		}
+		value = strings.TrimSpace(value)
+		if n := len(value); n >= 2 && (value[0] == '"' || value[0] == '\'') && value[n-1] == value[0] {
+			value = value[1 : n-1]
+		}
+		vars[key] = value
+	}
+	return vars, nil
+}
@@ -0,0 +1,11 @@
+package main
+
+// Exit codes. Every binary in this repository uses the same contract so
+// scripts and CI can tell failures apart without parsing logs.
+const (
+	exitOK         = 0
+	exitFailure    = 1 // anything not covered below, e.g. a listener failing
+	exitConfig     = 2 // invalid configuration or command-line usage
+	exitAuth       = 3 // missing, invalid or mismatched credentials
+	exitCollection = 4 // a requested report could not be collected
+)
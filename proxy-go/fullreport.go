@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/host"
+	"github.com/shirou/gopsutil/v3/mem"
+)
+
+// fullReportSections are the sections full_report can include, in output
+// order. Processes are opt-in since walking the process table is the
+// slowest collector.
+var fullReportSections = []string{"host", "cpu", "memory", "network", "disk", "processes"}
+
+// fullReportInput is the full_report tool input.
+type fullReportInput struct {
+	Sections    []string `json:"sections,omitempty" jsonschema:"sections to include: host, cpu, memory, network, disk, processes (default all but processes)"`
+	IncludeIdle bool     `json:"include_idle,omitempty" jsonschema:"also list network interfaces with no RX or TX traffic"`
+}
+
+// validate rejects unknown section names.
+func (in fullReportInput) validate() error {
+	for _, s := range in.Sections {
+		if !slices.Contains(fullReportSections, s) {
+			return fmt.Errorf("%w: unknown section %q: must be one of %s", errInvalidInput, s, strings.Join(fullReportSections, ", "))
+		}
+	}
+	return nil
+}
+
+// sections returns the requested sections, defaulting to all but processes.
+func (in fullReportInput) sections() []string {
+	if len(in.Sections) == 0 {
+		return fullReportSections[:len(fullReportSections)-1]
+	}
+	return in.Sections
+}
+
+// fullReportOptions carries the settings the individual collectors need.
+type fullReportOptions struct {
+	IncludeIdle     bool
+	Interfaces      interfaceFilter
+	Disk            diskReportOptions
+	ProcessWorkers  int
+	ProcessDeadline time.Duration
+}
+
+// memoryReport is the structured form of the report's memory section.
+type memoryReport struct {
+	Total     uint64 `json:"total"`
+	Used      uint64 `json:"used"`
+	SwapTotal uint64 `json:"swap_total"`
+	SwapUsed  uint64 `json:"swap_used"`
+}
+
+// fullReport is the full_report result: every requested section in one
+// object. A section that failed is left out and its error recorded under
+// the section name.
+type fullReport struct {
+	Host      *hostReport        `json:"host,omitempty"`
+	CPU       *cpuReport         `json:"cpu,omitempty"`
+	Memory    *memoryReport      `json:"memory,omitempty"`
+	Network   []networkInterface `json:"network,omitempty"`
+	Disk      []partitionUsage   `json:"disk,omitempty"`
+	Processes []processInfo      `json:"processes,omitempty"`
+	Errors    map[string]string  `json:"errors,omitempty"`
+}
+
+// collectFullReport gathers the requested sections concurrently and renders
+// them as a single JSON object.
+func collectFullReport(ctx context.Context, sections []string, opts fullReportOptions) (string, error) {
+	var report fullReport
+	var mu sync.Mutex
+	fail := func(section string, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if report.Errors == nil {
+			report.Errors = make(map[string]string)
+		}
+		report.Errors[section] = err.Error()
+	}
+
+	var wg sync.WaitGroup
+	for _, section := range sections {
+		wg.Go(func() {
+			switch section {
+			case "host":
+				h, err := host.Info()
+				if err != nil {
+					fail(section, err)
+					return
+				}
+				report.Host = &hostReport{
+					SystemName: runtime.GOOS,
+					OSName:     h.OS,
+					HostName:   h.Hostname,
+					BootTime:   time.Unix(int64(h.BootTime), 0).UTC(),
+					ServerTime: time.Now().UTC(),
+				}
+			case "cpu":
+				cores, err := cpu.Counts(true)
+				if err != nil {
+					fail(section, err)
+					return
+				}
+				c := &cpuReport{Cores: cores}
+				if vcpu, ok := cgroupCPULimit(); ok {
+					c.AllocatedVCPU = vcpu
+				}
+				report.CPU = c
+			case "memory":
+				v, err := mem.VirtualMemory()
+				if err != nil {
+					fail(section, err)
+					return
+				}
+				m := &memoryReport{Total: v.Total, Used: v.Used}
+				if s, err := mem.SwapMemory(); err == nil {
+					m.SwapTotal, m.SwapUsed = s.Total, s.Used
+				}
+				report.Memory = m
+			case "network":
+				interfaces, err := collectInterfaces(gopsutilSource{})
+				if err != nil {
+					fail(section, err)
+					return
+				}
+				report.Network = slices.DeleteFunc(interfaces, func(n networkInterface) bool {
+					return !opts.Interfaces.matches(n.Name) || (!opts.IncludeIdle && n.isIdle())
+				})
+			case "disk":
+				parts, err := reportPartitions(opts.Disk)
+				if err != nil {
+					fail(section, err)
+					return
+				}
+				report.Disk = parts
+			case "processes":
+				ctx, cancel := context.WithTimeout(ctx, opts.ProcessDeadline)
+				defer cancel()
+				procs, _, err := collectProcesses(ctx, opts.ProcessWorkers)
+				if err != nil && procs == nil {
+					fail(section, err)
+					return
+				}
+				sort.Slice(procs, func(i, j int) bool { return procs[i].RSS > procs[j].RSS })
+				report.Processes = procs[:min(topProcessCount, len(procs))]
+			}
+		})
+	}
+	wg.Wait()
+
+	out, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(out) + "\n", nil
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestFullReportInput(t *testing.T) {
+	if got := (fullReportInput{}).sections(); slices.Contains(got, "processes") || len(got) != len(fullReportSections)-1 {
+		t.Errorf("Expected every section but processes by default, got %v", got)
+	}
+	if err := (fullReportInput{Sections: []string{"disk", "processes"}}).validate(); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if err := (fullReportInput{Sections: []string{"gpu"}}).validate(); !errors.Is(err, errInvalidInput) {
+		t.Errorf("Expected unknown section to be invalid input, got: %v", err)
+	}
+}
+
+func TestCollectFullReportSections(t *testing.T) {
+	out, err := collectFullReport(context.Background(), []string{"cpu", "memory"}, fullReportOptions{})
+	if err != nil {
+		t.Fatalf("collectFullReport failed: %v", err)
+	}
+	var report map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		t.Fatalf("Expected a single JSON object, got %v:\n%s", err, out)
+	}
+	if _, ok := report["cpu"]; !ok {
+		t.Errorf("Expected a cpu section:\n%s", out)
+	}
+	for _, section := range []string{"host", "network", "disk", "processes"} {
+		if _, ok := report[section]; ok {
+			t.Errorf("Section %q was not requested:\n%s", section, out)
+		}
+	}
+}
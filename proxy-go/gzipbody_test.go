@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func gzipped(t *testing.T, s string) []byte {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(s)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestWithGzipRequest(t *testing.T) {
+	var got string
+	h := withGzipRequest(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got = string(body)
+		if r.Header.Get("Content-Encoding") != "" || r.ContentLength != int64(len(body)) {
+			t.Errorf("Expected the encoding header dropped and the length updated, got %q and %d", r.Header.Get("Content-Encoding"), r.ContentLength)
+		}
+	}), 64)
+
+	send := func(body []byte, encoding string) int {
+		req := httptest.NewRequest(http.MethodPost, "/mcp", bytes.NewReader(body))
+		if encoding != "" {
+			req.Header.Set("Content-Encoding", encoding)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	const call = `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`
+	if code := send(gzipped(t, call), "gzip"); code != http.StatusOK || got != call {
+		t.Errorf("Expected the body inflated, got %d and %q", code, got)
+	}
+	if code := send([]byte(call), ""); code != http.StatusOK || got != call {
+		t.Errorf("Expected a plain body to pass through, got %d and %q", code, got)
+	}
+	if code := send([]byte("not gzip"), "gzip"); code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a malformed body, got %d", code)
+	}
+	if code := send(gzipped(t, call)[:20], "GZIP"); code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a truncated body, got %d", code)
+	}
+	if code := send(gzipped(t, strings.Repeat("a", 65)), "gzip"); code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected 413 past the limit, got %d", code)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServeHealthProbe(t *testing.T) {
+	rec := httptest.NewRecorder()
+	serveHealthProbe(rec, httptest.NewRequest(http.MethodGet, "/healthz/probe", nil), time.Second)
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected 200 from a working probe, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	orig := healthProbe
+	defer func() { healthProbe = orig }()
+	healthProbe = func(ctx context.Context) error { return errors.New("open /proc/stat: permission denied") }
+
+	rec = httptest.NewRecorder()
+	serveHealthProbe(rec, httptest.NewRequest(http.MethodGet, "/healthz/probe", nil), time.Second)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 from a failing probe, got %d", rec.Code)
+	}
+}
+
+func TestServeHealthProbeTimeout(t *testing.T) {
+	orig := healthProbe
+	defer func() { healthProbe = orig }()
+	release := make(chan struct{})
+	defer close(release)
+	healthProbe = func(ctx context.Context) error {
+		<-release // ignores ctx, like a read stuck in the kernel
+		return nil
+	}
+
+	start := time.Now()
+	rec := httptest.NewRecorder()
+	serveHealthProbe(rec, httptest.NewRequest(http.MethodGet, "/healthz/probe", nil), 20*time.Millisecond)
+	if rec.Code != http.StatusServiceUnavailable || !strings.Contains(rec.Body.String(), "timed out after 20ms") {
+		t.Errorf("Expected 503 for a hung probe, got %d %q", rec.Code, rec.Body.String())
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the probe to give up at its timeout, took %s", elapsed)
+	}
+	if c := metricsCheck(20 * time.Millisecond)(context.Background()); c.Status != healthFail {
+		t.Errorf("Expected the metrics check to fail on a hung probe, got %+v", c)
+	}
+}
+
+func TestHealthzDiffersFromLivez(t *testing.T) {
+	check := func(name string, serve func(*httptest.ResponseRecorder), code int, body string) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		serve(rec)
+		if rec.Code != code || strings.TrimSpace(rec.Body.String()) != body {
+			t.Errorf("%s: expected %d %q, got %d %q", name, code, body, rec.Code, rec.Body.String())
+		}
+	}
+	livez := func(rec *httptest.ResponseRecorder) { serveLive(rec) }
+	healthz := func(rec *httptest.ResponseRecorder) {
+		serveHealth(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	}
+
+	check("/livez", livez, http.StatusOK, "OK")
+	check("/healthz", healthz, http.StatusOK, "OK")
+
+	shuttingDown.Store(true)
+	defer shuttingDown.Store(false)
+	check("/livez during shutdown", livez, http.StatusOK, "OK")
+	check("/healthz during shutdown", healthz, http.StatusServiceUnavailable, "Service Unavailable: shutting down")
+}
+
+func TestServeHealthVerbose(t *testing.T) {
+	ok := func(context.Context) healthCheck { return healthCheck{Name: "a", Status: healthOK} }
+	warn := func(context.Context) healthCheck { return healthCheck{Name: "b", Status: healthWarn, Detail: "slow"} }
+	fail := func(context.Context) healthCheck { return healthCheck{Name: "c", Status: healthFail} }
+
+	rec := httptest.NewRecorder()
+	serveHealth(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil), fail)
+	if rec.Code != http.StatusOK || rec.Body.String() != "OK" {
+		t.Errorf("Expected plain OK without verbose, got %d %q", rec.Code, rec.Body.String())
+	}
+
+	tests := []struct {
+		target string
+		accept string
+		checks []func(context.Context) healthCheck
+		code   int
+		status string
+	}{
+		{"/healthz?verbose=1", "", []func(context.Context) healthCheck{ok, warn}, http.StatusOK, healthWarn},
+		{"/healthz?verbose=true", "", []func(context.Context) healthCheck{ok}, http.StatusOK, healthOK},
+		{"/healthz", "application/json", []func(context.Context) healthCheck{ok}, http.StatusOK, healthOK},
+		{"/healthz?verbose=1", "", []func(context.Context) healthCheck{warn, fail, ok}, http.StatusServiceUnavailable, healthFail},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+		req.Header.Set("Accept", tt.accept)
+		rec := httptest.NewRecorder()
+		serveHealth(rec, req, tt.checks...)
+		var report healthReport
+		if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+			t.Fatalf("%s: %v: %s", tt.target, err, rec.Body.String())
+		}
+		if rec.Code != tt.code || report.Status != tt.status || len(report.Checks) != len(tt.checks) {
+			t.Errorf("%s: expected %d %s, got %d %+v", tt.target, tt.code, tt.status, rec.Code, report)
+		}
+	}
+}
+
+func TestDiskCheck(t *testing.T) {
+	if check := diskCheck(100, 100, 0)(context.Background()); check.Status != healthOK {
+		t.Errorf("Expected no partition at 100%%, got %+v", check)
+	}
+}
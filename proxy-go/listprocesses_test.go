@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestListProcesses(t *testing.T) {
+	orig := listProcessDetails
+	defer func() { listProcessDetails = orig }()
+
+	detail := func(pid int32, name string, rss uint64, cpu float64) processDetail {
+		return processDetail{processInfo: processInfo{PID: pid, Name: name, RSS: rss}, CPU: cpu, FDs: -1}
+	}
+	listProcessDetails = func(context.Context, int) ([]processDetail, int, error) {
+		return []processDetail{
+			detail(30, "worker", 512<<20, 2.5),
+			detail(1, "init", 8<<20, 0.1),
+			detail(12, "server", 2<<30, 40),
+			detail(7, "idle", 8<<20, 0),
+		}, 4, nil
+	}
+	order := func(out string) []string {
+		var names []string
+		for _, line := range strings.Split(out, "\n") {
+			if f := strings.Fields(line); len(f) == 5 && f[0] != "PID" {
+				names = append(names, f[1])
+			}
+		}
+		return names
+	}
+
+	for _, tt := range []struct {
+		sortBy string
+		limit  int
+		want   string
+	}{
+		{"", 0, "server worker init idle"},
+		{"mem", 2, "server worker"},
+		{"cpu", 0, "server worker init idle"},
+		{"pid", 3, "init idle server"},
+	} {
+		out := listProcesses(context.Background(), 4, tt.sortBy, tt.limit)
+		if got := strings.Join(order(out), " "); got != tt.want {
+			t.Errorf("sort_by %q limit %d: got %q, want %q in:\n%s", tt.sortBy, tt.limit, got, tt.want, out)
+		}
+	}
+	out := listProcesses(context.Background(), 4, "mem", 2)
+	if !strings.Contains(out, "showing 2 of 4 processes") || !strings.Contains(out, "2.0 GiB") || strings.Contains(out, "Note:") {
+		t.Errorf("Unexpected report:\n%s", out)
+	}
+
+	var gotWorkers int
+	listProcessDetails = func(ctx context.Context, workers int) ([]processDetail, int, error) {
+		gotWorkers = workers
+		return []processDetail{detail(1, "init", 8<<20, 0.1)}, 3, context.DeadlineExceeded
+	}
+	out = listProcesses(context.Background(), 8, "", 0)
+	if gotWorkers != 8 {
+		t.Errorf("Expected PROCESS_WORKERS to reach the pool, got %d", gotWorkers)
+	}
+	if !strings.Contains(out, "showing 1 of 1 processes") || !strings.Contains(out, "Note: collection stopped early (context deadline exceeded); inspected 1 of 3 processes") {
+		t.Errorf("Expected a truncated listing to say so, got:\n%s", out)
+	}
+
+	listProcessDetails = func(context.Context, int) ([]processDetail, int, error) { return nil, 0, errors.New("no /proc") }
+	if out := listProcesses(context.Background(), 4, "", 0); !strings.Contains(out, "Error retrieving processes: no /proc") {
+		t.Errorf("Expected an error line, got:\n%s", out)
+	}
+}
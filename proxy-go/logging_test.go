@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestClientLogHandlerForwardsToSession(t *testing.T) {
+	ctx := context.Background()
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	clientLogs := newClientLogHandler("test", slog.LevelInfo)
+	clientLogs.attach(server)
+
+	received := make(chan *mcp.LoggingMessageParams, 1)
+	client := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "1.0.0"}, &mcp.ClientOptions{
+		LoggingMessageHandler: func(_ context.Context, req *mcp.LoggingMessageRequest) {
+			received <- req.Params
+		},
+	})
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	if _, err := server.Connect(ctx, serverTransport, nil); err != nil {
+		t.Fatalf("server connect failed: %v", err)
+	}
+	cs, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("client connect failed: %v", err)
+	}
+	defer cs.Close()
+	if err := cs.SetLoggingLevel(ctx, &mcp.SetLoggingLevelParams{Level: "info"}); err != nil {
+		t.Fatalf("SetLoggingLevel failed: %v", err)
+	}
+
+	logger := slog.New(clientLogs)
+	logger.Debug("below threshold")
+	logger.Info("hello client", "k", "v")
+
+	select {
+	case params := <-received:
+		if params.Level != "info" || params.Logger != "test" {
+			t.Errorf("Expected info message from logger 'test', got: %+v", params)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected a logging notification, got none")
+	}
+}
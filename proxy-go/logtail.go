@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	// defaultLogTailLines is the number of lines recent_logs returns when the
+	// caller does not ask for a count; maxLogTailLines caps what it may ask for.
+	defaultLogTailLines = 50
+	maxLogTailLines     = 1000
+
+	// maxLogTailBytes bounds how much of the end of the file is read, so a log
+	// with very long lines cannot make a call read the whole file.
+	maxLogTailBytes = 1 << 20
+)
+
+// allowedLogFiles are the only files LOG_TAIL_FILE may name. The tool never
+// takes a path from the client, and the configured path must match one of
+// these exactly, so it cannot be used to read arbitrary files.
+var allowedLogFiles = []string{
+	"/var/log/syslog",
+	"/var/log/messages",
+	"/var/log/kern.log",
+	"/var/log/daemon.log",
+}
+
+// recentLogsInput is the recent_logs tool input.
+type recentLogsInput struct {
+	Lines int `json:"lines,omitempty" jsonschema:"number of lines to return from the end of the log (default 50, max 1000)"`
+}
+
+// validate rejects a lines count outside 0..maxLogTailLines.
+func (in recentLogsInput) validate() error {
+	if in.Lines < 0 || in.Lines > maxLogTailLines {
+		return fmt.Errorf("%w: lines %d must be between 0 and %d", errInvalidInput, in.Lines, maxLogTailLines)
+	}
+	return nil
+}
+
+// validateLogTailFile checks that path is allow-listed.
+func validateLogTailFile(path string) error {
+	if filepath.Clean(path) != path || !slices.Contains(allowedLogFiles, path) {
+		return fmt.Errorf("invalid LOG_TAIL_FILE %q: must be one of %s", path, strings.Join(allowedLogFiles, ", "))
+	}
+	return nil
+}
+
+// defaultLogTailFile is /var/log/syslog, or /var/log/messages on hosts that
+// have only that.
+func defaultLogTailFile() string {
+	if _, err := os.Stat("/var/log/syslog"); err != nil {
+		if _, err := os.Stat("/var/log/messages"); err == nil {
+			return "/var/log/messages"
+		}
+	}
+	return "/var/log/syslog"
+}
+
+// tailLines returns up to n lines from the end of the file at path.
+func tailLines(path string, n int) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if !fi.Mode().IsRegular() {
+		return nil, fmt.Errorf("%s is not a regular file", path)
+	}
+	offset := max(fi.Size()-maxLogTailBytes, 0)
+	buf := make([]byte, fi.Size()-offset)
+	if _, err := f.ReadAt(buf, offset); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	buf = bytes.TrimRight(buf, "\n")
+	if len(buf) == 0 {
+		return nil, nil
+	}
+	lines := strings.Split(string(buf), "\n")
+	if offset > 0 {
+		// The first line was cut by the read window
+		lines = lines[1:]
+	}
+	return lines[max(len(lines)-n, 0):], nil
+}
+
+// recentLogsReport renders the last lines of the configured log file.
+// Missing files and permission errors are reported as plain errors.
+func recentLogsReport(path string, n int) (string, error) {
+	switch {
+	case n < 0 || n > maxLogTailLines:
+		return "", fmt.Errorf("%w: lines %d must be between 0 and %d", errInvalidInput, n, maxLogTailLines)
+	case n == 0:
+		n = defaultLogTailLines
+	}
+
+	lines, err := tailLines(path, n)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return "", fmt.Errorf("log file %s does not exist on this host", path)
+	case errors.Is(err, fs.ErrPermission):
+		return "", fmt.Errorf("permission denied reading %s", path)
+	case err != nil:
+		return "", fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Recent Logs\n")
+	sb.WriteString("===========\n\n")
+	sb.WriteString(fmt.Sprintf("File: %s (last %d lines)\n\n", path, len(lines)))
+	for _, line := range lines {
+		sb.WriteString(line + "\n")
+	}
+	return sb.String(), nil
+}
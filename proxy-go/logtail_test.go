@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateLogTailFile(t *testing.T) {
+	if err := validateLogTailFile("/var/log/syslog"); err != nil {
+		t.Errorf("Expected /var/log/syslog to be allowed, got: %v", err)
+	}
+	for _, path := range []string{"/etc/shadow", "/var/log/../../etc/shadow", "var/log/syslog", "/var/log/syslog/"} {
+		if err := validateLogTailFile(path); err == nil {
+			t.Errorf("Expected %q to be rejected", path)
+		}
+	}
+}
+
+func TestRecentLogsReport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "syslog")
+	var content strings.Builder
+	for i := 1; i <= 10; i++ {
+		fmt.Fprintf(&content, "line %d\n", i)
+	}
+	if err := os.WriteFile(path, []byte(content.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := recentLogsReport(path, 3)
+	if err != nil {
+		t.Fatalf("recentLogsReport returned error: %v", err)
+	}
+	if !strings.HasSuffix(out, "line 8\nline 9\nline 10\n") || strings.Contains(out, "line 7\n") {
+		t.Errorf("Expected the last 3 lines, got: %s", out)
+	}
+
+	_, err = recentLogsReport(filepath.Join(t.TempDir(), "missing"), 3)
+	if err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("Expected a missing-file error, got: %v", err)
+	}
+}
@@ -1,20 +1,86 @@
 package main
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
 	"strings"
 	"testing"
 )
 
 func TestCollectDiskUsage(t *testing.T) {
-	output := collectDiskUsage()
+	output := collectDiskUsage(diskReportOptions{})
 	if !strings.Contains(output, "Disk Usage Report") {
 		t.Errorf("Expected output to contain 'Disk Usage Report', got: %s", output)
 	}
 }
 
 func TestCollectSystemInfo(t *testing.T) {
-	output := collectSystemInfo()
+	output := collectSystemInfo(context.Background(), false, systemInfoOptions{})
 	if !strings.Contains(output, "System Information Report") {
 		t.Errorf("Expected output to contain 'System Information Report', got: %s", output)
 	}
 }
+
+// proxy-go has no auth of its own (IAP sits in front of it), so this only
+// guards that the health response is limited to the health paths.
+func TestHealthResponseLimitedToHealthPaths(t *testing.T) {
+	handler, _ := newHandler(&Config{AuthMode: "none"}, nil)
+
+	for _, path := range []string{"/", "/healthz", "/healthz/probe", "/livez", "/readyz"} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK || rec.Body.String() != "OK" {
+			t.Errorf("GET %s: expected 200 OK, got %d %q", path, rec.Code, rec.Body.String())
+		}
+	}
+	for _, path := range []string{"/mcp", "/foo", "/healthz/"} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Body.String() == "OK" {
+			t.Errorf("GET %s: expected the MCP handler, got the health response", path)
+		}
+	}
+}
+
+func TestAdminPortMovesAdminEndpoints(t *testing.T) {
+	public, admin := newHandler(&Config{AuthMode: "none", AdminPort: "9090"}, nil)
+
+	rec := httptest.NewRecorder()
+	public.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/stats", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected /stats to be gone from the public listener, got %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	admin.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/stats", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected /stats on the admin listener, got %d %q", rec.Code, rec.Body.String())
+	}
+	rec = httptest.NewRecorder()
+	admin.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/mcp", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected the admin listener not to serve MCP, got %d", rec.Code)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	for _, command := range []string{"info", "disk", "processes", "check", "config"} {
+		if !strings.Contains(usageText, "\n  "+command+" ") {
+			t.Errorf("Expected usage to list %q", command)
+		}
+	}
+	if !isHelp("--help") || !isHelp("-h") || !isHelp("help") || isHelp("info") {
+		t.Error("Expected help, -h and --help to be recognized as help")
+	}
+}
+
+func TestSystemSectionNames(t *testing.T) {
+	var names []string
+	for _, s := range systemSections(systemInfoOptions{}) {
+		names = append(names, s.name)
+	}
+	if !slices.Equal(names, systemSectionNames) {
+		t.Errorf("systemSectionNames %q does not match systemSections %q", systemSectionNames, names)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// markdownField matches a "Label:   value" line of a text report.
+var markdownField = regexp.MustCompile(`^([^:(][^:]*):\s*(.*)$`)
+
+// markdownReport converts a text report into Markdown. It relies on the
+// layout every text report shares: a title underlined with "=", sections
+// underlined with "-", and "Label: value" lines within a section. Those lines
+// become a two-column table; any other line is kept as a paragraph.
+func markdownReport(text string) string {
+	var sb strings.Builder
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	inSection, inTable := false, false
+	endTable := func() {
+		if inTable {
+			sb.WriteString("\n")
+			inTable = false
+		}
+	}
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimRight(lines[i], " ")
+		if i+1 < len(lines) && line != "" {
+			if underline := lines[i+1]; isUnderline(underline, '=') || isUnderline(underline, '-') {
+				endTable()
+				level := "##"
+				if underline[0] == '=' {
+					level = "#"
+				}
+				sb.WriteString(level + " " + line + "\n\n")
+				inSection = underline[0] == '-'
+				i++
+				continue
+			}
+		}
+		if line == "" {
+			endTable()
+			inSection = false
+			continue
+		}
+		if m := markdownField.FindStringSubmatch(line); inSection && m != nil {
+			if !inTable {
+				sb.WriteString("| Field | Value |\n| --- | --- |\n")
+				inTable = true
+			}
+			sb.WriteString(fmt.Sprintf("| %s | %s |\n", markdownCell(strings.TrimSpace(m[1])), markdownCell(m[2])))
+			continue
+		}
+		endTable()
+		sb.WriteString(line + "\n\n")
+	}
+	endTable()
+	return strings.TrimRight(sb.String(), "\n") + "\n"
+}
+
+// isUnderline reports whether line is a heading underline made of c.
+func isUnderline(line string, c byte) bool {
+	return len(line) >= 3 && strings.Trim(line, string(c)) == ""
+}
+
+// markdownCell escapes text for use in a Markdown table cell.
+func markdownCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+// collectDiskUsageMarkdown renders the partition list as a Markdown table,
+// with the same rows as the text report.
+func collectDiskUsageMarkdown(opts diskReportOptions) (string, error) {
+	parts, err := reportPartitions(opts)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	sb.WriteString("# Disk Usage Report\n\n")
+	if len(parts) == 0 {
+		sb.WriteString(noPartitionsNote)
+		return sb.String(), nil
+	}
+	if opts.ShowDevice {
+		sb.WriteString("| Mountpoint | Device | Fstype | Used | Total | Use% |\n| --- | --- | --- | ---: | ---: | ---: |\n")
+	} else {
+		sb.WriteString("| Mountpoint | Fstype | Used | Total | Use% |\n| --- | --- | ---: | ---: | ---: |\n")
+	}
+	for _, p := range parts {
+		if p.Error != "" {
+			continue
+		}
+		sb.WriteString("| " + markdownCell(p.Mountpoint+p.alsoMountedNote()) + " | ")
+		if opts.ShowDevice {
+			sb.WriteString(markdownCell(p.Device) + " | ")
+		}
+		sb.WriteString(fmt.Sprintf("%s | %s | %s | %.1f%% |\n",
+			markdownCell(p.Fstype), opts.size(p.Used), opts.size(p.Total), p.Percent))
+	}
+	return sb.String(), nil
+}
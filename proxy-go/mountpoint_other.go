@@ -0,0 +1,18 @@
+//go:build !unix
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// isMountpoint reports whether path is a volume root such as C:\, the only
+// mounts recognized without Unix device numbers.
+func isMountpoint(path string) (bool, error) {
+	if _, err := os.Stat(path); err != nil {
+		return false, err
+	}
+	vol := filepath.VolumeName(path)
+	return vol != "" && filepath.Clean(path) == vol+string(filepath.Separator), nil
+}
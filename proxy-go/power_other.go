@@ -0,0 +1,8 @@
+//go:build !linux && !darwin
+
+package main
+
+// readBatteries reports no batteries where there is no supported source.
+func readBatteries() ([]battery, error) {
+	return nil, nil
+}
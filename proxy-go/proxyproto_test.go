@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+)
+
+func proxyV2Header(cmd, family byte, addrs []byte) string {
+	hdr := append([]byte{}, proxyV2Signature...)
+	hdr = append(hdr, 0x20|cmd, family, 0, 0)
+	binary.BigEndian.PutUint16(hdr[14:], uint16(len(addrs)))
+	return string(append(hdr, addrs...))
+}
+
+func TestReadProxyHeader(t *testing.T) {
+	v4 := []byte{203, 0, 113, 7, 10, 0, 0, 5, 0xc8, 0x22, 0x1f, 0x90}
+	v6 := make([]byte, 36)
+	v6[0], v6[1], v6[15] = 0x20, 0x01, 0x01
+	binary.BigEndian.PutUint16(v6[32:], 443)
+
+	cases := []struct {
+		name, in, want string
+		wantErr        bool
+	}{
+		{"v1 tcp4", "PROXY TCP4 203.0.113.7 10.0.0.5 51234 8080\r\nGET", "203.0.113.7:51234", false},
+		{"v1 tcp6", "PROXY TCP6 2001:db8::1 2001:db8::2 4000 443\r\nGET", "[2001:db8::1]:4000", false},
+		{"v1 unknown", "PROXY UNKNOWN\r\nGET", "", false},
+		{"v1 family mismatch", "PROXY TCP4 2001:db8::1 10.0.0.5 1 2\r\n", "", true},
+		{"v1 bad port", "PROXY TCP4 203.0.113.7 10.0.0.5 70000 8080\r\n", "", true},
+		{"v1 no crlf", "PROXY TCP4 203.0.113.7 10.0.0.5 1 2\n", "", true},
+		{"v1 too long", "PROXY TCP4 " + strings.Repeat("1", 120) + "\r\n", "", true},
+		{"v2 tcp4", proxyV2Header(1, 0x11, v4) + "GET", "203.0.113.7:51234", false},
+		{"v2 tcp6 with tlv", proxyV2Header(1, 0x21, append(v6, 0x04, 0x00, 0x01, 0x00)) + "GET", "[2001::1]:443", false},
+		{"v2 local", proxyV2Header(0, 0x00, nil) + "GET", "", false},
+		{"v2 short block", proxyV2Header(1, 0x11, v4[:8]), "", true},
+		{"v2 bad command", proxyV2Header(2, 0x11, v4), "", true},
+		{"missing", "GET / HTTP/1.1\r\nHost: x\r\n\r\n", "", true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := bufio.NewReader(strings.NewReader(c.in))
+			addr, err := readProxyHeader(r)
+			if c.wantErr {
+				if err == nil {
+					t.Fatalf("Expected an error, got %v", addr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := ""; addr != nil {
+				got = addr.String()
+				if got != c.want {
+					t.Errorf("Expected %s, got %s", c.want, got)
+				}
+			} else if c.want != "" {
+				t.Errorf("Expected %s, got no address", c.want)
+			}
+			if rest, _ := io.ReadAll(r); string(rest) != "GET" {
+				t.Errorf("Expected the header to be consumed exactly, %q left", rest)
+			}
+		})
+	}
+}
+
+func TestProxyListener(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer ln.Close()
+	pl := proxyListener{ln}
+
+	// The kernel completes the handshake before Accept, so the client can
+	// write and close up front.
+	send := func(data string) {
+		c, err := net.Dial("tcp", ln.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.Write([]byte(data))
+		c.Close()
+	}
+
+	send("PROXY TCP4 198.51.100.9 10.0.0.5 40000 8080\r\nhello")
+	c, err := pl.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.RemoteAddr().String(); got != "198.51.100.9:40000" {
+		t.Errorf("Expected the client address from the header, got %s", got)
+	}
+	if body, _ := io.ReadAll(c); string(body) != "hello" {
+		t.Errorf("Expected the payload after the header, got %q", body)
+	}
+	c.Close()
+
+	send("hello")
+	c, err = pl.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.ReadAll(c); err == nil {
+		t.Error("Expected a connection without a header to be rejected")
+	}
+	if host, _, _ := net.SplitHostPort(c.RemoteAddr().String()); host != "127.0.0.1" {
+		t.Errorf("Expected the peer address for a rejected connection, got %s", c.RemoteAddr())
+	}
+	c.Close()
+}
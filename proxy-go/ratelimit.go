@@ -0,0 +1,254 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxRateBuckets bounds the per-client bucket map. Once reached, buckets
+// that have refilled completely are dropped since they carry no state, and
+// if none has, the least recently used bucket makes room.
+const maxRateBuckets = 10000
+
+// toolRate allows Calls tool calls per Per, refilled continuously.
+type toolRate struct {
+	Calls int
+	Per   time.Duration
+}
+
+func (r toolRate) String() string {
+	return fmt.Sprintf("%d/%s", r.Calls, r.Per)
+}
+
+// toolRateLimits maps default tool names to their limits. Tools without an
+// entry are not limited.
+type toolRateLimits map[string]toolRate
+
+// parseToolRateLimits reads a TOOL_RATE_LIMITS value, e.g.
+//
+//	top_processes=6/m,disk_latency=1/10s
+//
+// The period is s, m, h or any time.ParseDuration value. Keys are default
+// tool names, so limits follow a tool through TOOLS_CONFIG_FILE renames.
+func parseToolRateLimits(s string) (toolRateLimits, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	limits := toolRateLimits{}
+	for item := range strings.SplitSeq(s, ",") {
+		name, spec, ok := strings.Cut(strings.TrimSpace(item), "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid TOOL_RATE_LIMITS entry %q: want tool=calls/period", item)
+		}
+		name = strings.TrimSpace(name)
+		if !slices.Contains(toolNames, name) {
+			return nil, fmt.Errorf("invalid TOOL_RATE_LIMITS: unknown tool %q", name)
+		}
+		rate, err := parseToolRate(strings.TrimSpace(spec))
+		if err != nil {
+			return nil, fmt.Errorf("invalid TOOL_RATE_LIMITS entry %q: %w", item, err)
+		}
+		limits[name] = rate
+	}
+	return limits, nil
+}
+
+func parseToolRate(spec string) (toolRate, error) {
+	calls, per, ok := strings.Cut(spec, "/")
+	if !ok {
+		return toolRate{}, fmt.Errorf("want calls/period")
+	}
+	n, err := strconv.Atoi(calls)
+	if err != nil || n <= 0 {
+		return toolRate{}, fmt.Errorf("calls must be a positive integer")
+	}
+	var d time.Duration
+	switch per {
+	case "s":
+		d = time.Second
+	case "m":
+		d = time.Minute
+	case "h":
+		d = time.Hour
+	default:
+		if d, err = time.ParseDuration(per); err != nil || d <= 0 {
+			return toolRate{}, fmt.Errorf("period must be s, m, h or a positive duration")
+		}
+	}
+	return toolRate{Calls: n, Per: d}, nil
+}
+
+// String renders limits in toolNames order for the config listing.
+func (l toolRateLimits) String() string {
+	parts := make([]string, 0, len(l))
+	for _, name := range toolNames {
+		if r, ok := l[name]; ok {
+			parts = append(parts, name+"="+r.String())
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// rateBucket is a token bucket holding up to rate.Calls tokens.
+type rateBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// toolRateLimiter keeps one bucket per client and tool, so one client
+// hammering top_processes does not throttle another, while opening a new MCP
+// session does not buy a client a fresh budget.
+type toolRateLimiter struct {
+	limits map[string]toolRate // keyed by registered tool name
+	now    func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*rateBucket
+}
+
+// newToolRateLimiter resolves limits to registered tool names. It returns
+// nil when no limits are configured.
+func newToolRateLimiter(limits toolRateLimits, tc toolsConfig) *toolRateLimiter {
+	if len(limits) == 0 {
+		return nil
+	}
+	l := &toolRateLimiter{
+		limits:  make(map[string]toolRate, len(limits)),
+		now:     time.Now,
+		buckets: make(map[string]*rateBucket),
+	}
+	for key, rate := range limits {
+		l.limits[tc.name(key)] = rate
+	}
+	return l
+}
+
+// allow takes a token for the call and, when none is left, reports how long
+// until one is.
+func (l *toolRateLimiter) allow(client, tool string) (time.Duration, bool) {
+	rate, ok := l.limits[tool]
+	if !ok {
+		return 0, true
+	}
+	perToken := rate.Per / time.Duration(rate.Calls)
+	now := l.now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	key := client + "\x00" + tool
+	b, ok := l.buckets[key]
+	if !ok {
+		if len(l.buckets) >= maxRateBuckets {
+			l.prune(now)
+		}
+		if len(l.buckets) >= maxRateBuckets {
+			l.evictOldest()
+		}
+		b = &rateBucket{tokens: float64(rate.Calls), last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = min(float64(rate.Calls), b.tokens+float64(now.Sub(b.last))/float64(perToken))
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0, true
+	}
+	return time.Duration((1 - b.tokens) * float64(perToken)), false
+}
+
+// prune drops buckets that would be full by now. The caller holds l.mu.
+func (l *toolRateLimiter) prune(now time.Time) {
+	for key, b := range l.buckets {
+		_, tool, _ := strings.Cut(key, "\x00")
+		rate := l.limits[tool]
+		if now.Sub(b.last) >= rate.Per {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// evictOldest drops the least recently used bucket. The caller holds l.mu.
+func (l *toolRateLimiter) evictOldest() {
+	var oldest string
+	var last time.Time
+	for key, b := range l.buckets {
+		if oldest == "" || b.last.Before(last) {
+			oldest, last = key, b.last
+		}
+	}
+	delete(l.buckets, oldest)
+}
+
+// rateClientHeader carries the caller's identity from the HTTP request to
+// the tool rate limiter, which only sees the request headers.
+// withRateClient sets it on every request, so a client cannot choose its
+// own bucket.
+const rateClientHeader = "X-Rate-Limit-Client"
+
+// rateClient identifies the caller of r for tool rate limiting by its
+// remote host. Headers are not used since nothing here verifies them, so a
+// client could pick a fresh bucket with every request.
+func rateClient(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "addr " + host
+}
+
+// withRateClient records rateClient in rateClientHeader before passing the
+// request to the MCP handler h.
+func withRateClient(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Header.Set(rateClientHeader, rateClient(r))
+		h.ServeHTTP(w, r)
+	})
+}
+
+// rateLimitError is the JSON-RPC error for a throttled call. Data carries
+// the wait in milliseconds so clients can back off without parsing text.
+func rateLimitError(tool string, wait time.Duration) error {
+	wait = wait.Round(time.Millisecond)
+	data, _ := json.Marshal(map[string]any{"tool": tool, "retry_after_ms": wait.Milliseconds()})
+	return &jsonrpc.Error{
+		Code:    codeRateLimited,
+		Message: fmt.Sprintf("%v: %s, retry after %s", errRateLimited, tool, wait),
+		Data:    data,
+	}
+}
+
+// toolRateLimitMiddleware rejects tools/call requests over their per-tool
+// limit before the handler runs. A nil limiter passes everything through.
+func toolRateLimitMiddleware(l *toolRateLimiter) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		if l == nil {
+			return next
+		}
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			call, ok := req.(*mcp.CallToolRequest)
+			if !ok || call.Params == nil {
+				return next(ctx, method, req)
+			}
+			var client string
+			if call.Extra != nil && call.Extra.Header != nil {
+				client = call.Extra.Header.Get(rateClientHeader)
+			}
+			if wait, ok := l.allow(client, call.Params.Name); !ok {
+				slog.Warn("Tool call rate limited", "tool", call.Params.Name, "retry_after", wait)
+				return nil, rateLimitError(call.Params.Name, wait)
+			}
+			return next(ctx, method, req)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// sshConnectFailed is the status ssh exits with when it could not connect or
+// authenticate; any other status is the remote command's own.
+const sshConnectFailed = 255
+
+// sshCommandNotFound is the status the remote shell exits with when
+// REMOTE_BINARY is not on its PATH.
+const sshCommandNotFound = 127
+
+// remoteFlags are the report flags forwarded to the remote binary. Anything
+// else, in particular credentials, stays on this host.
+var remoteFlags = map[string]bool{
+	"--json":            true,
+	"--show-device":     true,
+	"--keep-duplicates": true,
+	"--exact-bytes":     true,
+	"--resolve-vendor":  true,
+	"--memory-detail":   true,
+}
+
+// splitRemote removes "--remote user@host" (or "--remote=user@host") from
+// args. It returns the target, empty when the flag is absent, and the report
+// flags to forward to the remote command.
+func splitRemote(args []string) (target string, forward []string, err error) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--remote":
+			if i+1 >= len(args) {
+				return "", nil, errors.New("--remote needs a user@host target")
+			}
+			i++
+			target = args[i]
+		case strings.HasPrefix(arg, "--remote="):
+			target = strings.TrimPrefix(arg, "--remote=")
+		case remoteFlags[arg]:
+			forward = append(forward, arg)
+		}
+	}
+	if target == "" {
+		return "", forward, nil
+	}
+	if strings.HasPrefix(target, "-") || strings.ContainsAny(target, " \t\n") {
+		return "", nil, fmt.Errorf("invalid --remote target %q: expected user@host", target)
+	}
+	return target, forward, nil
+}
+
+// sshArgs builds the ssh argument list that runs binary with args on target.
+// BatchMode makes ssh fail instead of prompting, so only the user's agent and
+// keys are used. The remote command line is parsed by the remote shell, so
+// every word is quoted.
+func sshArgs(target, binary string, args []string) []string {
+	words := []string{shellQuote(binary)}
+	for _, arg := range args {
+		words = append(words, shellQuote(arg))
+	}
+	return []string{"-T", "-o", "BatchMode=yes", "-o", "ConnectTimeout=10", "--", target, strings.Join(words, " ")}
+}
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// runRemote runs binary with args on target over ssh, streaming the remote
+// report and diagnostics to stdout and stderr. It returns the exit code for
+// this process: the remote one when the command ran, otherwise a local code
+// after a message saying what went wrong.
+func runRemote(target, binary string, args []string) int {
+	cmd := exec.Command("ssh", sshArgs(target, binary, args)...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == sshConnectFailed:
+		slog.Error("Could not connect over ssh; check the host and that your ssh agent or keys log in without a password", "target", target)
+		return exitCollection
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == sshCommandNotFound:
+		slog.Error("Remote binary not found; install it on the host or set REMOTE_BINARY to its path", "target", target, "binary", binary)
+		return exitCollection
+	case errors.As(err, &exitErr) && exitErr.ExitCode() > 0:
+		return exitErr.ExitCode()
+	default:
+		slog.Error("Failed to run ssh", "error", err)
+		return exitFailure
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestSplitRemote(t *testing.T) {
+	target, forward, err := splitRemote([]string{"--json", "--remote", "ops@db1", "--key", "secret", "--show-device"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if target != "ops@db1" || !slices.Equal(forward, []string{"--json", "--show-device"}) {
+		t.Errorf("Unexpected split: %q %q", target, forward)
+	}
+	if target, _, _ := splitRemote([]string{"--remote=ops@db2"}); target != "ops@db2" {
+		t.Errorf("Expected ops@db2, got %q", target)
+	}
+	for _, args := range [][]string{{"--remote"}, {"--remote", "-oProxyCommand=x"}, {"--remote=a b"}} {
+		if _, _, err := splitRemote(args); err == nil {
+			t.Errorf("Expected an error for %q", args)
+		}
+	}
+}
+
+func TestSSHArgs(t *testing.T) {
+	got := sshArgs("ops@db1", "/opt/bin/tool", []string{"disk", "--json"})
+	if last := got[len(got)-1]; last != `'/opt/bin/tool' 'disk' '--json'` {
+		t.Errorf("Unexpected remote command %q", last)
+	}
+	if got[len(got)-3] != "--" || got[len(got)-2] != "ops@db1" {
+		t.Errorf("Expected the target after --, got %q", got)
+	}
+	if q := shellQuote("it's"); q != `'it'\''s'` {
+		t.Errorf("Unexpected quoting %s", q)
+	}
+}
+
+func TestRunRemoteExitCodes(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as a fake ssh")
+	}
+	tests := []struct {
+		status int
+		want   int
+	}{
+		{0, exitOK},
+		{sshConnectFailed, exitCollection},
+		{sshCommandNotFound, exitCollection},
+		{exitAuth, exitAuth},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		script := "#!/bin/sh\nexit " + strconv.Itoa(tt.status) + "\n"
+		if err := os.WriteFile(filepath.Join(dir, "ssh"), []byte(script), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		t.Setenv("PATH", dir)
+		if got := runRemote("ops@db1", "tool", []string{"info"}); got != tt.want {
+			t.Errorf("ssh status %d: expected exit %d, got %d", tt.status, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// slowRequestPeekBytes bounds how much of a POST body withRequestLog reads
+// to find the called tool. Larger bodies are logged without one.
+const slowRequestPeekBytes = 64 << 10
+
+// withRequestLog logs one line per request with its method, path, client
+// address, status and duration. Responses with status 400 or above are always
+// logged; other requests are logged with probability sample
+// (REQUEST_LOG_SAMPLE), so busy deployments can cut log volume without losing
+// sight of failures. Requests taking longer than slow
+// (SLOW_REQUEST_THRESHOLD) are also logged at WARN with the tool called, if
+// any, regardless of sampling. Long-lived GET event streams are not slow
+// requests and are left out.
+func withRequestLog(h http.Handler, sample float64, slow time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		tool := peekToolName(r)
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		h.ServeHTTP(rec, r)
+		elapsed := time.Since(start)
+
+		if elapsed > slow && !isEventStream(r) {
+			slog.WarnContext(r.Context(), "Slow request",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"tool", tool,
+				"status", rec.status,
+				"duration_ms", elapsed.Milliseconds(),
+				"threshold_ms", slow.Milliseconds(),
+			)
+		}
+
+		level := slog.LevelInfo
+		if rec.status >= http.StatusBadRequest {
+			level = slog.LevelWarn
+		} else if rand.Float64() >= sample {
+			return
+		}
+		slog.Log(r.Context(), level, "Request completed",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"client", r.RemoteAddr,
+			"status", rec.status,
+			"duration_ms", elapsed.Milliseconds(),
+		)
+	})
+}
+
+// isEventStream reports whether r opens a standalone SSE stream, which stays
+// open for as long as the client listens.
+func isEventStream(r *http.Request) bool {
+	return r.Method == http.MethodGet && strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
+
+// peekToolName returns the tool named by a JSON-RPC tools/call POST body, or
+// "" for any other request, including compressed ones. The body is restored
+// for the handler.
+func peekToolName(r *http.Request) string {
+	if r.Method != http.MethodPost || r.Body == nil || r.Header.Get("Content-Encoding") != "" {
+		return ""
+	}
+	head, err := io.ReadAll(io.LimitReader(r.Body, slowRequestPeekBytes))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(head), r.Body), r.Body}
+	if err != nil {
+		return ""
+	}
+	var msg struct {
+		Method string `json:"method"`
+		Params struct {
+			Name string `json:"name"`
+		} `json:"params"`
+	}
+	if json.Unmarshal(head, &msg) != nil || msg.Method != "tools/call" {
+		return ""
+	}
+	return msg.Params.Name
+}
+
+// statusRecorder captures the response status for withRequestLog. It passes
+// flushes through, since MCP responses may be streamed.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	r.status = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
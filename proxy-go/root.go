@@ -0,0 +1,20 @@
+package main
+
+import (
+	"errors"
+	"log/slog"
+)
+
+// checkRoot enforces ALLOW_ROOT before the server starts. Running as root is
+// allowed by default for compatibility but logged, since any flaw in a tool
+// would then run with full control of the host.
+func checkRoot(allow bool) error {
+	if !runningAsRoot() {
+		return nil
+	}
+	if !allow {
+		return errors.New("running as root (uid 0) is not allowed; run as an unprivileged user or set ALLOW_ROOT=true")
+	}
+	slog.Warn("Running as root; set ALLOW_ROOT=false to refuse to start as uid 0")
+	return nil
+}
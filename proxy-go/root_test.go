@@ -0,0 +1,12 @@
+package main
+
+import "testing"
+
+func TestCheckRoot(t *testing.T) {
+	if err := checkRoot(true); err != nil {
+		t.Errorf("Expected ALLOW_ROOT=true to always pass, got %v", err)
+	}
+	if err := checkRoot(false); (err != nil) != runningAsRoot() {
+		t.Errorf("Expected an error only when running as root (root=%v), got %v", runningAsRoot(), err)
+	}
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// defaultMaxSampleInterval is the MAX_SAMPLE_INTERVAL default.
+const defaultMaxSampleInterval = 5 * time.Second
+
+// checkSampleInterval rejects a sampling window input, in milliseconds, that
+// is negative or longer than limit, the MAX_SAMPLE_INTERVAL setting. Tools
+// that block for the whole window check it so that a single call cannot tie
+// up the server.
+func checkSampleInterval(name string, ms int, limit time.Duration) error {
+	if ms < 0 || int64(ms) > limit.Milliseconds() {
+		return fmt.Errorf("%w: %s %d must be between 0 and %d (MAX_SAMPLE_INTERVAL)", errInvalidInput, name, ms, limit.Milliseconds())
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestShutdownAfterDrainsRequests(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	started := make(chan struct{})
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(100 * time.Millisecond)
+		io.WriteString(w, "done")
+	})}
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve(ln) }()
+
+	type result struct {
+		body string
+		err  error
+	}
+	got := make(chan result, 1)
+	go func() {
+		resp, err := http.Get("http://" + ln.Addr().String())
+		if err != nil {
+			got <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		got <- result{string(body), err}
+	}()
+	<-started
+
+	defer shuttingDown.Store(false)
+	drained := shutdownAfter(time.Millisecond, 0, defaultShutdownGrace, "test", srv)
+	if err := <-served; !errors.Is(err, http.ErrServerClosed) {
+		t.Fatalf("Expected ErrServerClosed, got: %v", err)
+	}
+	<-drained
+	if r := <-got; r.err != nil || r.body != "done" {
+		t.Errorf("Expected in-flight request to complete, got %q, %v", r.body, r.err)
+	}
+}
+
+func TestShutdownDrainDelay(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serveHealth(w, r)
+	})}
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve(ln) }()
+
+	draining := make(chan time.Duration, 1)
+	release := make(chan time.Time)
+	drainTimer = func(d time.Duration) <-chan time.Time {
+		draining <- d
+		return release
+	}
+	defer func() { drainTimer = time.After }()
+	defer shuttingDown.Store(false)
+
+	drained := shutdownAfter(time.Millisecond, 5*time.Second, time.Second, "test", srv)
+	if d := <-draining; d != 5*time.Second {
+		t.Errorf("Expected a 5s drain delay, got %v", d)
+	}
+	// The listener is still up during the delay and health reports 503.
+	resp, err := http.Get("http://" + ln.Addr().String() + "/healthz")
+	if err != nil {
+		t.Fatalf("Expected the server to keep serving during the drain delay: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 during the drain delay, got %d", resp.StatusCode)
+	}
+	select {
+	case err := <-served:
+		t.Fatalf("Expected no shutdown before the drain delay ends, got %v", err)
+	default:
+	}
+
+	close(release)
+	if err := <-served; !errors.Is(err, http.ErrServerClosed) {
+		t.Fatalf("Expected ErrServerClosed, got: %v", err)
+	}
+	<-drained
+}
+
+func TestShutdownOnSignal(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &http.Server{Handler: http.NotFoundHandler()}
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve(ln) }()
+	defer shuttingDown.Store(false)
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	drained := shutdownOnSignal(ctx, 0, time.Second, srv)
+	select {
+	case <-drained:
+		t.Fatal("Expected no shutdown before the signal")
+	case <-time.After(20 * time.Millisecond):
+	}
+	rec := httptest.NewRecorder()
+	serveHealth(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected 200 before shutdown, got %d", rec.Code)
+	}
+
+	cancel(errors.New("terminated signal received"))
+	if err := <-served; !errors.Is(err, http.ErrServerClosed) {
+		t.Fatalf("Expected ErrServerClosed, got: %v", err)
+	}
+	<-drained
+	rec = httptest.NewRecorder()
+	serveHealth(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 from the health check during shutdown, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestReportSigningMiddleware(t *testing.T) {
+	next := func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "report body"}}}, nil
+	}
+	res, err := reportSigningMiddleware("shared")(next)(context.Background(), "tools/call", &mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tc := res.(*mcp.CallToolResult).Content[0].(*mcp.TextContent)
+	sig, _ := tc.Meta[reportSignatureMeta].(string)
+	if !verifyReport(tc.Text, "shared", sig) {
+		t.Errorf("Expected a signature valid under the shared key, got %q", sig)
+	}
+	if verifyReport(tc.Text+"tampered", "shared", sig) || verifyReport(tc.Text, "other", sig) {
+		t.Error("Expected the signature to reject altered text or another key")
+	}
+
+	res, _ = reportSigningMiddleware("")(next)(context.Background(), "tools/call", &mcp.CallToolRequest{})
+	if meta := res.(*mcp.CallToolResult).Content[0].(*mcp.TextContent).Meta; meta != nil {
+		t.Errorf("Expected no signature without a key, got %v", meta)
+	}
+}
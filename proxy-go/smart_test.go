@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+const ataSmartOutput = `=== START OF READ SMART DATA SECTION ===
+SMART overall-health self-assessment test result: PASSED
+
+SMART Attributes Data Structure revision number: 16
+ID# ATTRIBUTE_NAME          FLAG     VALUE WORST THRESH TYPE      UPDATED  WHEN_FAILED RAW_VALUE
+  5 Reallocated_Sector_Ct   0x0033   100   100   010    Pre-fail  Always       -       8
+194 Temperature_Celsius     0x0022   065   052   000    Old_age   Always       -       35 (Min/Max 20/48)
+`
+
+const nvmeSmartOutput = `=== START OF SMART DATA SECTION ===
+SMART overall-health self-assessment test result: FAILED!
+
+SMART/Health Information (NVMe Log 0x02)
+Critical Warning:                   0x04
+Temperature:                        41 Celsius
+`
+
+func TestParseSmartScan(t *testing.T) {
+	devices := parseSmartScan([]byte("/dev/sda -d sat # /dev/sda [SAT], ATA device\n/dev/nvme0 -d nvme # /dev/nvme0, NVMe device\n\n/dev/sdb # no type\n"))
+	want := []smartDevice{{"/dev/sda", "sat"}, {"/dev/nvme0", "nvme"}, {"/dev/sdb", ""}}
+	if fmt.Sprint(devices) != fmt.Sprint(want) {
+		t.Errorf("parseSmartScan = %v, want %v", devices, want)
+	}
+}
+
+func TestParseSmartHealth(t *testing.T) {
+	h, ok := parseSmartHealth([]byte(ataSmartOutput))
+	if !ok || h.Status != "PASSED" || h.Reallocated != "8" || h.Temperature != "35 C" {
+		t.Errorf("Unexpected ATA health %+v, %v", h, ok)
+	}
+	h, ok = parseSmartHealth([]byte(nvmeSmartOutput))
+	if !ok || h.Status != "FAILED!" || h.Reallocated != "" || h.Temperature != "41 C" {
+		t.Errorf("Unexpected NVMe health %+v, %v", h, ok)
+	}
+	h, ok = parseSmartHealth([]byte("SMART Health Status: OK\nCurrent Drive Temperature:     30 C\nElements in grown defect list: 0\n"))
+	if !ok || h.Status != "OK" || h.Reallocated != "0" || h.Temperature != "30 C" {
+		t.Errorf("Unexpected SCSI health %+v, %v", h, ok)
+	}
+	if _, ok := parseSmartHealth([]byte("SMART support is: Unavailable\n")); ok {
+		t.Error("Expected no health without a health line")
+	}
+}
+
+func TestCollectDiskHealth(t *testing.T) {
+	orig := runSmartctl
+	defer func() { runSmartctl = orig }()
+
+	runSmartctl = func(ctx context.Context, args ...string) ([]byte, error) {
+		switch args[len(args)-1] {
+		case "--scan":
+			return []byte("/dev/sda -d sat # ATA\n/dev/sdb -d sat # ATA\n/dev/nvme0 -d nvme # NVMe\n"), nil
+		case "/dev/sda":
+			return []byte(ataSmartOutput), nil
+		case "/dev/sdb":
+			return []byte("Smartctl open device: /dev/sdb failed: Permission denied\n"), nil
+		}
+		return []byte(nvmeSmartOutput), nil
+	}
+	out := collectDiskHealth(context.Background())
+	for _, want := range []string{"/dev/sda", "PASSED", "35 C", "/dev/nvme0", "FAILED!", "1 device(s) skipped", "1 device(s) not reporting healthy"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "/dev/sdb ") {
+		t.Errorf("Expected the unreadable device to be left out:\n%s", out)
+	}
+
+	runSmartctl = func(ctx context.Context, args ...string) ([]byte, error) {
+		return nil, fmt.Errorf("exec: %w", exec.ErrNotFound)
+	}
+	if out := collectDiskHealth(context.Background()); !strings.Contains(out, "smartctl not found") {
+		t.Errorf("Expected the missing smartctl note, got:\n%s", out)
+	}
+}
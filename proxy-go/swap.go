@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/mem"
+)
+
+// maxSwapSample caps the swap_sample_ms input; the memory section blocks for
+// the whole interval.
+const maxSwapSample = 10 * time.Second
+
+// swapWarnRate is the combined swap-in/out rate, in bytes per second, at
+// which the memory section flags active swapping: 256 pages of 4 KiB.
+const swapWarnRate = 1 << 20
+
+// swapCounters returns the cumulative swapped-in and swapped-out byte counts.
+// It is a variable so tests can replace it.
+var swapCounters = func() (in, out uint64, err error) {
+	s, err := mem.SwapMemory()
+	if err != nil {
+		return 0, 0, err
+	}
+	return s.Sin, s.Sout, nil
+}
+
+// swapRate is swap traffic in bytes per second.
+type swapRate struct {
+	In  float64
+	Out float64
+}
+
+// sampleSwapRate reads the swap counters twice, interval apart. Counters that
+// went backwards (a reset) count as no traffic.
+func sampleSwapRate(interval time.Duration) (swapRate, error) {
+	in1, out1, err := swapCounters()
+	if err != nil {
+		return swapRate{}, err
+	}
+	time.Sleep(interval)
+	in2, out2, err := swapCounters()
+	if err != nil {
+		return swapRate{}, err
+	}
+	perSecond := func(before, after uint64) float64 {
+		if after < before {
+			return 0
+		}
+		return float64(after-before) / interval.Seconds()
+	}
+	return swapRate{In: perSecond(in1, in2), Out: perSecond(out1, out2)}, nil
+}
+
+// swapActivity samples swap traffic over interval and renders it as memory
+// section lines.
+func swapActivity(interval time.Duration) string {
+	r, err := sampleSwapRate(interval)
+	if err != nil {
+		return fmt.Sprintf("Swap Activity:    Error: %v\n", err)
+	}
+	return formatSwapRate(r, interval)
+}
+
+// formatSwapRate renders swap-in/out rates, flagging combined traffic at or
+// above swapWarnRate so idle allocated swap reads differently from thrashing.
+func formatSwapRate(r swapRate, interval time.Duration) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Swap In:          %s/s\n", formatBytes(uint64(r.In), unitsIEC)))
+	sb.WriteString(fmt.Sprintf("Swap Out:         %s/s\n", formatBytes(uint64(r.Out), unitsIEC)))
+	sb.WriteString(fmt.Sprintf("Swap Sampled:     %s\n", interval))
+	if r.In+r.Out >= swapWarnRate {
+		sb.WriteString("Swap Warning:     actively swapping; the host may be thrashing\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"crypto/tls"
+	"testing"
+)
+
+func TestLoadTLSConfig(t *testing.T) {
+	t.Setenv("TLS_CERT_FILE", "cert.pem")
+	t.Setenv("TLS_KEY_FILE", "key.pem")
+	t.Setenv("TLS_MIN_VERSION", "")
+	t.Setenv("TLS_CIPHER_PROFILE", "strict")
+
+	cfg := &Config{}
+	if err := loadTLSConfig(cfg); err != nil {
+		t.Fatalf("loadTLSConfig returned error: %v", err)
+	}
+	if !cfg.tlsEnabled() {
+		t.Error("Expected TLS to be enabled")
+	}
+	tc := cfg.tlsConfig()
+	if tc.MinVersion != tls.VersionTLS12 {
+		t.Errorf("Expected default minimum version TLS 1.2, got %x", tc.MinVersion)
+	}
+	if len(tc.CipherSuites) == 0 {
+		t.Error("Expected the strict profile to restrict cipher suites")
+	}
+}
+
+func TestLoadTLSConfigRejectsInvalidSettings(t *testing.T) {
+	tests := []struct {
+		name    string
+		cert    string
+		key     string
+		version string
+		profile string
+	}{
+		{"old version", "cert.pem", "key.pem", "1.0", ""},
+		{"unknown profile", "cert.pem", "key.pem", "1.3", "legacy"},
+		{"cert without key", "cert.pem", "", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TLS_CERT_FILE", tt.cert)
+			t.Setenv("TLS_KEY_FILE", tt.key)
+			t.Setenv("TLS_MIN_VERSION", tt.version)
+			t.Setenv("TLS_CIPHER_PROFILE", tt.profile)
+			if err := loadTLSConfig(&Config{}); err == nil {
+				t.Error("Expected an error, got nil")
+			}
+		})
+	}
+}
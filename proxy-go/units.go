@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Unit bases accepted by formatBytes.
+const (
+	unitsIEC = "iec"
+	unitsSI  = "si"
+)
+
+var (
+	iecSuffixes = []string{"KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+	siSuffixes  = []string{"KB", "MB", "GB", "TB", "PB", "EB"}
+)
+
+// formatBytes renders n in the largest unit that keeps the value at or above
+// one: powers of 1024 with IEC labels (KiB, MiB, ...) or, for unitsSI, powers
+// of 1000 with SI labels (KB, MB, ...). Any other base is treated as IEC.
+func formatBytes(n uint64, base string) string {
+	unit, suffixes := uint64(1024), iecSuffixes
+	if base == unitsSI {
+		unit, suffixes = 1000, siSuffixes
+	}
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := unit, 0
+	for m := n / unit; m >= unit && exp < len(suffixes)-1; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %s", float64(n)/float64(div), suffixes[exp])
+}
+
+// formatThousands renders n in full with comma thousands separators, e.g.
+// 1,234,567,890.
+func formatThousands(n uint64) string {
+	digits := strconv.FormatUint(n, 10)
+	var sb strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteRune(d)
+	}
+	return sb.String()
+}
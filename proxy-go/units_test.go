@@ -0,0 +1,46 @@
+package main
+
+import "testing"
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		n    uint64
+		base string
+		want string
+	}{
+		{0, unitsIEC, "0 B"},
+		{1023, unitsIEC, "1023 B"},
+		{1024, unitsIEC, "1.0 KiB"},
+		{1536 * 1024, unitsIEC, "1.5 MiB"},
+		{8 * 1024 * 1024 * 1024, unitsIEC, "8.0 GiB"},
+		{999, unitsSI, "999 B"},
+		{1000, unitsSI, "1.0 KB"},
+		{2500000000, unitsSI, "2.5 GB"},
+		{1 << 63, unitsIEC, "8.0 EiB"},
+	}
+	for _, tt := range tests {
+		if got := formatBytes(tt.n, tt.base); got != tt.want {
+			t.Errorf("formatBytes(%d, %q) = %q, want %q", tt.n, tt.base, got, tt.want)
+		}
+	}
+}
+
+func TestFormatThousands(t *testing.T) {
+	tests := []struct {
+		n    uint64
+		want string
+	}{
+		{0, "0"},
+		{999, "999"},
+		{1000, "1,000"},
+		{1234567890, "1,234,567,890"},
+	}
+	for _, tt := range tests {
+		if got := formatThousands(tt.n); got != tt.want {
+			t.Errorf("formatThousands(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+	if got := (diskReportOptions{ExactBytes: true}).size(2048); got != "2,048 B" {
+		t.Errorf("Expected an exact size, got %q", got)
+	}
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// upstreamProbeTimeout bounds the whole test-upstream round trip.
+const upstreamProbeTimeout = 10 * time.Second
+
+// Failure classes of an upstream probe. test-upstream maps them onto the
+// exit-code contract so deploy scripts can tell a network problem from a
+// rejected credential.
+var (
+	errUpstreamConfig      = errors.New("upstream not configured")
+	errUpstreamUnreachable = errors.New("upstream unreachable")
+	errUpstreamAuth        = errors.New("upstream rejected credentials")
+	errUpstreamResponse    = errors.New("unexpected upstream response")
+)
+
+// upstreamExitCode maps a probe error to the test-upstream exit code. An
+// unreachable upstream is a collection failure; an answer that is not a
+// valid initialize result falls under the generic code.
+func upstreamExitCode(err error) int {
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, errUpstreamConfig):
+		return exitConfig
+	case errors.Is(err, errUpstreamAuth):
+		return exitAuth
+	case errors.Is(err, errUpstreamUnreachable):
+		return exitCollection
+	default:
+		return exitFailure
+	}
+}
+
+// upstreamProbe is the outcome of a successful MCP initialize.
+type upstreamProbe struct {
+	Server          string
+	ProtocolVersion string
+	Latency         time.Duration
+}
+
+// parseUpstreamURL parses UPSTREAM_MCP_URL, which must be an absolute http
+// or https URL.
+func parseUpstreamURL(rawURL string) (*url.URL, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, errors.New("UPSTREAM_MCP_URL must be an http or https URL")
+	}
+	return u, nil
+}
+
+// redactUpstreamURL returns rawURL with any password masked, so it can be
+// printed. A URL that does not parse is not echoed at all.
+func redactUpstreamURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "(invalid URL)"
+	}
+	return u.Redacted()
+}
+
+// newUpstreamProxy forwards MCP requests to the upstream endpoint target,
+// whatever their local path, so clients keep their session and protocol
+// headers and see the upstream's answers unchanged. Responses are flushed as
+// they arrive, so server-sent event streams are not buffered. A token
+// replaces the client's Authorization header with a bearer credential for
+// the upstream; without one the client's header is passed through.
+func newUpstreamProxy(target *url.URL, token string) http.Handler {
+	return &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.SetURL(target)
+			pr.Out.URL.Path, pr.Out.URL.RawPath = target.Path, target.RawPath
+			pr.SetXForwarded()
+			if token != "" {
+				pr.Out.Header.Set("Authorization", "Bearer "+token)
+			}
+		},
+		FlushInterval: -1,
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			slog.Warn("Upstream MCP request failed", "upstream", target.Redacted(), "error", err)
+			http.Error(w, "Bad Gateway: upstream MCP server unreachable", http.StatusBadGateway)
+		},
+	}
+}
+
+// initializeRequest is the smallest valid MCP initialize request.
+const initializeRequest = `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{},"clientInfo":{"name":"proxy-go","version":"1.0.0"}}}`
+
+// probeUpstream sends an MCP initialize to rawURL, with token as a bearer
+// credential when set, and checks that a server answered it. No session is
+// kept open.
+func probeUpstream(ctx context.Context, client *http.Client, rawURL, token string) (upstreamProbe, error) {
+	if rawURL == "" {
+		return upstreamProbe{}, fmt.Errorf("%w: UPSTREAM_MCP_URL is not set", errUpstreamConfig)
+	}
+	if _, err := parseUpstreamURL(rawURL); err != nil {
+		return upstreamProbe{}, fmt.Errorf("%w: %v", errUpstreamConfig, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rawURL, strings.NewReader(initializeRequest))
+	if err != nil {
+		return upstreamProbe{}, fmt.Errorf("%w: %v", errUpstreamConfig, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return upstreamProbe{}, fmt.Errorf("%w: %v", errUpstreamUnreachable, err)
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return upstreamProbe{}, fmt.Errorf("%w: HTTP %s", errUpstreamAuth, resp.Status)
+	case resp.StatusCode != http.StatusOK:
+		return upstreamProbe{}, fmt.Errorf("%w: HTTP %s", errUpstreamResponse, resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return upstreamProbe{}, fmt.Errorf("%w: %v", errUpstreamUnreachable, err)
+	}
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		body = firstEventData(body)
+	}
+	var msg struct {
+		Result *struct {
+			ProtocolVersion string `json:"protocolVersion"`
+			ServerInfo      struct {
+				Name    string `json:"name"`
+				Version string `json:"version"`
+			} `json:"serverInfo"`
+		} `json:"result"`
+		Error *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return upstreamProbe{}, fmt.Errorf("%w: not a JSON-RPC response: %v", errUpstreamResponse, err)
+	}
+	if msg.Error != nil {
+		return upstreamProbe{}, fmt.Errorf("%w: initialize failed: %d %s", errUpstreamResponse, msg.Error.Code, msg.Error.Message)
+	}
+	if msg.Result == nil {
+		return upstreamProbe{}, fmt.Errorf("%w: initialize returned no result", errUpstreamResponse)
+	}
+	latency := time.Since(start)
+	if id := resp.Header.Get("Mcp-Session-Id"); id != "" {
+		endUpstreamSession(ctx, client, rawURL, token, id)
+	}
+	return upstreamProbe{
+		Server:          strings.TrimSpace(msg.Result.ServerInfo.Name + " " + msg.Result.ServerInfo.Version),
+		ProtocolVersion: msg.Result.ProtocolVersion,
+		Latency:         latency,
+	}, nil
+}
+
+// endUpstreamSession deletes the session a probe's initialize opened, so
+// repeated readiness checks do not pile up sessions on the upstream. It is
+// best effort: an upstream that keeps the session just expires it later.
+func endUpstreamSession(ctx context.Context, client *http.Client, rawURL, token, id string) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, rawURL, nil)
+	if err != nil {
+		return
+	}
+	req.Header.Set("Mcp-Session-Id", id)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if resp, err := client.Do(req); err == nil {
+		resp.Body.Close()
+	}
+}
+
+// upstreamReadyTTL is how long a readiness result is reused, so frequent
+// /readyz polling does not run an MCP initialize against the upstream on
+// every hit.
+const upstreamReadyTTL = 5 * time.Second
+
+// upstreamReadiness serves /readyz in upstream mode: 200 while the upstream
+// answers an MCP initialize within timeout, 503 otherwise. As locally, a
+// graceful shutdown makes the instance unready.
+type upstreamReadiness struct {
+	rawURL  string
+	token   string
+	timeout time.Duration
+	now     func() time.Time
+
+	mu      sync.Mutex
+	checked time.Time
+	err     error
+}
+
+func newUpstreamReadiness(rawURL, token string, timeout time.Duration) *upstreamReadiness {
+	return &upstreamReadiness{rawURL: rawURL, token: token, timeout: timeout, now: time.Now}
+}
+
+// check probes the upstream at most once per upstreamReadyTTL. Concurrent
+// callers wait for the probe in flight and share its result.
+func (u *upstreamReadiness) check() error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	now := u.now()
+	if !u.checked.IsZero() && now.Sub(u.checked) < upstreamReadyTTL {
+		return u.err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), u.timeout)
+	defer cancel()
+	_, u.err = probeUpstream(ctx, http.DefaultClient, u.rawURL, u.token)
+	u.checked = now
+	if u.err != nil {
+		slog.Warn("Upstream readiness probe failed", "error", u.err)
+	}
+	return u.err
+}
+
+// serve answers /readyz. The probe error stays in the log since it names
+// the internal upstream host.
+func (u *upstreamReadiness) serve(w http.ResponseWriter, r *http.Request) {
+	if shuttingDown.Load() {
+		http.Error(w, "Service Unavailable: shutting down", http.StatusServiceUnavailable)
+		return
+	}
+	if err := u.check(); err != nil {
+		http.Error(w, "Service Unavailable: upstream unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("OK"))
+}
+
+// firstEventData returns the data of the first server-sent event in body.
+func firstEventData(body []byte) []byte {
+	var data []byte
+	sc := bufio.NewScanner(bytes.NewReader(body))
+	for sc.Scan() {
+		line := sc.Bytes()
+		if len(line) == 0 && data != nil {
+			break
+		}
+		if rest, ok := bytes.CutPrefix(line, []byte("data:")); ok {
+			data = append(data, bytes.TrimSpace(rest)...)
+		}
+	}
+	return data
+}
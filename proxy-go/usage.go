@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// usageText lists the commands and flags of the CLI.
+const usageText = `Usage: proxy-go [command] [flags]
+
+With no command, starts the MCP server on $PORT (default 8080).
+
+Commands:
+  info                  Print the system information report
+    --resolve-vendor    Annotate MAC addresses with their vendor
+    --memory-detail     Break used memory down by category
+    --remote USER@HOST  Run the report on another host over ssh
+  disk                  Print the disk usage report
+    --json              Print JSON instead of text
+    --show-device       Include the device backing each mount
+    --keep-duplicates   List every mount separately
+    --exact-bytes       Show exact byte counts instead of IEC units
+    --remote USER@HOST  Run the report on another host over ssh
+  processes             Print the top processes by memory usage
+  check                 Report that the utilities are available
+  test-upstream         Send an MCP initialize to $UPSTREAM_MCP_URL and report
+                        the latency; exits 2 if unset or invalid, 3 if
+                        credentials are rejected, 4 if unreachable, 1 on
+                        any other bad response
+  config                Print the resolved configuration
+    --json              Print JSON instead of text
+  version, --version    Print the version, git commit and build date
+  help, -h, --help      Show this help
+`
+
+// isHelp reports whether command asks for the usage text.
+func isHelp(command string) bool {
+	return command == "help" || command == "-h" || command == "--help"
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprint(w, usageText)
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+func TestParseWaitForTCP(t *testing.T) {
+	got, err := parseWaitForTCP(" db:5432, [::1]:6379 ,")
+	if err != nil || len(got) != 2 || got[0] != "db:5432" || got[1] != "[::1]:6379" {
+		t.Errorf("Unexpected endpoints: %q %v", got, err)
+	}
+	for _, bad := range []string{"db", "db:", "http://db:5432"} {
+		if _, err := parseWaitForTCP(bad); err == nil {
+			t.Errorf("Expected an error for %q", bad)
+		}
+	}
+}
+
+func TestWaitForTCP(t *testing.T) {
+	up, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer up.Close()
+	down, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	downAddr := down.Addr().String()
+	down.Close()
+
+	start := time.Now()
+	pending := waitForTCP([]string{up.Addr().String(), downAddr}, 200*time.Millisecond)
+	if len(pending) != 1 || pending[0] != downAddr {
+		t.Errorf("Expected only %s pending, got %q", downAddr, pending)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected the wait to stop at the timeout, took %v", elapsed)
+	}
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultWebhookInterval is how often the report is pushed when
+	// REPORT_WEBHOOK_INTERVAL is unset.
+	defaultWebhookInterval = time.Minute
+	// webhookTimeout bounds one delivery attempt.
+	webhookTimeout = 10 * time.Second
+	// webhookAttempts is how many times one report is tried before it is
+	// dropped; the next tick sends a fresh one.
+	webhookAttempts = 3
+	// webhookBackoff is the wait before the first retry; it doubles for each
+	// later one.
+	webhookBackoff = 2 * time.Second
+)
+
+// reportWebhook pushes the JSON report to a collector. It is safe for
+// concurrent use.
+type reportWebhook struct {
+	url         string
+	headerName  string
+	headerValue string
+	signingKey  string
+	client      *http.Client
+	backoff     time.Duration
+}
+
+// newReportWebhook builds the webhook from the configuration, which
+// loadConfig has already validated. Deliveries are signed like tool results
+// when REPORT_SIGNING_KEY is set.
+func newReportWebhook(cfg *Config) *reportWebhook {
+	h := &reportWebhook{url: cfg.WebhookURL, signingKey: cfg.ReportSigningKey, client: &http.Client{}, backoff: webhookBackoff}
+	if cfg.WebhookHeader != "" {
+		h.headerName, h.headerValue, _ = parseWebhookHeader(cfg.WebhookHeader)
+	}
+	return h
+}
+
+// validateWebhookURL checks REPORT_WEBHOOK_URL.
+func validateWebhookURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("invalid REPORT_WEBHOOK_URL: must be an http or https URL")
+	}
+	return nil
+}
+
+// parseWebhookHeader validates REPORT_WEBHOOK_AUTH_HEADER, a "Name: value"
+// header such as "Authorization: Bearer <token>".
+func parseWebhookHeader(v string) (name, value string, err error) {
+	name, value, ok := strings.Cut(v, ":")
+	name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+	if !ok || name == "" || value == "" || strings.ContainsAny(name, " \t") {
+		return "", "", errors.New(`invalid REPORT_WEBHOOK_AUTH_HEADER: must be "Name: value"`)
+	}
+	return name, value, nil
+}
+
+// deliver posts body, retrying network errors, 429 and 5xx responses with
+// exponential backoff. Other responses are final. It gives up early when ctx
+// is done.
+func (h *reportWebhook) deliver(ctx context.Context, body []byte) error {
+	var err error
+	wait := h.backoff
+	for attempt := 1; attempt <= webhookAttempts; attempt++ {
+		var retry bool
+		retry, err = h.post(ctx, body)
+		if err == nil || !retry || attempt == webhookAttempts {
+			break
+		}
+		slog.Debug("Report webhook delivery failed; retrying", "attempt", attempt, "error", err, "retry_in", wait)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+	return err
+}
+
+// post makes one delivery attempt and reports whether a failure is worth
+// retrying.
+func (h *reportWebhook) post(ctx context.Context, body []byte) (retry bool, err error) {
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if h.headerName != "" {
+		req.Header.Set(h.headerName, h.headerValue)
+	}
+	if h.signingKey != "" {
+		req.Header.Set("X-Report-Signature", signReport(string(body), h.signingKey))
+	}
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("webhook returned %s", resp.Status)
+	default:
+		return false, fmt.Errorf("webhook returned %s", resp.Status)
+	}
+}
+
+// startReportWebhook pushes the default full_report sections to
+// REPORT_WEBHOOK_URL every REPORT_WEBHOOK_INTERVAL. See runReportWebhook.
+func startReportWebhook(cfg *Config) (stop func()) {
+	collect := func(ctx context.Context) (string, error) {
+		return collectFullReport(ctx, fullReportInput{}.sections(), fullReportOptions{
+			Interfaces: cfg.IfaceFilter,
+			Disk:       diskReportOptions{MinTotalMB: cfg.DiskMinTotalMB},
+		})
+	}
+	return runReportWebhook(newReportWebhook(cfg), cfg.WebhookInterval, collect)
+}
+
+// runReportWebhook collects a report and pushes it every interval from a
+// single goroutine, starting one interval after the call. Every outcome is
+// logged at debug. The returned stop function cancels any delivery in flight
+// and waits for the goroutine to exit.
+func runReportWebhook(h *reportWebhook, interval time.Duration, collect func(context.Context) (string, error)) (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				start := time.Now()
+				report, err := collect(ctx)
+				if err == nil {
+					err = h.deliver(ctx, []byte(report))
+				}
+				if err != nil {
+					slog.Debug("Report webhook delivery failed", "error", err)
+				} else {
+					slog.Debug("Report webhook delivered", "bytes", len(report), "duration", time.Since(start))
+				}
+			}
+		}
+	})
+	slog.Info("Report webhook started", "interval", interval)
+	return func() {
+		cancel()
+		wg.Wait()
+	}
+}
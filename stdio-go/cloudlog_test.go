@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func TestNewLogHandlerCloudLogging(t *testing.T) {
+	var buf bytes.Buffer
+	slog.New(newLogHandler(&buf, true)).Warn("disk nearly full", "mount", "/")
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("Expected a JSON log line, got %q: %v", buf.String(), err)
+	}
+	if entry["severity"] != "WARNING" || entry["message"] != "disk nearly full" || entry["mount"] != "/" {
+		t.Errorf("Expected Cloud Logging fields, got: %v", entry)
+	}
+	if _, ok := entry["timestamp"]; !ok {
+		t.Errorf("Expected a timestamp field, got: %v", entry)
+	}
+	for _, key := range []string{"level", "msg", "time"} {
+		if _, ok := entry[key]; ok {
+			t.Errorf("Expected %q to be renamed, got: %v", key, entry)
+		}
+	}
+
+	buf.Reset()
+	slog.New(newLogHandler(&buf, false)).Info("started")
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("Expected a JSON log line, got %q: %v", buf.String(), err)
+	}
+	if entry["level"] != "INFO" || entry["msg"] != "started" {
+		t.Errorf("Expected the default slog fields when disabled, got: %v", entry)
+	}
+}
+
+func TestCloudSeverity(t *testing.T) {
+	for level, want := range map[slog.Level]string{
+		slog.LevelDebug:     "DEBUG",
+		slog.LevelInfo:      "INFO",
+		slog.LevelWarn:      "WARNING",
+		slog.LevelError:     "ERROR",
+		slog.LevelError + 4: "ERROR",
+	} {
+		if got := cloudSeverity(level); got != want {
+			t.Errorf("cloudSeverity(%v) = %q, want %q", level, got, want)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config holds the fully-resolved runtime configuration. It is populated once
+// by loadConfig and passed to the server and CLI paths.
+type Config struct {
+	Transport         string
+	AuthMode          string
+	DebugTiming       bool
+	CloudLogging      bool
+	NetIfaceInclude   string
+	NetIfaceExclude   string
+	OUIFile           string
+	RemoteBinary      string
+	IfaceFilter       interfaceFilter
+	MaxResultBytes    int
+	DiskMinTotalMB    int
+	SectionRetries    int
+	RetryThreshold    int
+	SectionPriority   []string
+	BackgroundRefresh bool
+	AllowRoot         bool
+	Offline           bool
+	RefreshInterval   time.Duration
+	MaxSampleInterval time.Duration
+	ReportSigningKey  string
+}
+
+// configEntry is a single printable setting. Secrets are stored already
+// redacted so entries can be printed or serialized without further care.
+type configEntry struct {
+	Key   string
+	Label string
+	Value any
+}
+
+// loadConfig never fails; an unparseable DEBUG_TIMING, LOG_CLOUD_LOGGING,
+// ENABLE_BACKGROUND_REFRESH or OFFLINE leaves the setting off, and an
+// unparseable or out-of-range MAX_RESULT_BYTES, DISK_MIN_TOTAL_MB,
+// SYSTEM_INFO_RETRIES, SYSTEM_INFO_RETRY_THRESHOLD,
+// BACKGROUND_REFRESH_INTERVAL or MAX_SAMPLE_INTERVAL keeps the default, as
+// does an unparseable ALLOW_ROOT.
+// An unreadable ENV_FILE or invalid REPORT_SECTION_PRIORITY is logged and
+// ignored.
+func loadConfig() *Config {
+	if err := loadEnvFile(); err != nil {
+		slog.Warn("Ignoring ENV_FILE", "error", err)
+	}
+	debugTiming, _ := strconv.ParseBool(os.Getenv("DEBUG_TIMING"))
+	backgroundRefresh, _ := strconv.ParseBool(os.Getenv("ENABLE_BACKGROUND_REFRESH"))
+	offline, _ := strconv.ParseBool(os.Getenv("OFFLINE"))
+	allowRoot, err := strconv.ParseBool(os.Getenv("ALLOW_ROOT"))
+	if err != nil {
+		allowRoot = true
+	}
+	remoteBinary := os.Getenv("REMOTE_BINARY")
+	if remoteBinary == "" {
+		remoteBinary = "stdio-go"
+	}
+	return &Config{
+		Transport:         "stdio",
+		AuthMode:          "none",
+		DebugTiming:       debugTiming,
+		BackgroundRefresh: backgroundRefresh,
+		AllowRoot:         allowRoot,
+		Offline:           offline,
+		CloudLogging:      cloudLoggingEnabled(),
+		MaxResultBytes:    envNonNegativeInt("MAX_RESULT_BYTES", defaultMaxResultBytes),
+		DiskMinTotalMB:    envNonNegativeInt("DISK_MIN_TOTAL_MB", 0),
+		SectionRetries:    envNonNegativeInt("SYSTEM_INFO_RETRIES", 0),
+		RetryThreshold:    envNonNegativeInt("SYSTEM_INFO_RETRY_THRESHOLD", 1),
+		SectionPriority:   envSectionPriority(),
+		RefreshInterval:   envRefreshInterval(),
+		MaxSampleInterval: envPositiveDuration("MAX_SAMPLE_INTERVAL", defaultMaxSampleInterval),
+		ReportSigningKey:  os.Getenv("REPORT_SIGNING_KEY"),
+		NetIfaceInclude:   os.Getenv("NET_IFACE_INCLUDE"),
+		NetIfaceExclude:   os.Getenv("NET_IFACE_EXCLUDE"),
+		OUIFile:           os.Getenv("OUI_FILE"),
+		RemoteBinary:      remoteBinary,
+	}
+}
+
+// envNonNegativeInt reads an integer setting, keeping def when it is unset,
+// unparseable or negative.
+func envNonNegativeInt(name string, def int) int {
+	n, err := strconv.Atoi(os.Getenv(name))
+	if err != nil || n < 0 {
+		return def
+	}
+	return n
+}
+
+// envSectionPriority reads REPORT_SECTION_PRIORITY, keeping the report
+// order when it is invalid.
+func envSectionPriority() []string {
+	priority, err := parseSectionPriority(os.Getenv("REPORT_SECTION_PRIORITY"), systemSectionNames)
+	if err != nil {
+		slog.Warn("Ignoring REPORT_SECTION_PRIORITY", "error", err)
+	}
+	return priority
+}
+
+// fingerprint returns a short, non-reversible identifier for a secret so it
+// can be printed or logged without exposing the value.
+func fingerprint(secret string) string {
+	if secret == "" {
+		return "(not set)"
+	}
+	sum := sha256.Sum256([]byte(secret))
+	return "sha256:" + hex.EncodeToString(sum[:])[:12]
+}
+
+// compileIfaceFilter compiles the NET_IFACE_INCLUDE and NET_IFACE_EXCLUDE
+// patterns. Unlike the other settings, a bad pattern is an error, so main can
+// fail fast instead of silently listing every interface.
+func (c *Config) compileIfaceFilter() error {
+	var err error
+	c.IfaceFilter, err = newInterfaceFilter(c.NetIfaceInclude, c.NetIfaceExclude)
+	return err
+}
+
+// sectionRetry is the system report retry policy from SYSTEM_INFO_RETRIES
+// and SYSTEM_INFO_RETRY_THRESHOLD.
+func (c *Config) sectionRetry() sectionRetry {
+	return sectionRetry{Attempts: c.SectionRetries, Threshold: c.RetryThreshold}
+}
+
+func (c *Config) entries() []configEntry {
+	return []configEntry{
+		{"transport", "Transport", c.Transport},
+		{"auth_mode", "Auth Mode", c.AuthMode},
+		{"debug_timing", "Debug Timing", c.DebugTiming},
+		{"net_iface_include", "Iface Include", c.NetIfaceInclude},
+		{"net_iface_exclude", "Iface Exclude", c.NetIfaceExclude},
+		{"oui_file", "OUI File", c.OUIFile},
+		{"remote_binary", "Remote Binary", c.RemoteBinary},
+		{"log_cloud_logging", "Cloud Logging", c.CloudLogging},
+		{"max_result_bytes", "Max Result Bytes", c.MaxResultBytes},
+		{"report_signing_key", "Report Signing Key", fingerprint(c.ReportSigningKey)},
+		{"disk_min_total_mb", "Disk Min Total MB", c.DiskMinTotalMB},
+		{"system_info_retries", "System Info Retries", c.SectionRetries},
+		{"system_info_retry_threshold", "System Info Retry Threshold", c.RetryThreshold},
+		{"report_section_priority", "Report Section Priority", strings.Join(c.SectionPriority, ",")},
+		{"background_refresh", "Background Refresh", c.BackgroundRefresh},
+		{"background_refresh_interval", "Refresh Interval", c.RefreshInterval.String()},
+		{"max_sample_interval", "Max Sample Interval", c.MaxSampleInterval.String()},
+		{"allow_root", "Allow Root", c.AllowRoot},
+		{"offline", "Offline", c.Offline},
+	}
+}
+
+func formatConfig(c *Config, asJSON bool) (string, error) {
+	entries := c.entries()
+	if asJSON {
+		m := make(map[string]any, len(entries))
+		for _, e := range entries {
+			m[e.Key] = e.Value
+		}
+		out, err := json.MarshalIndent(m, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(out) + "\n", nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Effective Configuration\n")
+	sb.WriteString("=======================\n\n")
+	for _, e := range entries {
+		sb.WriteString(fmt.Sprintf("%-20s %v\n", e.Label+":", e.Value))
+	}
+	return sb.String(), nil
+}
+
+// envPositiveDuration reads a duration setting, keeping def when it is unset,
+// unparseable or not positive.
+func envPositiveDuration(name string, def time.Duration) time.Duration {
+	d, err := time.ParseDuration(os.Getenv(name))
+	if err != nil || d <= 0 {
+		return def
+	}
+	return d
+}
+
+// envRefreshInterval reads BACKGROUND_REFRESH_INTERVAL, keeping the default
+// when it is unset, unparseable or not positive.
+func envRefreshInterval() time.Duration {
+	d, err := time.ParseDuration(os.Getenv("BACKGROUND_REFRESH_INTERVAL"))
+	if err != nil || d <= 0 {
+		return defaultRefreshInterval
+	}
+	return d
+}
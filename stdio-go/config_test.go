@@ -0,0 +1,31 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestFormatConfig(t *testing.T) {
+	cfg := &Config{Transport: "stdio", AuthMode: "none"}
+
+	text, err := formatConfig(cfg, false)
+	if err != nil {
+		t.Fatalf("formatConfig returned error: %v", err)
+	}
+	if !strings.Contains(text, "Effective Configuration") || !strings.Contains(text, "stdio") {
+		t.Errorf("Expected text output to contain the header and transport, got: %s", text)
+	}
+
+	out, err := formatConfig(cfg, true)
+	if err != nil {
+		t.Fatalf("formatConfig returned error: %v", err)
+	}
+	var m map[string]any
+	if err := json.Unmarshal([]byte(out), &m); err != nil {
+		t.Fatalf("Expected valid JSON, got error: %v", err)
+	}
+	if m["transport"] != "stdio" {
+		t.Errorf("Expected transport stdio, got: %v", m["transport"])
+	}
+}
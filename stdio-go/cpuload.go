@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/cpu"
+)
+
+// defaultCPULoadInterval is the cpu_load sampling window when the call gives
+// none.
+const defaultCPULoadInterval = time.Second
+
+// cpuPercent is the per-core utilization sample, replaceable in tests.
+var cpuPercent = cpu.PercentWithContext
+
+// cpuLoadInterval is the sampling window for an interval_ms input: the input
+// when set, otherwise defaultCPULoadInterval, kept within limit
+// (MAX_SAMPLE_INTERVAL).
+func cpuLoadInterval(ms int, limit time.Duration) time.Duration {
+	if ms > 0 {
+		return time.Duration(ms) * time.Millisecond
+	}
+	return min(defaultCPULoadInterval, limit)
+}
+
+// cpuLoadReport is the cpu_load report: utilization of every core sampled
+// over interval, and their mean as the aggregate. The call blocks for the
+// whole interval. A failed sample becomes an error line in the report.
+func cpuLoadReport(ctx context.Context, interval time.Duration) string {
+	var sb strings.Builder
+	sb.WriteString("CPU Load Report\n")
+	sb.WriteString("===============\n\n")
+	sb.WriteString(fmt.Sprintf("Interval:         %s\n", interval))
+
+	perCore, err := cpuPercent(ctx, interval, true)
+	if err != nil {
+		sb.WriteString(fmt.Sprintf("Error retrieving CPU load: %v\n", err))
+		return sb.String()
+	}
+	if len(perCore) == 0 {
+		sb.WriteString("Error retrieving CPU load: no per-core samples\n")
+		return sb.String()
+	}
+	total := 0.0
+	for _, pct := range perCore {
+		total += pct
+	}
+	sb.WriteString(fmt.Sprintf("Aggregate:        %.1f%%\n", total/float64(len(perCore))))
+	sb.WriteString("\n")
+	for i, pct := range perCore {
+		sb.WriteString(fmt.Sprintf("%-18s%.1f%%\n", fmt.Sprintf("Core %d:", i), pct))
+	}
+	return sb.String()
+}
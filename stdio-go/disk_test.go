@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/shirou/gopsutil/v3/disk"
+)
+
+func TestDiskUsageRetriesOnce(t *testing.T) {
+	orig := statUsage
+	defer func() { statUsage = orig }()
+
+	calls := 0
+	statUsage = func(path string) (*disk.UsageStat, error) {
+		calls++
+		if calls == 1 {
+			return nil, errors.New("resource temporarily unavailable")
+		}
+		return &disk.UsageStat{Path: path}, nil
+	}
+	if _, err := diskUsage(gopsutilSource{}, "/"); err != nil {
+		t.Errorf("Expected retry to succeed, got: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 calls, got %d", calls)
+	}
+
+	calls = 0
+	statUsage = func(path string) (*disk.UsageStat, error) {
+		calls++
+		return nil, errors.New("stuck mount")
+	}
+	if _, err := diskUsage(gopsutilSource{}, "/"); err == nil {
+		t.Error("Expected persistent failure to return an error")
+	}
+	if calls != 2 {
+		t.Errorf("Expected exactly one retry, got %d calls", calls)
+	}
+}
+
+func TestCollectDiskUsageJSON(t *testing.T) {
+	out, err := collectDiskUsageJSON(diskReportOptions{})
+	if err != nil {
+		t.Fatalf("collectDiskUsageJSON returned error: %v", err)
+	}
+	var parts []partitionUsage
+	if err := json.Unmarshal([]byte(out), &parts); err != nil {
+		t.Fatalf("Expected a JSON array, got error: %v (%s)", err, out)
+	}
+	for _, p := range parts {
+		if p.Error == "" && p.Total > 0 && p.Used+p.Free == 0 {
+			t.Errorf("Expected raw byte counts for %s, got: %+v", p.Mountpoint, p)
+		}
+	}
+}
+
+func TestDiskUsageReportRejectsUnknownFormat(t *testing.T) {
+	if _, err := diskUsageReport("xml", diskReportOptions{}); err == nil {
+		t.Error("Expected an error for an unsupported format")
+	}
+}
+
+func TestPartitionLabel(t *testing.T) {
+	p := partitionUsage{Mountpoint: "/data", Device: "/dev/sdb1"}
+	if got := p.label(false); got != "/data" {
+		t.Errorf("Expected mountpoint only, got %q", got)
+	}
+	if got := p.label(true); !strings.Contains(got, "/dev/sdb1") {
+		t.Errorf("Expected device in label, got %q", got)
+	}
+}
+
+func TestDedupePartitions(t *testing.T) {
+	parts := []partitionUsage{
+		{Mountpoint: "/", Device: "/dev/sda1", Fstype: "ext4"},
+		{Mountpoint: "/dev/shm", Device: "shm", Fstype: "tmpfs"},
+		{Mountpoint: "/etc/hosts", Device: "/dev/sda1", Fstype: "ext4"},
+		{Mountpoint: "/run", Device: "shm", Fstype: "tmpfs"},
+		{Mountpoint: "/etc/hostname", Device: "/dev/sda1", Fstype: "ext4"},
+	}
+	got := dedupePartitions(parts)
+	if len(got) != 3 {
+		t.Fatalf("Expected 3 partitions, got %d: %+v", len(got), got)
+	}
+	if got[0].Mountpoint != "/" || strings.Join(got[0].AlsoMountedAt, ",") != "/etc/hosts,/etc/hostname" {
+		t.Errorf("Expected bind mounts collapsed into /, got %+v", got[0])
+	}
+	if note := got[0].alsoMountedNote(); !strings.Contains(note, "/etc/hosts") {
+		t.Errorf("Expected collapsed mountpoints in note, got %q", note)
+	}
+	if got[1].AlsoMountedAt != nil || got[2].AlsoMountedAt != nil {
+		t.Errorf("Expected virtual filesystems kept separate, got %+v", got[1:])
+	}
+}
+
+func TestFilterMinTotal(t *testing.T) {
+	parts := []partitionUsage{
+		{Mountpoint: "/", Total: 20 << 30},
+		{Mountpoint: "/dev", Total: 64 << 10},
+		{Mountpoint: "/boot", Total: 100 << 20},
+		{Mountpoint: "/mnt/stale", Error: "stale file handle"},
+	}
+	if got := filterMinTotal(parts, 0); len(got) != 4 {
+		t.Errorf("Expected every partition at 0, got %+v", got)
+	}
+	var mounts []string
+	for _, p := range filterMinTotal(parts, 100) {
+		mounts = append(mounts, p.Mountpoint)
+	}
+	if strings.Join(mounts, ",") != "/,/boot,/mnt/stale" {
+		t.Errorf("Expected partitions under 100 MiB dropped and unreadable ones kept, got %v", mounts)
+	}
+	if _, err := diskUsageReport("text", diskReportOptions{MinTotalMB: -1}); err == nil {
+		t.Error("Expected an error for a negative min_total_mb")
+	}
+}
+
+func TestCollectDiskUsageNoPartitions(t *testing.T) {
+	orig := listPartitions
+	defer func() { listPartitions = orig }()
+
+	var tried []bool
+	listPartitions = func(all bool) ([]disk.PartitionStat, error) {
+		tried = append(tried, all)
+		return []disk.PartitionStat{}, nil
+	}
+	out := collectDiskUsage(diskReportOptions{})
+	if !strings.Contains(out, "No partitions found") {
+		t.Errorf("Expected a no-partitions note, got: %s", out)
+	}
+	if len(tried) != 2 || tried[0] || !tried[1] {
+		t.Errorf("Expected physical then all partitions to be tried, got %v", tried)
+	}
+
+	js, err := collectDiskUsageJSON(diskReportOptions{})
+	if err != nil || strings.TrimSpace(js) != "[]" {
+		t.Errorf("Expected an empty JSON array, got %q, %v", js, err)
+	}
+}
+
+func TestReportLineInodes(t *testing.T) {
+	withInodes := partitionUsage{Mountpoint: "/", Fstype: "ext4", Total: 100 << 30, Used: 25 << 30, Percent: 25,
+		Inodes: inodeUsage{Used: 1200, Total: 6553600, Percent: 0.02}}
+	full := partitionUsage{Mountpoint: "/data", Fstype: "xfs", Total: 100 << 30, Used: 100 << 30, Percent: 100,
+		Inodes: inodeUsage{Used: 6553600, Total: 6553600, Percent: 100}}
+	noInodes := partitionUsage{Mountpoint: "/boot/efi", Fstype: "vfat", Total: 512 << 20, Used: 6 << 20, Percent: 1.2}
+
+	a, b := withInodes.reportLine(diskReportOptions{}), full.reportLine(diskReportOptions{})
+	if !strings.Contains(a, "1200 /    6553600 inodes (0.0%)") {
+		t.Errorf("Expected inode columns, got %q", a)
+	}
+	if strings.Index(a, "inodes") != strings.Index(b, "inodes") {
+		t.Errorf("Expected the inode columns aligned:\n%s\n%s", a, b)
+	}
+	c := noInodes.reportLine(diskReportOptions{})
+	if strings.Contains(c, "inodes") || !strings.HasSuffix(c, "used (1.2%)") {
+		t.Errorf("Expected no inode columns for a filesystem without inodes, got %q", c)
+	}
+}
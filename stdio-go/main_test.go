@@ -1,12 +1,14 @@
 package main
 
 import (
+	"context"
+	"slices"
 	"strings"
 	"testing"
 )
 
 func TestCollectDiskUsage(t *testing.T) {
-	output := collectDiskUsage()
+	output := collectDiskUsage(diskReportOptions{})
 	if !strings.Contains(output, "Disk Usage Report") {
 		t.Errorf("Expected output to contain 'Disk Usage Report', got: %s", output)
 	}
@@ -17,7 +19,7 @@ func TestCollectDiskUsage(t *testing.T) {
 }
 
 func TestCollectSystemInfo(t *testing.T) {
-	output := collectSystemInfo("test status")
+	output := collectSystemInfo(context.Background(), "test status", false, systemInfoOptions{})
 	if !strings.Contains(output, "System Information Report") {
 		t.Errorf("Expected output to contain 'System Information Report', got: %s", output)
 	}
@@ -31,3 +33,24 @@ func TestCollectSystemInfo(t *testing.T) {
 		t.Errorf("Expected output to contain 'Memory Information', got: %s", output)
 	}
 }
+
+func TestUsage(t *testing.T) {
+	for _, command := range []string{"info", "disk", "config"} {
+		if !strings.Contains(usageText, "\n  "+command+" ") {
+			t.Errorf("Expected usage to list %q", command)
+		}
+	}
+	if !isHelp("--help") || !isHelp("-h") || !isHelp("help") || isHelp("info") {
+		t.Error("Expected help, -h and --help to be recognized as help")
+	}
+}
+
+func TestSystemSectionNames(t *testing.T) {
+	var names []string
+	for _, s := range systemSections(systemInfoOptions{}) {
+		names = append(names, s.name)
+	}
+	if !slices.Equal(names, systemSectionNames) {
+		t.Errorf("systemSectionNames %q does not match systemSections %q", systemSectionNames, names)
+	}
+}
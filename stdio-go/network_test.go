@@ -0,0 +1,86 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatInterface(t *testing.T) {
+	n := networkInterface{
+		Name:      "eth0",
+		MTU:       1500,
+		MAC:       "aa:bb:cc:dd:ee:ff",
+		Flags:     []string{"up", "broadcast", "multicast"},
+		HasIO:     true,
+		BytesRecv: 100,
+		BytesSent: 200,
+	}
+	line := formatInterface(n)
+	for _, want := range []string{"eth0", "RX:        100 bytes", "MAC: aa:bb:cc:dd:ee:ff", "[up,broadcast,multicast]", "MTU 1500"} {
+		if !strings.Contains(line, want) {
+			t.Errorf("Expected line to contain %q, got: %s", want, line)
+		}
+	}
+	if !n.isUp() || n.isLoopback() {
+		t.Errorf("Expected interface to be up and not loopback")
+	}
+
+	idle := networkInterface{Name: "lo", MAC: "unknown", Flags: []string{"up", "loopback"}}
+	if line := formatInterface(idle); !strings.Contains(line, "(No IO stats)") {
+		t.Errorf("Expected no IO stats marker, got: %s", line)
+	}
+}
+
+func TestFormatInterfacesHidesIdle(t *testing.T) {
+	interfaces := []networkInterface{
+		{Name: "eth0", HasIO: true, BytesRecv: 100},
+		{Name: "veth1234", HasIO: true},
+		{Name: "dummy0"},
+	}
+	out := formatInterfaces(interfaces, false)
+	if !strings.Contains(out, "eth0") || strings.Contains(out, "veth1234") || strings.Contains(out, "dummy0") {
+		t.Errorf("Expected only the active interface, got: %s", out)
+	}
+	if !strings.Contains(out, "2 idle interfaces hidden") {
+		t.Errorf("Expected a note about hidden interfaces, got: %s", out)
+	}
+
+	out = formatInterfaces(interfaces, true)
+	if !strings.Contains(out, "veth1234") || !strings.Contains(out, "dummy0") || strings.Contains(out, "hidden") {
+		t.Errorf("Expected every interface with include_idle, got: %s", out)
+	}
+}
+
+func TestInterfaceFilter(t *testing.T) {
+	interfaces := []networkInterface{
+		{Name: "eth0", HasIO: true, BytesRecv: 100},
+		{Name: "veth1234", HasIO: true, BytesRecv: 100},
+		{Name: "br-abc", HasIO: true, BytesRecv: 100},
+	}
+	filter, err := newInterfaceFilter("", "^(veth|br-)")
+	if err != nil {
+		t.Fatalf("newInterfaceFilter returned error: %v", err)
+	}
+	out := filter.format(interfaces, true)
+	if !strings.Contains(out, "eth0") || strings.Contains(out, "veth1234") || strings.Contains(out, "br-abc") {
+		t.Errorf("Expected veth and bridge interfaces excluded, got: %s", out)
+	}
+	if !strings.Contains(out, "2 interfaces excluded by name filter") {
+		t.Errorf("Expected a note about excluded interfaces, got: %s", out)
+	}
+
+	filter, err = filter.withOverrides("eth", "")
+	if err != nil {
+		t.Fatalf("withOverrides returned error: %v", err)
+	}
+	if !filter.matches("eth0") || filter.matches("veth1234") || filter.matches("br-abc") {
+		t.Error("Expected the include override to apply on top of the configured exclude")
+	}
+
+	if _, err := newInterfaceFilter("eth[", ""); err == nil || !strings.Contains(err.Error(), "NET_IFACE_INCLUDE") {
+		t.Errorf("Expected a NET_IFACE_INCLUDE error, got: %v", err)
+	}
+	if _, err := filter.withOverrides("", "("); err == nil || !strings.Contains(err.Error(), "iface_exclude") {
+		t.Errorf("Expected an iface_exclude error, got: %v", err)
+	}
+}
@@ -0,0 +1,15 @@
+package main
+
+import "log/slog"
+
+// offlineDisabled lists what OFFLINE=true turns off, for the startup log.
+// stdio-go makes no cloud calls, so only the ssh of --remote is left.
+var offlineDisabled = []string{"--remote"}
+
+// logOffline announces offline mode and the features it disables; it logs
+// nothing when offline is false.
+func logOffline(offline bool) {
+	if offline {
+		slog.Warn("OFFLINE=true: outbound network calls are disabled", "disabled", offlineDisabled)
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMacVendor(t *testing.T) {
+	tests := map[string]string{
+		"00:50:56:aa:bb:cc": "VMware",
+		"52:54:00:12:34:56": "QEMU/KVM",
+		"02:42:ac:11:00:02": "locally administered",
+		"00:00:5e:00:53:01": "unknown vendor",
+		"unknown":           "",
+	}
+	for mac, want := range tests {
+		if got := macVendor(mac); got != want {
+			t.Errorf("macVendor(%q) = %q, want %q", mac, got, want)
+		}
+	}
+}
+
+func TestParseOUIFormats(t *testing.T) {
+	m, err := parseOUI(strings.NewReader("# comment\n00-00-5E   (hex)\t\tICANN, IANA Department\n00005E     (base 16)\t\tICANN, IANA Department\nabcdef\tExample\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m) != 2 || m["00005E"] != "ICANN, IANA Department" || m["ABCDEF"] != "Example" {
+		t.Errorf("Unexpected table: %v", m)
+	}
+}
+
+func TestLoadOUIFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "oui.txt")
+	os.WriteFile(path, []byte("00-00-5E   (hex)\t\tICANN\n"), 0o644)
+	defer delete(ouiVendors, "00005E")
+	if err := loadOUIFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if got := macVendor("00:00:5e:00:53:01"); got != "ICANN" {
+		t.Errorf("Expected the OUI_FILE entry, got %q", got)
+	}
+	if err := loadOUIFile(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("Expected an error for a missing OUI_FILE")
+	}
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackgroundRefresh(t *testing.T) {
+	stop := startBackgroundRefresh(time.Hour)
+	if enumerationCache.Load() == nil {
+		t.Fatal("Expected a snapshot after start")
+	}
+	if _, err := cachedPartitions(true); err != nil {
+		t.Errorf("Expected cached partitions, got %v", err)
+	}
+	stop()
+	if enumerationCache.Load() != nil {
+		t.Error("Expected the snapshot dropped after stop")
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// defaultMaxResultBytes caps tool results when MAX_RESULT_BYTES is unset.
+const defaultMaxResultBytes = 1 << 20
+
+// resultTruncatedMarker ends a tool result cut short by MAX_RESULT_BYTES.
+const resultTruncatedMarker = "\n[truncated]\n"
+
+// truncateResult caps text at limit bytes, marker included, without splitting
+// a UTF-8 sequence. A limit of 0 or less leaves text unchanged.
+func truncateResult(text string, limit int) string {
+	if limit <= 0 || len(text) <= limit {
+		return text
+	}
+	cut := max(limit-len(resultTruncatedMarker), 0)
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return text[:cut] + resultTruncatedMarker
+}
+
+// resultSizeMiddleware applies truncateResult to the text of every tool
+// result, so no tool can return unbounded output.
+func resultSizeMiddleware(limit int) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			res, err := next(ctx, request)
+			if res == nil {
+				return res, err
+			}
+			for i, c := range res.Content {
+				if tc, ok := c.(mcp.TextContent); ok {
+					tc.Text = truncateResult(tc.Text, limit)
+					res.Content[i] = tc
+				}
+			}
+			return res, err
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestTruncateResult(t *testing.T) {
+	if got := truncateResult("short", 100); got != "short" {
+		t.Errorf("Expected short text unchanged, got %q", got)
+	}
+	if got := truncateResult(strings.Repeat("x", 500), 0); len(got) != 500 {
+		t.Errorf("Expected no cap at 0, got %d bytes", len(got))
+	}
+	got := truncateResult(strings.Repeat("é", 100), 51)
+	if len(got) > 51 || !strings.HasSuffix(got, resultTruncatedMarker) || !utf8.ValidString(got) {
+		t.Errorf("Expected valid UTF-8 capped at 51 bytes with a marker, got %d bytes: %q", len(got), got)
+	}
+}
+
+func TestResultSizeMiddleware(t *testing.T) {
+	next := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText(strings.Repeat("x", 1000)), nil
+	}
+	res, err := resultSizeMiddleware(100)(next)(context.Background(), mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := res.Content[0].(mcp.TextContent).Text
+	if len(text) > 100 || !strings.HasSuffix(text, "[truncated]\n") {
+		t.Errorf("Expected result capped at 100 bytes, got %d: %q", len(text), text)
+	}
+}
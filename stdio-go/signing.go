@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// reportSignatureMeta is the _meta key that carries a text result's signature.
+// It is an annotation rather than a line in the text so JSON reports still
+// parse.
+const reportSignatureMeta = "signature"
+
+// signReport returns the HMAC-SHA256 of text under key, in the form
+// "hmac-sha256=<hex>".
+func signReport(text, key string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(text))
+	return "hmac-sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// verifyReport reports whether sig is the signature of text under key.
+func verifyReport(text, key, sig string) bool {
+	return hmac.Equal([]byte(signReport(text, key)), []byte(sig))
+}
+
+// reportSigningMiddleware annotates the text of every tool result with its
+// signature under REPORT_SIGNING_KEY. It must wrap resultSizeMiddleware so the
+// signature covers the text the client actually receives. An empty key leaves
+// results unsigned.
+func reportSigningMiddleware(key string) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		if key == "" {
+			return next
+		}
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			res, err := next(ctx, request)
+			if res == nil {
+				return res, err
+			}
+			for i, c := range res.Content {
+				if tc, ok := c.(mcp.TextContent); ok {
+					tc.Meta = mcp.NewMetaFromMap(map[string]any{reportSignatureMeta: signReport(tc.Text, key)})
+					res.Content[i] = tc
+				}
+			}
+			return res, err
+		}
+	}
+}
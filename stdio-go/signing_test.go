@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+func TestReportSigningMiddleware(t *testing.T) {
+	next := func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("report body"), nil
+	}
+	res, err := reportSigningMiddleware("shared")(next)(context.Background(), mcp.CallToolRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tc := res.Content[0].(mcp.TextContent)
+	sig, _ := tc.Meta.AdditionalFields[reportSignatureMeta].(string)
+	if !verifyReport(tc.Text, "shared", sig) {
+		t.Errorf("Expected a signature valid under the shared key, got %q", sig)
+	}
+	if verifyReport(tc.Text+"tampered", "shared", sig) || verifyReport(tc.Text, "other", sig) {
+		t.Error("Expected the signature to reject altered text or another key")
+	}
+
+	res, _ = reportSigningMiddleware("")(next)(context.Background(), mcp.CallToolRequest{})
+	if meta := res.Content[0].(mcp.TextContent).Meta; meta != nil {
+		t.Errorf("Expected no signature without a key, got %v", meta)
+	}
+}
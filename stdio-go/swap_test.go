@@ -0,0 +1,37 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSampleSwapRate(t *testing.T) {
+	orig := swapCounters
+	defer func() { swapCounters = orig }()
+	reads := [][2]uint64{{1000, 5000}, {1000 + 4<<20, 4000}}
+	swapCounters = func() (uint64, uint64, error) {
+		r := reads[0]
+		reads = reads[1:]
+		return r[0], r[1], nil
+	}
+
+	r, err := sampleSwapRate(time.Millisecond)
+	if err != nil {
+		t.Fatalf("sampleSwapRate failed: %v", err)
+	}
+	if r.In != float64(4<<20)*1000 || r.Out != 0 {
+		t.Errorf("Unexpected rates: %+v", r)
+	}
+}
+
+func TestFormatSwapRate(t *testing.T) {
+	idle := formatSwapRate(swapRate{In: 0, Out: 4096}, time.Second)
+	if !strings.Contains(idle, "Swap Out:         4.0 KiB/s") || strings.Contains(idle, "Warning") {
+		t.Errorf("Unexpected idle swap report:\n%s", idle)
+	}
+	busy := formatSwapRate(swapRate{In: swapWarnRate, Out: 0}, time.Second)
+	if !strings.Contains(busy, "Swap Warning:") {
+		t.Errorf("Expected a warning for heavy swapping:\n%s", busy)
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/host"
+	"github.com/shirou/gopsutil/v3/mem"
+	"github.com/shirou/gopsutil/v3/net"
+)
+
+func TestCollectSystemInfoJSON(t *testing.T) {
+	src := fakeSource{
+		host:   &host.InfoStat{Hostname: "fake-host", OS: "linux", BootTime: 1767225600},
+		cores:  4,
+		memory: &mem.VirtualMemoryStat{Total: 8 << 30, Used: 2 << 30, UsedPercent: 25},
+		interfaces: net.InterfaceStatList{
+			{Name: "eth0", Flags: []string{"up"}},
+			{Name: "veth1", Flags: []string{"up"}},
+		},
+		counters: []net.IOCountersStat{{Name: "eth0", BytesRecv: 10}},
+	}
+	out, err := systemInfoJSONReport(systemInfoOptions{Source: src})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got systemInfoJSON
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("Invalid JSON %v:\n%s", err, out)
+	}
+	if _, err := time.Parse(time.RFC3339, got.CollectedAt); err != nil {
+		t.Errorf("Expected an RFC 3339 collected_at, got %q", got.CollectedAt)
+	}
+	if got.Host == nil || got.Host.HostName != "fake-host" || !got.Host.BootTime.Equal(time.Unix(1767225600, 0)) {
+		t.Errorf("Unexpected host %+v", got.Host)
+	}
+	if got.CPU == nil || got.CPU.Cores != 4 {
+		t.Errorf("Unexpected cpu %+v", got.CPU)
+	}
+	if got.Memory == nil || got.Memory.Used != 2<<30 || got.Memory.Percent != 25 {
+		t.Errorf("Unexpected memory %+v", got.Memory)
+	}
+	if got.Swap != nil || got.Errors["swap"] != errFake.Error() {
+		t.Errorf("Expected the swap failure under errors, got %+v, %v", got.Swap, got.Errors)
+	}
+	if len(got.Interfaces) != 1 || got.Interfaces[0].Name != "eth0" {
+		t.Errorf("Expected only the active interface, got %+v", got.Interfaces)
+	}
+
+	got = collectSystemInfoJSON(systemInfoOptions{Source: src, IncludeIdle: true})
+	if len(got.Interfaces) != 2 {
+		t.Errorf("Expected idle interfaces with IncludeIdle, got %+v", got.Interfaces)
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTimezone(t *testing.T) {
+	loc, err := parseTimezone("")
+	if err != nil || loc != time.UTC {
+		t.Errorf("Expected UTC by default, got %v, %v", loc, err)
+	}
+	if _, err := parseTimezone("Europe/Berlin"); err != nil {
+		t.Errorf("Expected Europe/Berlin to load, got: %v", err)
+	}
+	for _, bad := range []string{"Mars/Olympus", "Local"} {
+		if _, err := parseTimezone(bad); err == nil {
+			t.Errorf("Expected an error for %q", bad)
+		}
+	}
+}
+
+func TestFormatTimestamp(t *testing.T) {
+	ts := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	if got := formatTimestamp(ts, nil); got != "2026-01-15 12:00:00 +00:00 UTC" {
+		t.Errorf("Unexpected UTC timestamp: %q", got)
+	}
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := formatTimestamp(ts, berlin); got != "2026-01-15 13:00:00 +01:00 Europe/Berlin" {
+		t.Errorf("Unexpected Berlin timestamp: %q", got)
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestSectionTimer(t *testing.T) {
+	var disabled *sectionTimer
+	disabled.mark("Host")
+	if out := disabled.report(); out != "" {
+		t.Errorf("Expected no output from a disabled timer, got: %s", out)
+	}
+
+	timer := newSectionTimer(true)
+	timer.mark("Host")
+	timer.mark("Network")
+	out := timer.report()
+	for _, want := range []string{"Timing", "Host:", "Network:", "Total:"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected timing report to contain %q, got: %s", want, out)
+		}
+	}
+}
+
+func TestCollectSystemInfoTiming(t *testing.T) {
+	if out := collectSystemInfo(context.Background(), "", true, systemInfoOptions{}); !strings.Contains(out, "Timing") {
+		t.Errorf("Expected a Timing section when enabled, got: %s", out)
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"runtime/debug"
+)
+
+// Build metadata, injected at link time:
+//
+//	go build -ldflags "-X main.version=v1.2.0 -X main.commit=$(git rev-parse --short HEAD) -X main.buildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// The Makefile sets all three. Values left empty fall back to what the Go
+// toolchain embeds, which covers the commit of a plain go build in a git
+// checkout.
+var version, commit, buildDate string
+
+// buildInfo is the version, commit and build date of the running binary.
+type buildInfo struct {
+	Version   string
+	Commit    string
+	BuildDate string
+}
+
+// currentBuild returns the injected build metadata, completed from the
+// binary's embedded module version and VCS revision. Anything still unknown,
+// including the build date of a plain go build, is reported as "unknown".
+func currentBuild() buildInfo {
+	b := buildInfo{Version: version, Commit: commit, BuildDate: buildDate}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		if v := info.Main.Version; v != "" && v != "(devel)" {
+			b.Version = cmp.Or(b.Version, v)
+		}
+		for _, s := range info.Settings {
+			if s.Key == "vcs.revision" {
+				b.Commit = cmp.Or(b.Commit, s.Value)
+			}
+		}
+	}
+	b.Version = cmp.Or(b.Version, "unknown")
+	b.Commit = cmp.Or(b.Commit, "unknown")
+	b.BuildDate = cmp.Or(b.BuildDate, "unknown")
+	return b
+}
+
+// isVersion reports whether arg asks for the version.
+func isVersion(arg string) bool {
+	return arg == "version" || arg == "--version"
+}
+
+// printVersion prints the build metadata of the binary called name.
+func printVersion(w io.Writer, name string) {
+	b := currentBuild()
+	fmt.Fprintf(w, "%s %s\ncommit: %s\nbuilt:  %s\n", name, b.Version, b.Commit, b.BuildDate)
+}
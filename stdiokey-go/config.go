@@ -0,0 +1,274 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config holds the fully-resolved runtime configuration. It is populated once
+// from the environment and command-line arguments by loadConfig.
+type Config struct {
+	Transport         string
+	AuthMode          string
+	APIKey            string
+	APIKeySource      string
+	ProjectID         string
+	DebugTiming       bool
+	CloudLogging      bool
+	NetIfaceInclude   string
+	NetIfaceExclude   string
+	OUIFile           string
+	RemoteBinary      string
+	IfaceFilter       interfaceFilter
+	MaxResultBytes    int
+	DiskMinTotalMB    int
+	SectionRetries    int
+	RetryThreshold    int
+	SectionPriority   []string
+	BackgroundRefresh bool
+	AllowRoot         bool
+	Offline           bool
+	RefreshInterval   time.Duration
+	MaxSampleInterval time.Duration
+	ReportSigningKey  string
+	// KeyFingerprint pins the fetched key. An invalid MCP_API_KEY_FINGERPRINT
+	// is kept as-is so that it matches no key rather than disabling the pin.
+	KeyFingerprint string
+}
+
+// configEntry is a single printable setting. Secrets are stored already
+// redacted so entries can be printed or serialized without further care.
+type configEntry struct {
+	Key   string
+	Label string
+	Value any
+}
+
+func loadConfig(args []string) *Config {
+	if err := loadEnvFile(); err != nil {
+		slog.Warn("Ignoring ENV_FILE", "error", err)
+	}
+	cfg := &Config{
+		Transport: "stdio",
+		AuthMode:  "apikey",
+		APIKey:    os.Getenv("MCP_API_KEY"),
+		ProjectID: os.Getenv("GOOGLE_CLOUD_PROJECT"),
+	}
+	// An unparseable DEBUG_TIMING leaves timing off
+	cfg.DebugTiming, _ = strconv.ParseBool(os.Getenv("DEBUG_TIMING"))
+	cfg.CloudLogging = cloudLoggingEnabled()
+	// An unparseable or negative MAX_RESULT_BYTES keeps the default
+	cfg.MaxResultBytes = defaultMaxResultBytes
+	if n, err := strconv.Atoi(os.Getenv("MAX_RESULT_BYTES")); err == nil && n >= 0 {
+		cfg.MaxResultBytes = n
+	}
+	// Likewise for DISK_MIN_TOTAL_MB, whose default includes every partition
+	if n, err := strconv.Atoi(os.Getenv("DISK_MIN_TOTAL_MB")); err == nil && n >= 0 {
+		cfg.DiskMinTotalMB = n
+	}
+	// and SYSTEM_INFO_RETRIES, whose default never retries
+	if n, err := strconv.Atoi(os.Getenv("SYSTEM_INFO_RETRIES")); err == nil && n >= 0 {
+		cfg.SectionRetries = n
+	}
+	cfg.RetryThreshold = 1
+	if n, err := strconv.Atoi(os.Getenv("SYSTEM_INFO_RETRY_THRESHOLD")); err == nil && n >= 0 {
+		cfg.RetryThreshold = n
+	}
+	// An invalid REPORT_SECTION_PRIORITY keeps the report order
+	if priority, err := parseSectionPriority(os.Getenv("REPORT_SECTION_PRIORITY"), systemSectionNames); err == nil {
+		cfg.SectionPriority = priority
+	} else {
+		slog.Warn("Ignoring REPORT_SECTION_PRIORITY", "error", err)
+	}
+	cfg.ReportSigningKey = os.Getenv("REPORT_SIGNING_KEY")
+	// An unparseable ENABLE_BACKGROUND_REFRESH leaves it off
+	cfg.BackgroundRefresh, _ = strconv.ParseBool(os.Getenv("ENABLE_BACKGROUND_REFRESH"))
+	// An unparseable ALLOW_ROOT keeps the default of allowing root
+	cfg.AllowRoot = true
+	if b, err := strconv.ParseBool(os.Getenv("ALLOW_ROOT")); err == nil {
+		cfg.AllowRoot = b
+	}
+	// An unparseable OFFLINE leaves it off
+	cfg.Offline, _ = strconv.ParseBool(os.Getenv("OFFLINE"))
+	cfg.RefreshInterval = envRefreshInterval()
+	cfg.MaxSampleInterval = envPositiveDuration("MAX_SAMPLE_INTERVAL", defaultMaxSampleInterval)
+	if v := os.Getenv("MCP_API_KEY_FINGERPRINT"); v != "" {
+		var err error
+		if cfg.KeyFingerprint, err = parseKeyFingerprint(v); err != nil {
+			cfg.KeyFingerprint = v
+		}
+	}
+	cfg.NetIfaceInclude = os.Getenv("NET_IFACE_INCLUDE")
+	cfg.NetIfaceExclude = os.Getenv("NET_IFACE_EXCLUDE")
+	cfg.OUIFile = os.Getenv("OUI_FILE")
+	if cfg.RemoteBinary = os.Getenv("REMOTE_BINARY"); cfg.RemoteBinary == "" {
+		cfg.RemoteBinary = "stdiokey-go"
+	}
+	if cfg.APIKey != "" {
+		cfg.APIKeySource = "MCP_API_KEY"
+	} else {
+		for i, arg := range args {
+			if arg == "--key" && i+1 < len(args) {
+				cfg.APIKey = args[i+1]
+				cfg.APIKeySource = "--key"
+				break
+			}
+		}
+	}
+	cfg.APIKey = normalizeAPIKey(cfg.APIKeySource, cfg.APIKey)
+	return cfg
+}
+
+// normalizeAPIKey trims whitespace and one pair of matching surrounding
+// quotes from an API key, both common leftovers of copying a key into a .env
+// file or a secret. source names where the key came from for the debug log,
+// which shows only the fingerprint of the result.
+func normalizeAPIKey(source, key string) string {
+	norm := strings.TrimSpace(key)
+	if len(norm) >= 2 && (norm[0] == '"' || norm[0] == '\'') && norm[len(norm)-1] == norm[0] {
+		norm = strings.TrimSpace(norm[1 : len(norm)-1])
+	}
+	if norm != key {
+		slog.Debug("Normalized API key", "source", source, "key", fingerprint(norm), "removed_bytes", len(key)-len(norm))
+	}
+	return norm
+}
+
+// fingerprint returns a short, non-reversible identifier for a secret so it
+// can be printed or logged without exposing the value.
+func fingerprint(secret string) string {
+	if secret == "" {
+		return "(not set)"
+	}
+	sum := sha256.Sum256([]byte(secret))
+	return "sha256:" + hex.EncodeToString(sum[:])[:12]
+}
+
+// parseKeyFingerprint normalizes an MCP_API_KEY_FINGERPRINT pin: a SHA-256
+// hex prefix of at least 8 digits, optionally written with the "sha256:"
+// prefix that fingerprint prints.
+func parseKeyFingerprint(v string) (string, error) {
+	pin := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(v)), "sha256:")
+	if len(pin) < 8 || len(pin) > 64 || strings.Trim(pin, "0123456789abcdef") != "" {
+		return "", fmt.Errorf("invalid MCP_API_KEY_FINGERPRINT %q: want 8 to 64 hex digits", v)
+	}
+	return pin, nil
+}
+
+// checkKeyFingerprint verifies a fetched key against the pinned fingerprint.
+// An empty pin accepts any key. Errors name only fingerprint prefixes.
+func checkKeyFingerprint(key, pin string) error {
+	if pin == "" {
+		return nil
+	}
+	sum := sha256.Sum256([]byte(key))
+	if !strings.HasPrefix(hex.EncodeToString(sum[:]), pin) {
+		return fmt.Errorf("fetched key %s does not match MCP_API_KEY_FINGERPRINT sha256:%s", fingerprint(key), pin)
+	}
+	return nil
+}
+
+// compileIfaceFilter compiles the NET_IFACE_INCLUDE and NET_IFACE_EXCLUDE
+// patterns. Unlike the other settings, a bad pattern is an error, so main can
+// fail fast instead of silently listing every interface.
+func (c *Config) compileIfaceFilter() error {
+	var err error
+	c.IfaceFilter, err = newInterfaceFilter(c.NetIfaceInclude, c.NetIfaceExclude)
+	return err
+}
+
+// sectionRetry is the system report retry policy from SYSTEM_INFO_RETRIES
+// and SYSTEM_INFO_RETRY_THRESHOLD.
+func (c *Config) sectionRetry() sectionRetry {
+	return sectionRetry{Attempts: c.SectionRetries, Threshold: c.RetryThreshold}
+}
+
+func (c *Config) entries() []configEntry {
+	apiKeySource := c.APIKeySource
+	if apiKeySource == "" {
+		apiKeySource = "(not set)"
+	}
+	projectID := c.ProjectID
+	if projectID == "" {
+		projectID = "(not set)"
+	}
+	keyPin := "(not set)"
+	if c.KeyFingerprint != "" {
+		keyPin = "sha256:" + c.KeyFingerprint
+	}
+	return []configEntry{
+		{"transport", "Transport", c.Transport},
+		{"auth_mode", "Auth Mode", c.AuthMode},
+		{"api_key", "API Key", fingerprint(c.APIKey)},
+		{"api_key_source", "API Key Source", apiKeySource},
+		{"api_key_fingerprint", "API Key Pin", keyPin},
+		{"project_id", "Project ID", projectID},
+		{"debug_timing", "Debug Timing", c.DebugTiming},
+		{"net_iface_include", "Iface Include", c.NetIfaceInclude},
+		{"net_iface_exclude", "Iface Exclude", c.NetIfaceExclude},
+		{"oui_file", "OUI File", c.OUIFile},
+		{"remote_binary", "Remote Binary", c.RemoteBinary},
+		{"log_cloud_logging", "Cloud Logging", c.CloudLogging},
+		{"max_result_bytes", "Max Result Bytes", c.MaxResultBytes},
+		{"report_signing_key", "Report Signing Key", fingerprint(c.ReportSigningKey)},
+		{"disk_min_total_mb", "Disk Min Total MB", c.DiskMinTotalMB},
+		{"system_info_retries", "System Info Retries", c.SectionRetries},
+		{"system_info_retry_threshold", "System Info Retry Threshold", c.RetryThreshold},
+		{"report_section_priority", "Report Section Priority", strings.Join(c.SectionPriority, ",")},
+		{"background_refresh", "Background Refresh", c.BackgroundRefresh},
+		{"background_refresh_interval", "Refresh Interval", c.RefreshInterval.String()},
+		{"max_sample_interval", "Max Sample Interval", c.MaxSampleInterval.String()},
+		{"allow_root", "Allow Root", c.AllowRoot},
+		{"offline", "Offline", c.Offline},
+	}
+}
+
+func formatConfig(c *Config, asJSON bool) (string, error) {
+	entries := c.entries()
+	if asJSON {
+		m := make(map[string]any, len(entries))
+		for _, e := range entries {
+			m[e.Key] = e.Value
+		}
+		out, err := json.MarshalIndent(m, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(out) + "\n", nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Effective Configuration\n")
+	sb.WriteString("=======================\n\n")
+	for _, e := range entries {
+		sb.WriteString(fmt.Sprintf("%-20s %v\n", e.Label+":", e.Value))
+	}
+	return sb.String(), nil
+}
+
+// envPositiveDuration reads a duration setting, keeping def when it is unset,
+// unparseable or not positive.
+func envPositiveDuration(name string, def time.Duration) time.Duration {
+	d, err := time.ParseDuration(os.Getenv(name))
+	if err != nil || d <= 0 {
+		return def
+	}
+	return d
+}
+
+// envRefreshInterval reads BACKGROUND_REFRESH_INTERVAL, keeping the default
+// when it is unset, unparseable or not positive.
+func envRefreshInterval() time.Duration {
+	d, err := time.ParseDuration(os.Getenv("BACKGROUND_REFRESH_INTERVAL"))
+	if err != nil || d <= 0 {
+		return defaultRefreshInterval
+	}
+	return d
+}
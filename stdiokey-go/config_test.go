@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfigKeyFlag(t *testing.T) {
+	t.Setenv("MCP_API_KEY", "")
+	cfg := loadConfig([]string{"stdiokey-go", "--key", "flag-key"})
+	if cfg.APIKey != "flag-key" || cfg.APIKeySource != "--key" {
+		t.Errorf("Expected key from --key flag, got key=%q source=%q", cfg.APIKey, cfg.APIKeySource)
+	}
+
+	t.Setenv("MCP_API_KEY", "env-key")
+	cfg = loadConfig([]string{"stdiokey-go", "--key", "flag-key"})
+	if cfg.APIKey != "env-key" || cfg.APIKeySource != "MCP_API_KEY" {
+		t.Errorf("Expected MCP_API_KEY to take precedence, got key=%q source=%q", cfg.APIKey, cfg.APIKeySource)
+	}
+
+	t.Setenv("MCP_API_KEY", " \"env-key\"\n")
+	if cfg = loadConfig([]string{"stdiokey-go"}); cfg.APIKey != "env-key" {
+		t.Errorf("Expected a quoted, padded MCP_API_KEY to be normalized, got %q", cfg.APIKey)
+	}
+}
+
+func TestFormatConfigRedactsSecrets(t *testing.T) {
+	cfg := &Config{Transport: "stdio", AuthMode: "apikey", APIKey: "super-secret-key"}
+
+	text, err := formatConfig(cfg, false)
+	if err != nil {
+		t.Fatalf("formatConfig returned error: %v", err)
+	}
+	if strings.Contains(text, "super-secret-key") {
+		t.Errorf("Expected text output to redact the API key, got: %s", text)
+	}
+	if !strings.Contains(text, fingerprint("super-secret-key")) {
+		t.Errorf("Expected text output to contain the key fingerprint, got: %s", text)
+	}
+
+	out, err := formatConfig(cfg, true)
+	if err != nil {
+		t.Fatalf("formatConfig returned error: %v", err)
+	}
+	var m map[string]any
+	if err := json.Unmarshal([]byte(out), &m); err != nil {
+		t.Fatalf("Expected valid JSON, got error: %v", err)
+	}
+	if strings.Contains(out, "super-secret-key") {
+		t.Errorf("Expected JSON output to redact the API key, got: %s", out)
+	}
+}
+
+func TestKeyFingerprintPin(t *testing.T) {
+	pin, err := parseKeyFingerprint(strings.ToUpper(fingerprint("right-key")))
+	if err != nil {
+		t.Fatalf("Expected a printed fingerprint to be accepted as a pin, got: %v", err)
+	}
+	if err := checkKeyFingerprint("right-key", pin); err != nil {
+		t.Errorf("Expected the pinned key to match, got: %v", err)
+	}
+	err = checkKeyFingerprint("wrong-key", pin)
+	if err == nil {
+		t.Fatal("Expected a different key to be rejected")
+	}
+	if strings.Contains(err.Error(), "wrong-key") {
+		t.Errorf("Expected the error to show only fingerprints, got: %v", err)
+	}
+	if err := checkKeyFingerprint("any-key", ""); err != nil {
+		t.Errorf("Expected no pin to accept any key, got: %v", err)
+	}
+
+	for _, bad := range []string{"abc", "sha256:not-hex!", strings.Repeat("a", 65)} {
+		if _, err := parseKeyFingerprint(bad); err == nil {
+			t.Errorf("Expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestNormalizeAPIKey(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"abc123", "abc123"},
+		{"  abc123\n", "abc123"},
+		{`"abc123"`, "abc123"},
+		{` 'abc123' `, "abc123"},
+		{`" abc123 "`, "abc123"},
+		{`"abc123'`, `"abc123'`},
+		{`"`, `"`},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := normalizeAPIKey("test", tt.in); got != tt.want {
+			t.Errorf("normalizeAPIKey(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/shirou/gopsutil/v3/host"
+	"github.com/shirou/gopsutil/v3/mem"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/apikeys/v2"
+)
+
+type doctorStatus string
+
+const (
+	doctorPass doctorStatus = "PASS"
+	doctorWarn doctorStatus = "WARN"
+	doctorFail doctorStatus = "FAIL"
+)
+
+// doctorCheck is the outcome of a single troubleshooting check. A FAIL is
+// critical and makes the doctor command exit non-zero; a WARN is not.
+type doctorCheck struct {
+	Name   string
+	Status doctorStatus
+	Detail string
+	Remedy string
+}
+
+// doctorKeyFetchTimeout bounds the key fetch so a hung gcloud or API call
+// cannot stall the report.
+const doctorKeyFetchTimeout = 10 * time.Second
+
+// runDoctor runs every check in order. Later checks reuse what earlier ones
+// resolved, e.g. the key fetch uses the project found by the project check.
+func runDoctor(ctx context.Context, cfg *Config) []doctorCheck {
+	projectID := cfg.ProjectID
+	if projectID == "" {
+		projectID = cfg.fetchProject()
+	}
+
+	checks := []doctorCheck{checkProvidedKey(cfg)}
+	if cfg.Offline {
+		checks = append(checks, offlineCheck())
+	} else {
+		checks = append(checks, checkProject(projectID, true))
+		checks = append(checks, checkGcloud(), checkADC(ctx))
+		checks = append(checks, checkKeyFetch(ctx, cfg, projectID, true))
+	}
+	checks = append(checks, checkSystemAccess())
+	return checks
+}
+
+// offlineCheck stands in for the project, gcloud, credential and key fetch
+// checks, which all need the network, when OFFLINE is set.
+func offlineCheck() doctorCheck {
+	return doctorCheck{Name: "Cloud checks", Status: doctorWarn, Detail: "skipped: OFFLINE=true", Remedy: "Unset OFFLINE to check the project, gcloud, credentials and key fetch"}
+}
+
+func checkProvidedKey(cfg *Config) doctorCheck {
+	if cfg.APIKey == "" {
+		return doctorCheck{
+			Name:   "API key provided",
+			Status: doctorFail,
+			Detail: "no key in MCP_API_KEY or --key",
+			Remedy: "Set MCP_API_KEY or pass --key <key>",
+		}
+	}
+	return doctorCheck{Name: "API key provided", Status: doctorPass, Detail: fmt.Sprintf("%s (%s)", cfg.APIKeySource, fingerprint(cfg.APIKey))}
+}
+
+func checkProject(projectID string, required bool) doctorCheck {
+	if projectID != "" {
+		return doctorCheck{Name: "Project resolution", Status: doctorPass, Detail: projectID}
+	}
+	c := doctorCheck{
+		Name:   "Project resolution",
+		Status: doctorWarn,
+		Detail: "no project in GOOGLE_CLOUD_PROJECT or gcloud config",
+		Remedy: "Set GOOGLE_CLOUD_PROJECT or run: gcloud config set project <id>",
+	}
+	if required {
+		c.Status = doctorFail
+	}
+	return c
+}
+
+func checkADC(ctx context.Context) doctorCheck {
+	c := doctorCheck{Name: "Application Default Credentials", Status: doctorPass, Detail: "found"}
+	if _, err := google.FindDefaultCredentials(ctx, apikeys.CloudPlatformScope); err != nil {
+		c.Status, c.Detail = doctorWarn, "not found"
+		c.Remedy = "Run: gcloud auth application-default login"
+	}
+	return c
+}
+
+func checkGcloud() doctorCheck {
+	path, err := exec.LookPath("gcloud")
+	if err != nil {
+		return doctorCheck{
+			Name:   "gcloud CLI",
+			Status: doctorWarn,
+			Detail: "not found on PATH",
+			Remedy: "Install the Google Cloud SDK; it is the preferred key fetch method",
+		}
+	}
+	return doctorCheck{Name: "gcloud CLI", Status: doctorPass, Detail: path}
+}
+
+func checkKeyFetch(ctx context.Context, cfg *Config, projectID string, required bool) doctorCheck {
+	c := doctorCheck{Name: "API key fetch"}
+	if projectID == "" {
+		c.Status, c.Detail = doctorWarn, "skipped: no project"
+		if required {
+			c.Status = doctorFail
+		}
+		return c
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, doctorKeyFetchTimeout)
+	defer cancel()
+	key, err := fetchMCPAPIKey(ctx, projectID)
+	pinErr := checkKeyFingerprint(key, cfg.KeyFingerprint)
+	switch {
+	case err != nil:
+		c.Status, c.Detail = doctorWarn, err.Error()
+		c.Remedy = "Create an API key named 'MCP API Key' and grant access to list it (roles/serviceusage.apiKeysViewer)"
+		if required {
+			c.Status = doctorFail
+		}
+	case pinErr != nil:
+		c.Status, c.Detail = doctorFail, pinErr.Error()
+		c.Remedy = "Check that GOOGLE_CLOUD_PROJECT is the intended project, or update MCP_API_KEY_FINGERPRINT after a key rotation"
+	case cfg.APIKey != "" && cfg.APIKey != key:
+		c.Status, c.Detail = doctorFail, "provided key does not match the key in the project"
+		c.Remedy = "Use the project's 'MCP API Key' for MCP_API_KEY or --key"
+	default:
+		c.Status, c.Detail = doctorPass, "MCP API Key found ("+fingerprint(key)+")"
+	}
+	return c
+}
+
+func checkSystemAccess() doctorCheck {
+	c := doctorCheck{Name: "System metrics access", Status: doctorPass, Detail: "host, memory and disk readable"}
+	var failed []string
+	if _, err := host.Info(); err != nil {
+		failed = append(failed, "host: "+err.Error())
+	}
+	if _, err := mem.VirtualMemory(); err != nil {
+		failed = append(failed, "memory: "+err.Error())
+	}
+	if _, err := disk.Partitions(false); err != nil {
+		failed = append(failed, "disk: "+err.Error())
+	}
+	if len(failed) > 0 {
+		c.Status, c.Detail = doctorFail, strings.Join(failed, "; ")
+		c.Remedy = "Ensure /proc and /sys are mounted and readable by this user"
+	}
+	return c
+}
+
+// formatDoctor renders the checklist and reports whether any critical check
+// failed.
+func formatDoctor(checks []doctorCheck) (string, bool) {
+	var sb strings.Builder
+	sb.WriteString("Doctor Report\n")
+	sb.WriteString("=============\n\n")
+
+	counts := map[doctorStatus]int{}
+	for _, c := range checks {
+		counts[c.Status]++
+		sb.WriteString(fmt.Sprintf("[%s] %-32s %s\n", c.Status, c.Name, c.Detail))
+		if c.Status != doctorPass && c.Remedy != "" {
+			sb.WriteString(fmt.Sprintf("       -> %s\n", c.Remedy))
+		}
+	}
+	sb.WriteString(fmt.Sprintf("\n%d passed, %d warnings, %d failed\n",
+		counts[doctorPass], counts[doctorWarn], counts[doctorFail]))
+	return sb.String(), counts[doctorFail] > 0
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatDoctor(t *testing.T) {
+	out, failed := formatDoctor([]doctorCheck{
+		{Name: "ok", Status: doctorPass, Remedy: "hidden remedy"},
+		{Name: "soft", Status: doctorWarn, Remedy: "warn remedy"},
+	})
+	if failed {
+		t.Error("Expected warnings alone not to count as a failure")
+	}
+	if strings.Contains(out, "hidden remedy") || !strings.Contains(out, "warn remedy") {
+		t.Errorf("Expected remedies only for checks that did not pass, got: %s", out)
+	}
+
+	_, failed = formatDoctor([]doctorCheck{{Name: "bad", Status: doctorFail}})
+	if !failed {
+		t.Error("Expected a FAIL check to be reported as a failure")
+	}
+}
+
+func TestCheckProvidedKey(t *testing.T) {
+	if c := checkProvidedKey(&Config{}); c.Status != doctorFail {
+		t.Errorf("Expected FAIL without a key, got %s", c.Status)
+	}
+	c := checkProvidedKey(&Config{APIKey: "secret", APIKeySource: "--key"})
+	if c.Status != doctorPass || strings.Contains(c.Detail, "secret") {
+		t.Errorf("Expected PASS with a redacted key, got %s: %s", c.Status, c.Detail)
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestListProcesses(t *testing.T) {
+	orig := processRows
+	defer func() { processRows = orig }()
+
+	processRows = func(context.Context) ([]processRow, error) {
+		return []processRow{
+			{PID: 30, Name: "worker", RSS: 512 << 20, CPU: 2.5},
+			{PID: 1, Name: "init", RSS: 8 << 20, CPU: 0.1},
+			{PID: 12, Name: "server", RSS: 2 << 30, CPU: 40},
+			{PID: 7, Name: "idle", RSS: 8 << 20, CPU: 0},
+		}, nil
+	}
+	order := func(out string) []string {
+		var names []string
+		for _, line := range strings.Split(out, "\n") {
+			if f := strings.Fields(line); len(f) == 5 && f[0] != "PID" {
+				names = append(names, f[1])
+			}
+		}
+		return names
+	}
+
+	for _, tt := range []struct {
+		sortBy string
+		limit  int
+		want   string
+	}{
+		{"", 0, "server worker init idle"},
+		{"mem", 2, "server worker"},
+		{"cpu", 0, "server worker init idle"},
+		{"pid", 3, "init idle server"},
+	} {
+		out := listProcesses(context.Background(), tt.sortBy, tt.limit)
+		if got := strings.Join(order(out), " "); got != tt.want {
+			t.Errorf("sort_by %q limit %d: got %q, want %q in:\n%s", tt.sortBy, tt.limit, got, tt.want, out)
+		}
+	}
+	if out := listProcesses(context.Background(), "mem", 2); !strings.Contains(out, "showing 2 of 4 processes") || !strings.Contains(out, "2.0 GiB") {
+		t.Errorf("Unexpected report:\n%s", out)
+	}
+
+	processRows = func(context.Context) ([]processRow, error) { return nil, errors.New("no /proc") }
+	if out := listProcesses(context.Background(), "", 0); !strings.Contains(out, "Error retrieving processes: no /proc") {
+		t.Errorf("Expected an error line, got:\n%s", out)
+	}
+}
@@ -2,20 +2,19 @@ package main
 
 import (
 	"context"
+	"crypto/subtle"
 	"fmt"
 	"log/slog"
 	"os"
 	"os/exec"
 	"runtime"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
-	"github.com/shirou/gopsutil/v3/cpu"
-	"github.com/shirou/gopsutil/v3/disk"
-	"github.com/shirou/gopsutil/v3/host"
-	"github.com/shirou/gopsutil/v3/mem"
-	"github.com/shirou/gopsutil/v3/net"
 	"google.golang.org/api/apikeys/v2"
 	"google.golang.org/api/option"
 )
@@ -24,15 +23,19 @@ func getProjectID() string {
 	if projectID := os.Getenv("GOOGLE_CLOUD_PROJECT"); projectID != "" {
 		return projectID
 	}
+	return gcloudProjectID()
+}
 
-	// Try gcloud config
+// gcloudProjectID shells out to gcloud at most once per process; the active
+// project does not change at runtime and several CLI steps need it. An empty
+// result (gcloud missing or unconfigured) is kept as well.
+var gcloudProjectID = sync.OnceValue(func() string {
 	out, err := exec.Command("gcloud", "config", "get-value", "project").Output()
 	if err == nil {
 		return strings.TrimSpace(string(out))
 	}
-
 	return ""
-}
+})
 
 func fetchMCPAPIKeyGcloud(projectID string) (string, error) {
 	out, err := exec.Command("gcloud", "services", "api-keys", "list",
@@ -94,14 +97,61 @@ func fetchMCPAPIKey(ctx context.Context, projectID string) (string, error) {
 	key, err := fetchMCPAPIKeyGcloud(projectID)
 	if err == nil {
 		slog.Info("Successfully fetched API key via gcloud")
-		return key, nil
+		return normalizeAPIKey("fetched", key), nil
 	}
 
 	slog.Info("Falling back to library-based API key fetch", "error", err)
-	return fetchMCPAPIKeyLibrary(ctx, projectID)
+	key, err = fetchMCPAPIKeyLibrary(ctx, projectID)
+	if err != nil {
+		return "", err
+	}
+	return normalizeAPIKey("fetched", key), nil
+}
+
+// systemInfoOptions controls what the system report includes. The zero value
+// waits for every section, lists only interfaces that carried traffic, with no
+// name filter, does not sample swap activity, retry failed sections or
+// resolve MAC vendors or break memory down, prints timestamps in UTC as plain text and has no size
+// budget.
+type systemInfoOptions struct {
+	SoftDeadline time.Duration
+	IncludeIdle  bool
+	Location     *time.Location
+	Interfaces   interfaceFilter
+	SwapSample   time.Duration
+	Markdown     bool
+	Retry        sectionRetry
+	Vendors      bool
+	MemoryDetail bool
+	Budget       reportBudget
+	Priority     []string
+	Source       MetricsSource
 }
 
-func collectSystemInfo(apiStatus string) string {
+// systemSections returns the parts of the system report, collected
+// concurrently and rendered in this order.
+func systemSections(opts systemInfoOptions) []reportSection {
+	src := metricsSourceOr(opts.Source)
+	return []reportSection{
+		{"Host", func() (string, error) { return hostSection(src, opts.Location) }},
+		{"CPU", func() (string, error) { return cpuSection(src) }},
+		{"Memory", func() (string, error) { return memorySection(src, opts.SwapSample, opts.MemoryDetail) }},
+		{"Network", func() (string, error) { return networkSection(src, opts.IncludeIdle, opts.Interfaces, opts.Vendors) }},
+		{"Power", powerSection},
+	}
+}
+
+// systemSectionNames are the names of systemSections, which
+// REPORT_SECTION_PRIORITY orders.
+var systemSectionNames = []string{"Host", "CPU", "Memory", "Network", "Power"}
+
+// collectSystemInfo renders the system report. With a non-zero SoftDeadline,
+// sections not finished by then are left out and the report says so. With a
+// Budget, the least important sections by Priority are left out to fit it,
+// as fitSections describes. With Markdown set, the report is converted by
+// markdownReport.
+func collectSystemInfo(ctx context.Context, apiStatus string, debugTiming bool, opts systemInfoOptions) string {
+	timer := newSectionTimer(debugTiming)
 	var sb strings.Builder
 	sb.WriteString("System Information Report\n")
 	sb.WriteString("=========================\n\n")
@@ -110,92 +160,144 @@ func collectSystemInfo(apiStatus string) string {
 		sb.WriteString(apiStatus + "\n")
 	}
 
-	hInfo, _ := host.Info()
+	header := sb.String()
+	results := collectSectionsRetry(ctx, systemSections(opts), opts.SoftDeadline, opts.Retry)
+	for _, r := range results {
+		if r.done {
+			timer.record(r.name, r.duration)
+		}
+	}
+	return fitSections(results, opts.Priority, opts.Budget, func(kept []sectionResult, omitted []string) string {
+		var out strings.Builder
+		out.WriteString(header)
+		for _, r := range kept {
+			if r.done {
+				out.WriteString(r.text)
+			}
+		}
+		out.WriteString(truncationNote(kept, opts.SoftDeadline))
+		out.WriteString(budgetNote(omitted))
+		out.WriteString(timer.report())
+		if opts.Markdown {
+			return markdownReport(out.String())
+		}
+		return out.String()
+	})
+}
+
+func hostSection(src MetricsSource, loc *time.Location) (string, error) {
+	var sb strings.Builder
+	hInfo, err := src.HostInfo()
 	sb.WriteString("System Information\n")
 	sb.WriteString("------------------\n")
 	sb.WriteString(fmt.Sprintf("System Name:      %s\n", runtime.GOOS))
-	sb.WriteString(fmt.Sprintf("OS Name:          %s\n", hInfo.OS))
-	sb.WriteString(fmt.Sprintf("Host Name:        %s\n", hInfo.Hostname))
+	if hInfo != nil {
+		sb.WriteString(fmt.Sprintf("OS Name:          %s\n", hInfo.OS))
+		sb.WriteString(fmt.Sprintf("Host Name:        %s\n", hInfo.Hostname))
+		sb.WriteString(fmt.Sprintf("Boot Time:        %s\n", formatTimestamp(time.Unix(int64(hInfo.BootTime), 0), loc)))
+		sb.WriteString(fmt.Sprintf("Server Time:      %s\n", formatTimestamp(time.Now(), loc)))
+	}
 	sb.WriteString("\n")
+	return sb.String(), err
+}
 
-	cpuCount, _ := cpu.Counts(true)
+func cpuSection(src MetricsSource) (string, error) {
+	var sb strings.Builder
+	cpuCount, err := src.CPUCounts(true)
 	sb.WriteString("CPU Information\n")
 	sb.WriteString("---------------\n")
 	sb.WriteString(fmt.Sprintf("Number of Cores:  %d\n", cpuCount))
+	if vcpu, ok := cgroupCPULimit(); ok {
+		sb.WriteString(fmt.Sprintf("Allocated CPU:    %.2f vCPU (cgroup quota)\n", vcpu))
+	}
 	sb.WriteString("\n")
+	return sb.String(), err
+}
 
-	vMem, _ := mem.VirtualMemory()
-	sMem, _ := mem.SwapMemory()
+// memorySection renders the Memory section. With detail set, used memory is
+// broken down by memoryBreakdown.
+func memorySection(src MetricsSource, swapSample time.Duration, detail bool) (string, error) {
+	var sb strings.Builder
+	vMem, err := src.VirtualMemory()
+	sMem, _ := src.SwapMemory()
 	sb.WriteString("Memory Information\n")
 	sb.WriteString("------------------\n")
-	sb.WriteString(fmt.Sprintf("Total Memory:     %d MB\n", vMem.Total/(1024*1024)))
-	sb.WriteString(fmt.Sprintf("Used Memory:      %d MB\n", vMem.Used/(1024*1024)))
-	sb.WriteString(fmt.Sprintf("Total Swap:       %d MB\n", sMem.Total/(1024*1024)))
-	sb.WriteString(fmt.Sprintf("Used Swap:        %d MB\n", sMem.Used/(1024*1024)))
+	if vMem != nil {
+		sb.WriteString(fmt.Sprintf("Total Memory:     %s\n", formatBytes(vMem.Total, unitsIEC)))
+		sb.WriteString(fmt.Sprintf("Used Memory:      %s\n", formatBytes(vMem.Used, unitsIEC)))
+		if detail {
+			sb.WriteString(memoryBreakdown(vMem))
+		}
+	}
+	if sMem != nil {
+		sb.WriteString(fmt.Sprintf("Total Swap:       %s\n", formatBytes(sMem.Total, unitsIEC)))
+		sb.WriteString(fmt.Sprintf("Used Swap:        %s\n", formatBytes(sMem.Used, unitsIEC)))
+	}
+	if swapSample > 0 {
+		sb.WriteString(swapActivity(swapSample))
+	}
 	sb.WriteString("\n")
+	return sb.String(), err
+}
 
+func networkSection(src MetricsSource, includeIdle bool, filter interfaceFilter, resolveVendor bool) (string, error) {
+	var sb strings.Builder
 	sb.WriteString("Network Interfaces\n")
 	sb.WriteString("------------------\n")
-	interfaces, _ := net.Interfaces()
-	ioCounters, _ := net.IOCounters(true)
-	for _, iface := range interfaces {
-		mac := iface.HardwareAddr
-		if mac == "" {
-			mac = "unknown"
-		}
-
-		var rx, tx uint64
-		found := false
-		for _, io := range ioCounters {
-			if io.Name == iface.Name {
-				rx = io.BytesRecv
-				tx = io.BytesSent
-				found = true
-				break
-			}
-		}
-
-		if found {
-			sb.WriteString(fmt.Sprintf("%-18s: RX: %10d bytes, TX: %10d bytes (MAC: %s)\n", iface.Name, rx, tx, mac))
-		} else {
-			sb.WriteString(fmt.Sprintf("%-18s: (No IO stats) (MAC: %s)\n", iface.Name, mac))
-		}
+	interfaces, err := collectInterfaces(src)
+	if resolveVendor {
+		resolveVendors(interfaces)
 	}
-
-	return sb.String()
+	sb.WriteString(filter.format(interfaces, includeIdle))
+	return sb.String(), err
 }
 
-func collectDiskUsage() string {
+func collectDiskUsage(opts diskReportOptions) string {
 	var sb strings.Builder
 	sb.WriteString("Disk Usage Report\n")
 	sb.WriteString("=================\n\n")
 
-	parts, _ := disk.Partitions(false)
+	parts, _ := reportPartitions(opts)
 	for _, part := range parts {
-		usage, err := disk.Usage(part.Mountpoint)
-		if err != nil {
+		if part.Error != "" {
 			continue
 		}
-		usedMB := usage.Used / (1024 * 1024)
-		totalMB := usage.Total / (1024 * 1024)
-		sb.WriteString(fmt.Sprintf("%-20s %-10s %10d / %10d MB used (%.1f%%)\n",
-			part.Mountpoint, part.Fstype, usedMB, totalMB, usage.UsedPercent))
+		sb.WriteString(part.reportLine(opts) + "\n")
+	}
+	if len(parts) == 0 {
+		sb.WriteString(noPartitionsNote)
 	}
 
 	return sb.String()
 }
 
-func checkAPIKeyStatus(ctx context.Context, args []string) (string, bool) {
+// offlineKeyStatus reports whether an API key was provided locally, for
+// check --offline. It makes no network calls, so it cannot tell whether the
+// key matches the project's key.
+func offlineKeyStatus(providedKey string) (string, bool) {
+	status := "Provided Key: [NOT FOUND]"
+	if providedKey != "" {
+		status = "Provided Key: [FOUND]"
+	}
+	return status + "\nCloud Match: [SKIPPED] (--offline)", providedKey != ""
+}
+
+func checkAPIKeyStatus(ctx context.Context, cfg *Config) (string, bool) {
 	var sb strings.Builder
 	sb.WriteString("MCP API Key Status\n")
 	sb.WriteString("------------------\n")
 	isValid := false
 
-	projectID := getProjectID()
+	projectID := cfg.fetchProject()
 	expectedKey := ""
-	if projectID != "" {
+	if cfg.Offline {
+		sb.WriteString("Cloud Match:      [SKIPPED] (OFFLINE)\n")
+	} else if projectID != "" {
 		sb.WriteString(fmt.Sprintf("Cloud Project:    %s\n", projectID))
 		key, err := fetchMCPAPIKey(ctx, projectID)
+		if err == nil {
+			err = checkKeyFingerprint(key, cfg.KeyFingerprint)
+		}
 		if err == nil {
 			expectedKey = key
 			sb.WriteString("Cloud Match:      [EXPECTED KEY FETCHED]\n")
@@ -206,20 +308,16 @@ func checkAPIKeyStatus(ctx context.Context, args []string) (string, bool) {
 		sb.WriteString("Cloud Match:      [ERROR: Project ID not found]\n")
 	}
 
-	providedKey := os.Getenv("MCP_API_KEY")
-	if providedKey == "" {
-		for i, arg := range args {
-			if arg == "--key" && i+1 < len(args) {
-				providedKey = args[i+1]
-				break
-			}
-		}
-	}
-
+	providedKey := cfg.APIKey
 	if providedKey != "" {
 		sb.WriteString("Provided Key:     [FOUND]\n")
+		if cfg.Offline {
+			// Offline the provided key is the only one there is
+			sb.WriteString("Key Validation:   [ACCEPTED: OFFLINE]\n")
+			isValid = true
+		}
 		if expectedKey != "" {
-			if providedKey == expectedKey {
+			if subtle.ConstantTimeCompare([]byte(providedKey), []byte(expectedKey)) == 1 {
 				sb.WriteString("Key Validation:   [SUCCESS]\n")
 				isValid = true
 			} else {
@@ -242,30 +340,167 @@ func isTTY() bool {
 	return (fi.Mode() & os.ModeCharDevice) != 0
 }
 
+// ttyStatusOnly reports whether a run without a command should print the key
+// status and exit instead of serving. That is the case on a terminal, where no
+// MCP host is attached, unless --serve asks for the server anyway.
+func ttyStatusOnly(noCommand, serve, tty bool) bool {
+	return noCommand && tty && !serve
+}
+
 func main() {
-	slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stderr, nil)))
+	slog.SetDefault(slog.New(newLogHandler(os.Stderr, cloudLoggingEnabled())))
 	ctx := context.Background()
 	args := os.Args[1:]
 
+	cfg := loadConfig(os.Args)
+	// .env may have set LOG_CLOUD_LOGGING after the logger was created
+	slog.SetDefault(slog.New(newLogHandler(os.Stderr, cfg.CloudLogging)))
+	logOffline(cfg.Offline)
+	if err := cfg.compileIfaceFilter(); err != nil {
+		slog.Error("Invalid configuration", "error", err)
+		os.Exit(exitConfig)
+	}
+	if err := loadOUIFile(cfg.OUIFile); err != nil {
+		slog.Error("Invalid configuration", "error", err)
+		os.Exit(exitConfig)
+	}
+
 	hasInfo := false
 	hasDisk := false
 	hasCheck := false
-
-	for _, arg := range args {
+	offline := false
+	hasConfig := false
+	hasDoctor := false
+	asJSON := false
+	showDevice := false
+	keepDuplicates := false
+	exactBytes := false
+	resolveVendor := false
+	memoryDetail := false
+	hasHelp := false
+	hasVersion := false
+	serve := false
+	unknown := ""
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
 		if arg == "info" {
 			hasInfo = true
 		} else if arg == "disk" {
 			hasDisk = true
 		} else if arg == "check" {
 			hasCheck = true
+		} else if arg == "config" {
+			hasConfig = true
+		} else if arg == "doctor" {
+			hasDoctor = true
+		} else if arg == "--json" {
+			asJSON = true
+		} else if arg == "--show-device" {
+			showDevice = true
+		} else if arg == "--keep-duplicates" {
+			keepDuplicates = true
+		} else if arg == "--exact-bytes" {
+			exactBytes = true
+		} else if arg == "--resolve-vendor" {
+			resolveVendor = true
+		} else if arg == "--memory-detail" {
+			memoryDetail = true
+		} else if arg == "--offline" {
+			offline = true
+		} else if arg == "--serve" {
+			serve = true
+		} else if arg == "--key" {
+			// The key itself is read by loadConfig
+			i++
+		} else if arg == "--remote" {
+			// The target is read by splitRemote
+			i++
+		} else if isHelp(arg) {
+			hasHelp = true
+		} else if isVersion(arg) {
+			hasVersion = true
+		} else if !strings.HasPrefix(arg, "-") && unknown == "" {
+			unknown = arg
 		}
 	}
 
+	if hasHelp {
+		printUsage(os.Stdout)
+		return
+	}
+	if hasVersion {
+		printVersion(os.Stdout, "stdiokey-go")
+		return
+	}
+	if unknown != "" {
+		fmt.Fprintf(os.Stderr, "Unknown command: %s\n\n", unknown)
+		printUsage(os.Stderr)
+		os.Exit(exitConfig)
+	}
+
+	if hasInfo || hasDisk {
+		target, forward, err := splitRemote(args)
+		if err != nil {
+			slog.Error("Invalid configuration", "error", err)
+			os.Exit(exitConfig)
+		}
+		if target != "" && cfg.Offline {
+			slog.Error("Invalid configuration", "error", "--remote is disabled by OFFLINE=true")
+			os.Exit(exitConfig)
+		}
+		if target != "" {
+			command := "disk"
+			if hasInfo {
+				command = "info"
+			}
+			os.Exit(runRemote(target, cfg.RemoteBinary, append([]string{command}, forward...)))
+		}
+	}
+
+	// Printing the configuration needs no authentication or cloud access
+	if hasConfig {
+		if cfg.ProjectID == "" {
+			cfg.ProjectID = cfg.fetchProject()
+		}
+		out, err := formatConfig(cfg, asJSON)
+		if err != nil {
+			slog.Error("Failed to format configuration", "error", err)
+			os.Exit(exitFailure)
+		}
+		fmt.Print(out)
+		return
+	}
+
+	// The doctor runs its own key checks and reports them instead of exiting
+	if hasDoctor {
+		out, failed := formatDoctor(runDoctor(ctx, cfg))
+		fmt.Print(out)
+		if failed {
+			os.Exit(exitFailure)
+		}
+		return
+	}
+
+	// check --offline skips the project lookup and cloud key fetch entirely
+	if hasCheck && offline {
+		status, found := offlineKeyStatus(cfg.APIKey)
+		fmt.Printf("MCP API Key Status\n------------------\n%s\n", status)
+		if !found {
+			fmt.Println("Authentication Failed: Missing API Key.")
+			fmt.Println("Please set MCP_API_KEY environment variable or use --key flag.")
+			os.Exit(exitAuth)
+		}
+		fmt.Println("Key Provided (cloud match not checked).")
+		return
+	}
+
 	// Always check API key status
-	status, isValid := checkAPIKeyStatus(ctx, os.Args)
+	status, isValid := checkAPIKeyStatus(ctx, cfg)
 
-	// If called directly (TTY) with no args or 'check'
-	if (len(args) == 0 || hasCheck) && isTTY() {
+	// On a terminal, 'check' and a bare run print the key status
+	statusOnly := ttyStatusOnly(!hasInfo && !hasDisk && !hasCheck, serve, isTTY())
+	if hasCheck && isTTY() || statusOnly {
 		fmt.Print(status)
 		if isValid {
 			fmt.Println("Authentication Verified: Server is ready to be used by an MCP host.")
@@ -275,14 +510,12 @@ func main() {
 		}
 		if hasCheck {
 			if isValid {
-				os.Exit(0)
+				os.Exit(exitOK)
 			}
-			os.Exit(1)
-		}
-		// If no args and valid, we still exit because it's a TTY
-		if len(args) == 0 {
-			return
+			os.Exit(exitAuth)
 		}
+		fmt.Println("Not starting the server: stdin is a terminal, so no MCP host is attached. Use --serve to start it anyway.")
+		return
 	}
 
 	if !isValid {
@@ -292,7 +525,7 @@ func main() {
 		} else {
 			slog.Error("Authentication Failed", "reason", "Invalid or missing API Key", "status", status)
 		}
-		os.Exit(1)
+		os.Exit(exitAuth)
 	}
 
 	if hasCheck {
@@ -301,39 +534,208 @@ func main() {
 	}
 
 	if hasInfo {
-		fmt.Print(collectSystemInfo(status))
+		fmt.Print(collectSystemInfo(ctx, status, cfg.DebugTiming, systemInfoOptions{IncludeIdle: true, Interfaces: cfg.IfaceFilter, Retry: cfg.sectionRetry(), Vendors: resolveVendor, MemoryDetail: memoryDetail}))
 		return
 	}
 
 	if hasDisk {
-		fmt.Print(collectDiskUsage())
+		format := "text"
+		if asJSON {
+			format = "json"
+		}
+		report, err := diskUsageReport(format, diskReportOptions{ShowDevice: showDevice, KeepDuplicates: keepDuplicates, MinTotalMB: cfg.DiskMinTotalMB, ExactBytes: exactBytes})
+		if err != nil {
+			slog.Error("Failed to collect disk usage", "error", err)
+			os.Exit(exitCollection)
+		}
+		fmt.Print(report)
 		return
 	}
 
 	// Server mode
+	if err := checkRoot(cfg.AllowRoot); err != nil {
+		slog.Error("Refusing to start", "error", err)
+		os.Exit(exitConfig)
+	}
 	slog.Info("Authentication Verified", "status", "MATCHED")
 
 	s := server.NewMCPServer(
 		"stdiokey-go",
 		"1.0.0",
+		server.WithToolHandlerMiddleware(reportSigningMiddleware(cfg.ReportSigningKey)),
+		server.WithToolHandlerMiddleware(resultSizeMiddleware(cfg.MaxResultBytes)),
 	)
 
 	s.AddTool(mcp.NewTool("local_system_info",
 		mcp.WithDescription("Get a detailed system information report including kernel, cores, and memory usage."),
+		mcp.WithNumber("soft_deadline_ms",
+			mcp.Description("Return the sections finished after this many milliseconds instead of waiting for all of them; 0 (default) waits"),
+		),
+		mcp.WithBoolean("include_idle",
+			mcp.Description("Also list network interfaces with no RX or TX traffic"),
+		),
+		mcp.WithBoolean("resolve_vendor",
+			mcp.Description("Annotate each interface MAC address with its vendor from the OUI table"),
+		),
+		mcp.WithBoolean("memory_detail",
+			mcp.Description("Break used memory down into free, available, buffers, cached and shared where the platform reports them"),
+		),
+		mcp.WithString("timezone",
+			mcp.Description("IANA time zone for timestamps, e.g. Europe/Berlin (default UTC)"),
+		),
+		mcp.WithString("iface_include",
+			mcp.Description("Only list network interfaces whose name matches this regular expression (overrides NET_IFACE_INCLUDE)"),
+		),
+		mcp.WithString("iface_exclude",
+			mcp.Description("Hide network interfaces whose name matches this regular expression (overrides NET_IFACE_EXCLUDE)"),
+		),
+		mcp.WithNumber("swap_sample_ms",
+			mcp.Description("Sample swap-in/out rates over this many milliseconds (at most MAX_SAMPLE_INTERVAL, default 5000, and never over 10000) and flag active swapping; 0 (default) skips"),
+			mcp.Min(0),
+			mcp.Max(10000),
+		),
+		mcp.WithString("format",
+			mcp.Description("Output format: text (default) or markdown"),
+			mcp.Enum("text", "markdown"),
+		),
+		mcp.WithNumber("max_lines",
+			mcp.Description("Fit the report into this many lines, leaving out the least important sections first; 0 (default) is unlimited"),
+			mcp.Min(0),
+		),
+		mcp.WithNumber("max_chars",
+			mcp.Description("Fit the report into this many characters, leaving out the least important sections first; 0 (default) is unlimited"),
+			mcp.Min(0),
+		),
 	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		return mcp.NewToolResultText(collectSystemInfo("Authentication:   [VERIFIED] (Running as MCP Server)\n")), nil
+		format := request.GetString("format", "text")
+		if format != "text" && format != "markdown" {
+			return mcp.NewToolResultError(fmt.Sprintf("unsupported format %q: must be text or markdown", format)), nil
+		}
+		loc, err := parseTimezone(request.GetString("timezone", ""))
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		filter, err := cfg.IfaceFilter.withOverrides(request.GetString("iface_include", ""), request.GetString("iface_exclude", ""))
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		swapSampleMS := request.GetInt("swap_sample_ms", 0)
+		if swapSampleMS < 0 || int64(swapSampleMS) > maxSwapSample.Milliseconds() {
+			return mcp.NewToolResultError(fmt.Sprintf("swap_sample_ms %d must be between 0 and %d", swapSampleMS, maxSwapSample.Milliseconds())), nil
+		}
+		if err := checkSampleInterval("swap_sample_ms", swapSampleMS, cfg.MaxSampleInterval); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		budget := reportBudget{MaxLines: request.GetInt("max_lines", 0), MaxChars: request.GetInt("max_chars", 0)}
+		if budget.MaxLines < 0 || budget.MaxChars < 0 {
+			return mcp.NewToolResultError("max_lines and max_chars must not be negative"), nil
+		}
+		opts := systemInfoOptions{
+			SoftDeadline: time.Duration(max(request.GetInt("soft_deadline_ms", 0), 0)) * time.Millisecond,
+			IncludeIdle:  request.GetBool("include_idle", false),
+			Location:     loc,
+			Interfaces:   filter,
+			SwapSample:   time.Duration(swapSampleMS) * time.Millisecond,
+			Markdown:     format == "markdown",
+			Retry:        cfg.sectionRetry(),
+			Vendors:      request.GetBool("resolve_vendor", false),
+			MemoryDetail: request.GetBool("memory_detail", false),
+			Budget:       budget,
+			Priority:     cfg.SectionPriority,
+		}
+		return mcp.NewToolResultText(collectSystemInfo(ctx, "Authentication:   [VERIFIED] (Running as MCP Server)\n", cfg.DebugTiming, opts)), nil
+	})
+
+	s.AddTool(mcp.NewTool("system_info_json",
+		mcp.WithDescription("Get host, CPU, memory, swap and network interface details as one JSON object with a collected_at timestamp."),
+		mcp.WithBoolean("include_idle",
+			mcp.Description("Also list network interfaces with no RX or TX traffic"),
+		),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		report, err := systemInfoJSONReport(systemInfoOptions{IncludeIdle: request.GetBool("include_idle", false), Interfaces: cfg.IfaceFilter})
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return mcp.NewToolResultText(report), nil
+	})
+
+	s.AddTool(mcp.NewTool("cpu_load",
+		mcp.WithDescription("Get aggregate and per-core CPU utilization sampled over an interval."),
+		mcp.WithNumber("interval_ms",
+			mcp.Description("Sample utilization over this many milliseconds (default 1000, at most MAX_SAMPLE_INTERVAL)"),
+			mcp.Min(0),
+		),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		intervalMS := request.GetInt("interval_ms", 0)
+		if err := checkSampleInterval("interval_ms", intervalMS, cfg.MaxSampleInterval); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return mcp.NewToolResultText(cpuLoadReport(ctx, cpuLoadInterval(intervalMS, cfg.MaxSampleInterval))), nil
+	})
+
+	s.AddTool(mcp.NewTool("list_processes",
+		mcp.WithDescription("List running processes with PID, name, resident memory and CPU percent."),
+		mcp.WithString("sort_by",
+			mcp.Description("Order to list processes in: mem (default, largest resident memory first), cpu or pid"),
+			mcp.Enum(listProcessSortKeys...),
+		),
+		mcp.WithNumber("limit",
+			mcp.Description("Number of processes to return (default 20)"),
+			mcp.Min(0),
+		),
+	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		sortBy := request.GetString("sort_by", "")
+		if sortBy != "" && !slices.Contains(listProcessSortKeys, sortBy) {
+			return mcp.NewToolResultError(fmt.Sprintf("unsupported sort_by %q: must be one of %s", sortBy, strings.Join(listProcessSortKeys, ", "))), nil
+		}
+		limit := request.GetInt("limit", 0)
+		if limit < 0 {
+			return mcp.NewToolResultError(fmt.Sprintf("limit %d must not be negative", limit)), nil
+		}
+		return mcp.NewToolResultText(listProcesses(ctx, sortBy, limit)), nil
 	})
 
 	s.AddTool(mcp.NewTool("disk_usage",
 		mcp.WithDescription("Get disk usage information for all mounted disks."),
+		mcp.WithString("format",
+			mcp.Description("Output format: text (default), json or markdown"),
+			mcp.Enum("text", "json", "markdown"),
+		),
+		mcp.WithBoolean("show_device",
+			mcp.Description("Include the device backing each mount"),
+		),
+		mcp.WithBoolean("keep_duplicates",
+			mcp.Description("List every mount separately instead of showing each filesystem once"),
+		),
+		mcp.WithNumber("min_total_mb",
+			mcp.Description("Omit partitions smaller than this many MiB; defaults to DISK_MIN_TOTAL_MB"),
+			mcp.Min(0),
+		),
+		mcp.WithBoolean("exact_bytes",
+			mcp.Description("Show exact byte counts with thousands separators instead of IEC units (text and markdown)"),
+		),
 	), func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		return mcp.NewToolResultText(collectDiskUsage()), nil
+		opts := diskReportOptions{
+			ShowDevice:     request.GetBool("show_device", false),
+			KeepDuplicates: request.GetBool("keep_duplicates", false),
+			MinTotalMB:     request.GetInt("min_total_mb", cfg.DiskMinTotalMB),
+			ExactBytes:     request.GetBool("exact_bytes", false),
+		}
+		report, err := diskUsageReport(request.GetString("format", "text"), opts)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return mcp.NewToolResultText(report), nil
 	})
 
-	slog.Info("Starting stdiokey-go MCP server", "transport", "stdio")
+	build := currentBuild()
+	slog.Info("Starting stdiokey-go MCP server", "transport", "stdio", "version", build.Version, "commit", build.Commit, "build_date", build.BuildDate)
 
+	if cfg.BackgroundRefresh {
+		defer startBackgroundRefresh(cfg.RefreshInterval)()
+	}
 	if err := server.ServeStdio(s); err != nil {
 		slog.Error("Failed to serve stdio", "error", err)
-		os.Exit(1)
+		os.Exit(exitFailure)
 	}
 }
@@ -1,19 +1,21 @@
 package main
 
 import (
+	"context"
+	"slices"
 	"strings"
 	"testing"
 )
 
 func TestCollectDiskUsage(t *testing.T) {
-	output := collectDiskUsage()
+	output := collectDiskUsage(diskReportOptions{})
 	if !strings.Contains(output, "Disk Usage Report") {
 		t.Errorf("Expected output to contain 'Disk Usage Report', got: %s", output)
 	}
 }
 
 func TestCollectSystemInfo(t *testing.T) {
-	output := collectSystemInfo("test status")
+	output := collectSystemInfo(context.Background(), "test status", false, systemInfoOptions{})
 	if !strings.Contains(output, "System Information Report") {
 		t.Errorf("Expected output to contain 'System Information Report', got: %s", output)
 	}
@@ -21,3 +23,50 @@ func TestCollectSystemInfo(t *testing.T) {
 		t.Errorf("Expected output to contain 'test status', got: %s", output)
 	}
 }
+
+func TestOfflineKeyStatus(t *testing.T) {
+	status, found := offlineKeyStatus("some-key")
+	if !found || !strings.Contains(status, "[FOUND]") || !strings.Contains(status, "[SKIPPED]") {
+		t.Errorf("Expected a found key with the cloud match skipped, got %v: %s", found, status)
+	}
+	if status, found := offlineKeyStatus(""); found || !strings.Contains(status, "[NOT FOUND]") {
+		t.Errorf("Expected a missing key, got %v: %s", found, status)
+	}
+}
+
+func TestTTYStatusOnly(t *testing.T) {
+	tests := []struct {
+		noCommand, serve, tty, want bool
+	}{
+		{noCommand: true, tty: true, want: true},
+		{noCommand: true, serve: true, tty: true, want: false},
+		{noCommand: true, tty: false, want: false},
+		{noCommand: false, tty: true, want: false},
+	}
+	for _, tt := range tests {
+		if got := ttyStatusOnly(tt.noCommand, tt.serve, tt.tty); got != tt.want {
+			t.Errorf("ttyStatusOnly(%v, %v, %v) = %v, want %v", tt.noCommand, tt.serve, tt.tty, got, tt.want)
+		}
+	}
+}
+
+func TestUsage(t *testing.T) {
+	for _, command := range []string{"info", "disk", "check", "config", "doctor"} {
+		if !strings.Contains(usageText, "\n  "+command+" ") {
+			t.Errorf("Expected usage to list %q", command)
+		}
+	}
+	if !isHelp("--help") || !isHelp("-h") || !isHelp("help") || isHelp("info") {
+		t.Error("Expected help, -h and --help to be recognized as help")
+	}
+}
+
+func TestSystemSectionNames(t *testing.T) {
+	var names []string
+	for _, s := range systemSections(systemInfoOptions{}) {
+		names = append(names, s.name)
+	}
+	if !slices.Equal(names, systemSectionNames) {
+		t.Errorf("systemSectionNames %q does not match systemSections %q", systemSectionNames, names)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/shirou/gopsutil/v3/host"
+	"github.com/shirou/gopsutil/v3/mem"
+	"github.com/shirou/gopsutil/v3/net"
+)
+
+// fakeSource is a MetricsSource with fixed values. A nil field makes the
+// matching call fail with errFake.
+type fakeSource struct {
+	host       *host.InfoStat
+	cores      int
+	memory     *mem.VirtualMemoryStat
+	swap       *mem.SwapMemoryStat
+	partitions []disk.PartitionStat
+	usage      map[string]*disk.UsageStat
+	interfaces net.InterfaceStatList
+	counters   []net.IOCountersStat
+}
+
+var errFake = errors.New("fake metrics unavailable")
+
+func (f fakeSource) HostInfo() (*host.InfoStat, error) {
+	if f.host == nil {
+		return nil, errFake
+	}
+	return f.host, nil
+}
+
+func (f fakeSource) CPUCounts(bool) (int, error) { return f.cores, nil }
+
+func (f fakeSource) VirtualMemory() (*mem.VirtualMemoryStat, error) {
+	if f.memory == nil {
+		return nil, errFake
+	}
+	return f.memory, nil
+}
+
+func (f fakeSource) SwapMemory() (*mem.SwapMemoryStat, error) {
+	if f.swap == nil {
+		return nil, errFake
+	}
+	return f.swap, nil
+}
+
+func (f fakeSource) Partitions(bool) ([]disk.PartitionStat, error) { return f.partitions, nil }
+
+func (f fakeSource) DiskUsage(mountpoint string) (*disk.UsageStat, error) {
+	if u, ok := f.usage[mountpoint]; ok {
+		return u, nil
+	}
+	return nil, errFake
+}
+
+func (f fakeSource) Interfaces() (net.InterfaceStatList, error) { return f.interfaces, nil }
+
+func (f fakeSource) IOCounters() ([]net.IOCountersStat, error) { return f.counters, nil }
+
+func TestCollectSystemInfoFakeSource(t *testing.T) {
+	src := fakeSource{
+		host:   &host.InfoStat{Hostname: "fake-host", OS: "linux", BootTime: 1767225600},
+		cores:  6,
+		memory: &mem.VirtualMemoryStat{Total: 8 << 30, Used: 3 << 30},
+		swap:   &mem.SwapMemoryStat{Total: 1 << 30, Used: 0},
+		interfaces: net.InterfaceStatList{
+			{Name: "eth0", MTU: 1500, HardwareAddr: "02:00:00:00:00:01", Flags: []string{"up"}},
+			{Name: "veth1", MTU: 1500, Flags: []string{"up"}},
+		},
+		counters: []net.IOCountersStat{{Name: "eth0", BytesRecv: 2048, BytesSent: 1024}},
+	}
+	out := collectSystemInfo(context.Background(), "", false, systemInfoOptions{Source: src})
+	for _, want := range []string{"fake-host", "2026-01-01", "Number of Cores:  6", "Total Memory:     8.0 GiB", "Used Memory:      3.0 GiB", "Total Swap:       1.0 GiB", "eth0", "RX:       2048 bytes", "1 idle interfaces hidden"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "veth1") {
+		t.Errorf("Expected the idle interface to be left out:\n%s", out)
+	}
+
+	src.host, src.memory = nil, nil
+	out = collectSystemInfo(context.Background(), "", false, systemInfoOptions{Source: src})
+	if strings.Contains(out, "Host Name:") || strings.Contains(out, "Total Memory:") || !strings.Contains(out, "Number of Cores:  6") {
+		t.Errorf("Expected only the failed sections to lose their values:\n%s", out)
+	}
+}
+
+func TestCollectDiskUsageFakeSource(t *testing.T) {
+	src := fakeSource{
+		partitions: []disk.PartitionStat{
+			{Device: "/dev/sda1", Mountpoint: "/", Fstype: "ext4"},
+			{Device: "/dev/sda1", Mountpoint: "/etc/hosts", Fstype: "ext4"},
+			{Device: "tmpfs", Mountpoint: "/run", Fstype: "tmpfs"},
+			{Device: "/dev/sdb1", Mountpoint: "/broken", Fstype: "xfs"},
+		},
+		usage: map[string]*disk.UsageStat{
+			"/":          {Total: 100 << 30, Used: 25 << 30, UsedPercent: 25},
+			"/etc/hosts": {Total: 100 << 30, Used: 25 << 30, UsedPercent: 25},
+			"/run":       {Total: 1 << 20, Used: 0},
+		},
+	}
+	out := collectDiskUsage(diskReportOptions{MinTotalMB: 10, Source: src})
+	if !strings.Contains(out, "25.0 GiB /  100.0 GiB used (25.0%)") || !strings.Contains(out, "/etc/hosts") {
+		t.Errorf("Expected the root partition with its bind mount noted, got:\n%s", out)
+	}
+	if strings.Contains(out, "/run") || strings.Contains(out, "/broken") {
+		t.Errorf("Expected the small and unreadable partitions to be left out, got:\n%s", out)
+	}
+
+	parts, err := reportPartitions(diskReportOptions{KeepDuplicates: true, Source: src})
+	if err != nil || len(parts) != 4 || parts[3].Error != errFake.Error() {
+		t.Errorf("Expected every partition with the read error kept, got %+v, %v", parts, err)
+	}
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/shirou/gopsutil/v3/net"
+)
+
+// networkInterface is the structured view of a host network interface. Both
+// the text report and JSON output are rendered from it.
+type networkInterface struct {
+	Name      string   `json:"name"`
+	MTU       int      `json:"mtu"`
+	MAC       string   `json:"mac"`
+	Vendor    string   `json:"vendor,omitempty"`
+	Flags     []string `json:"flags"`
+	Addresses []string `json:"addresses"`
+	HasIO     bool     `json:"has_io_stats"`
+	BytesRecv uint64   `json:"bytes_recv"`
+	BytesSent uint64   `json:"bytes_sent"`
+}
+
+func (n networkInterface) isUp() bool {
+	return slices.Contains(n.Flags, "up")
+}
+
+func (n networkInterface) isLoopback() bool {
+	return slices.Contains(n.Flags, "loopback")
+}
+
+// collectInterfaces enumerates network interfaces and joins them with their
+// IO counters. Missing IO counters are not an error; HasIO is left false.
+func collectInterfaces(src MetricsSource) ([]networkInterface, error) {
+	interfaces, err := src.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+	ioCounters, _ := src.IOCounters()
+	counters := make(map[string]net.IOCountersStat, len(ioCounters))
+	for _, io := range ioCounters {
+		counters[io.Name] = io
+	}
+
+	result := make([]networkInterface, 0, len(interfaces))
+	for _, inter := range interfaces {
+		n := networkInterface{
+			Name:      inter.Name,
+			MTU:       inter.MTU,
+			MAC:       inter.HardwareAddr,
+			Flags:     inter.Flags,
+			Addresses: make([]string, 0, len(inter.Addrs)),
+		}
+		if n.MAC == "" {
+			n.MAC = "unknown"
+		}
+		for _, addr := range inter.Addrs {
+			n.Addresses = append(n.Addresses, addr.Addr)
+		}
+		if io, ok := counters[inter.Name]; ok {
+			n.HasIO = true
+			n.BytesRecv = io.BytesRecv
+			n.BytesSent = io.BytesSent
+		}
+		result = append(result, n)
+	}
+	return result, nil
+}
+
+// isIdle reports whether the interface has moved no traffic. Interfaces
+// without IO counters count as idle.
+func (n networkInterface) isIdle() bool {
+	return n.BytesRecv == 0 && n.BytesSent == 0
+}
+
+// formatInterfaces renders interfaces as report lines. Unless includeIdle is
+// set, idle interfaces are left out and counted in a trailing note; on
+// container hosts they are mostly unused veth pairs.
+func formatInterfaces(interfaces []networkInterface, includeIdle bool) string {
+	var sb strings.Builder
+	hidden := 0
+	for _, n := range interfaces {
+		if !includeIdle && n.isIdle() {
+			hidden++
+			continue
+		}
+		sb.WriteString(formatInterface(n))
+	}
+	if hidden > 0 {
+		sb.WriteString(fmt.Sprintf("(%d idle interfaces hidden; set include_idle to list them)\n", hidden))
+	}
+	return sb.String()
+}
+
+// formatInterface renders a single interface as a report line, with the MAC
+// vendor when it was resolved.
+func formatInterface(n networkInterface) string {
+	flags := "none"
+	if len(n.Flags) > 0 {
+		flags = strings.Join(n.Flags, ",")
+	}
+	mac := n.MAC
+	if n.Vendor != "" {
+		mac += ", " + n.Vendor
+	}
+	if n.HasIO {
+		return fmt.Sprintf("%-18s: RX: %10d bytes, TX: %10d bytes (MAC: %s) [%s] MTU %d\n", n.Name, n.BytesRecv, n.BytesSent, mac, flags, n.MTU)
+	}
+	return fmt.Sprintf("%-18s: (No IO stats) (MAC: %s) [%s] MTU %d\n", n.Name, mac, flags, n.MTU)
+}
+
+// interfaceFilter selects interfaces by name. A nil Include matches every
+// name and a nil Exclude matches none. Patterns are unanchored regular
+// expressions, so "^eth" is needed to avoid also matching "veth0".
+type interfaceFilter struct {
+	Include *regexp.Regexp
+	Exclude *regexp.Regexp
+}
+
+// compileIfacePattern compiles an interface name pattern; setting names the
+// env var or tool input in errors. An empty pattern compiles to nil.
+func compileIfacePattern(setting, pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %q: %w", setting, pattern, err)
+	}
+	return re, nil
+}
+
+// newInterfaceFilter compiles the NET_IFACE_INCLUDE and NET_IFACE_EXCLUDE
+// patterns.
+func newInterfaceFilter(include, exclude string) (interfaceFilter, error) {
+	var f interfaceFilter
+	var err error
+	if f.Include, err = compileIfacePattern("NET_IFACE_INCLUDE", include); err != nil {
+		return f, err
+	}
+	if f.Exclude, err = compileIfacePattern("NET_IFACE_EXCLUDE", exclude); err != nil {
+		return f, err
+	}
+	return f, nil
+}
+
+// withOverrides replaces the configured patterns with the iface_include and
+// iface_exclude tool inputs that are set.
+func (f interfaceFilter) withOverrides(include, exclude string) (interfaceFilter, error) {
+	if include != "" {
+		re, err := compileIfacePattern("iface_include", include)
+		if err != nil {
+			return f, err
+		}
+		f.Include = re
+	}
+	if exclude != "" {
+		re, err := compileIfacePattern("iface_exclude", exclude)
+		if err != nil {
+			return f, err
+		}
+		f.Exclude = re
+	}
+	return f, nil
+}
+
+func (f interfaceFilter) matches(name string) bool {
+	return (f.Include == nil || f.Include.MatchString(name)) && (f.Exclude == nil || !f.Exclude.MatchString(name))
+}
+
+// format renders the interfaces that pass the filter like formatInterfaces,
+// noting how many were excluded by name.
+func (f interfaceFilter) format(interfaces []networkInterface, includeIdle bool) string {
+	kept := make([]networkInterface, 0, len(interfaces))
+	for _, n := range interfaces {
+		if f.matches(n.Name) {
+			kept = append(kept, n)
+		}
+	}
+	out := formatInterfaces(kept, includeIdle)
+	if excluded := len(interfaces) - len(kept); excluded > 0 {
+		out += fmt.Sprintf("(%d interfaces excluded by name filter)\n", excluded)
+	}
+	return out
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestOfflineSkipsCloudCalls(t *testing.T) {
+	old := gcloudProjectID
+	t.Cleanup(func() { gcloudProjectID = old })
+	gcloudProjectID = func() string {
+		t.Error("Expected no gcloud call in offline mode")
+		return "project"
+	}
+
+	cfg := &Config{Offline: true, APIKey: "key"}
+	status, valid := checkAPIKeyStatus(context.Background(), cfg)
+	if !valid || !strings.Contains(status, "[SKIPPED] (OFFLINE)") {
+		t.Errorf("Expected the provided key to be accepted offline, got valid=%v:\n%s", valid, status)
+	}
+	if _, valid := checkAPIKeyStatus(context.Background(), &Config{Offline: true}); valid {
+		t.Error("Expected a missing key to fail offline")
+	}
+	for _, c := range runDoctor(context.Background(), cfg) {
+		if c.Name == "Project resolution" || c.Name == "API key fetch" {
+			t.Errorf("Expected the cloud checks to be skipped offline, got %+v", c)
+		}
+	}
+}
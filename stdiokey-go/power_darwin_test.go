@@ -0,0 +1,11 @@
+package main
+
+import "testing"
+
+func TestParsePmset(t *testing.T) {
+	out := "Now drawing from 'Battery Power'\n -InternalBattery-0 (id=4653155)\t85%; discharging; 4:12 remaining present: true\n"
+	got := parsePmset(out)
+	if len(got) != 1 || got[0] != (battery{Name: "InternalBattery-0", Percent: 85, State: "discharging"}) {
+		t.Errorf("Unexpected batteries: %+v", got)
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// powerSupplyDir is where the kernel lists batteries and AC adapters.
+var powerSupplyDir = "/sys/class/power_supply"
+
+func readBatteries() ([]battery, error) {
+	entries, err := os.ReadDir(powerSupplyDir)
+	if err != nil {
+		return nil, err
+	}
+	var batteries []battery
+	for _, e := range entries {
+		dir := filepath.Join(powerSupplyDir, e.Name())
+		if readSysfs(dir, "type") != "Battery" {
+			continue
+		}
+		percent, err := strconv.Atoi(readSysfs(dir, "capacity"))
+		if err != nil {
+			continue
+		}
+		state := strings.ToLower(readSysfs(dir, "status"))
+		if state == "" {
+			state = "unknown"
+		}
+		batteries = append(batteries, battery{Name: e.Name(), Percent: percent, State: state})
+	}
+	return batteries, nil
+}
+
+// readSysfs returns a sysfs attribute without its trailing newline, or ""
+// when it cannot be read.
+func readSysfs(dir, name string) string {
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// sectionRetryBackoff is the pause before the first retry of a collection;
+// later retries wait proportionally longer.
+const sectionRetryBackoff = 50 * time.Millisecond
+
+// reportSection is one independently collected part of a report. Sections
+// render their own heading so a report can be assembled from whichever
+// sections finished. collect returns the section's primary collection error
+// alongside the text, which then describes the failure.
+type reportSection struct {
+	name    string
+	collect func() (string, error)
+}
+
+// sectionResult is the outcome of a reportSection. done is false when the
+// soft deadline expired before the section finished.
+type sectionResult struct {
+	name     string
+	text     string
+	err      error
+	duration time.Duration
+	done     bool
+}
+
+// collectSections runs every section concurrently and returns the results in
+// section order. A zero softDeadline waits for all of them; otherwise sections
+// still running when it expires are reported as not done and left to finish
+// in the background.
+func collectSections(sections []reportSection, softDeadline time.Duration) []sectionResult {
+	type completed struct {
+		index    int
+		text     string
+		err      error
+		duration time.Duration
+	}
+	ch := make(chan completed, len(sections))
+	results := make([]sectionResult, len(sections))
+	for i, s := range sections {
+		results[i].name = s.name
+		go func() {
+			start := time.Now()
+			text, err := s.collect()
+			ch <- completed{i, text, err, time.Since(start)}
+		}()
+	}
+
+	var deadline <-chan time.Time
+	if softDeadline > 0 {
+		t := time.NewTimer(softDeadline)
+		defer t.Stop()
+		deadline = t.C
+	}
+	for range sections {
+		select {
+		case c := <-ch:
+			results[c.index] = sectionResult{name: results[c.index].name, text: c.text, err: c.err, duration: c.duration, done: true}
+		case <-deadline:
+			return results
+		}
+	}
+	return results
+}
+
+// truncationNote explains which sections were left out of a report because
+// the soft deadline expired, or returns "" when every section finished.
+func truncationNote(results []sectionResult, softDeadline time.Duration) string {
+	var missing []string
+	for _, r := range results {
+		if !r.done {
+			missing = append(missing, r.name)
+		}
+	}
+	if len(missing) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("\nCollection truncated due to timeout: %s not complete after %s\n", strings.Join(missing, ", "), softDeadline)
+}
+
+// sectionRetry configures re-running a whole collection after a partial
+// failure. The zero value never retries.
+type sectionRetry struct {
+	Attempts  int // extra collections after the first
+	Threshold int // retry only when more sections than this failed
+}
+
+// failedSections counts the finished sections that reported an error.
+func failedSections(results []sectionResult) int {
+	n := 0
+	for _, r := range results {
+		if r.done && r.err != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// collectSectionsRetry runs collectSections and, while more than
+// retry.Threshold sections failed and attempts remain, collects everything
+// again after a short backoff. It keeps the attempt with the fewest failures
+// and stops retrying once ctx is done.
+func collectSectionsRetry(ctx context.Context, sections []reportSection, softDeadline time.Duration, retry sectionRetry) []sectionResult {
+	best := collectSections(sections, softDeadline)
+	for attempt := 1; attempt <= retry.Attempts && failedSections(best) > retry.Threshold; attempt++ {
+		select {
+		case <-ctx.Done():
+			return best
+		case <-time.After(time.Duration(attempt) * sectionRetryBackoff):
+		}
+		slog.Warn("Retrying system info collection", "attempt", attempt, "failed_sections", failedSections(best))
+		if results := collectSections(sections, softDeadline); failedSections(results) < failedSections(best) {
+			best = results
+		}
+	}
+	return best
+}
+
+// reportBudget limits the size of a rendered report for MCP clients with
+// small context windows (the max_lines and max_chars tool inputs). Zero
+// fields are unlimited.
+type reportBudget struct {
+	MaxLines int
+	MaxChars int
+}
+
+// lineCount counts the lines of s, including an unterminated last one.
+func lineCount(s string) int {
+	n := strings.Count(s, "\n")
+	if s != "" && !strings.HasSuffix(s, "\n") {
+		n++
+	}
+	return n
+}
+
+// fits reports whether s is within the budget.
+func (b reportBudget) fits(s string) bool {
+	return (b.MaxLines == 0 || lineCount(s) <= b.MaxLines) && (b.MaxChars == 0 || utf8.RuneCountInString(s) <= b.MaxChars)
+}
+
+// budgetCutNote ends a report cut mid-section by reportBudget.cut.
+const budgetCutNote = "\n[Report cut to fit max_lines/max_chars]\n"
+
+// cut trims s to the budget and appends budgetCutNote. It is the last resort
+// for reports that are over budget even without any section.
+func (b reportBudget) cut(s string) string {
+	if b.MaxLines > 0 {
+		lines := strings.SplitAfter(s, "\n")
+		s = strings.Join(lines[:min(len(lines), max(b.MaxLines-lineCount(budgetCutNote), 0))], "")
+	}
+	if b.MaxChars > 0 {
+		if keep := max(b.MaxChars-utf8.RuneCountInString(budgetCutNote), 0); utf8.RuneCountInString(s) > keep {
+			s = string([]rune(s)[:keep])
+		}
+	}
+	return s + budgetCutNote
+}
+
+// budgetNote names the sections left out of a report to fit its budget, or
+// returns "" when none were.
+func budgetNote(omitted []string) string {
+	if len(omitted) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("\nReport truncated to fit max_lines/max_chars: %s left out\n", strings.Join(omitted, ", "))
+}
+
+// parseSectionPriority reads a REPORT_SECTION_PRIORITY value, a
+// comma-separated list of section names, most important first. Names match
+// case-insensitively and are returned as spelled in names.
+func parseSectionPriority(s string, names []string) ([]string, error) {
+	var priority []string
+	for item := range strings.SplitSeq(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		i := slices.IndexFunc(names, func(n string) bool { return strings.EqualFold(n, item) })
+		if i < 0 {
+			return nil, fmt.Errorf("invalid REPORT_SECTION_PRIORITY: unknown section %q, want one of %s", item, strings.Join(names, ", "))
+		}
+		if slices.Contains(priority, names[i]) {
+			return nil, fmt.Errorf("invalid REPORT_SECTION_PRIORITY: section %q listed twice", names[i])
+		}
+		priority = append(priority, names[i])
+	}
+	return priority, nil
+}
+
+// priorityOrder returns the indexes of results with the sections named in
+// priority first, in that order, and the rest in report order.
+func priorityOrder(results []sectionResult, priority []string) []int {
+	order := make([]int, 0, len(results))
+	for _, name := range priority {
+		if i := slices.IndexFunc(results, func(r sectionResult) bool { return r.name == name }); i >= 0 {
+			order = append(order, i)
+		}
+	}
+	for i := range results {
+		if !slices.Contains(order, i) {
+			order = append(order, i)
+		}
+	}
+	return order
+}
+
+// fitSections renders a report within budget. Finished sections are added
+// most important first, by priority, and any that would push the report over
+// budget are left out. render gets the kept results in report order,
+// including unfinished ones for truncationNote, and the names of those left
+// out for budgetNote.
+func fitSections(results []sectionResult, priority []string, budget reportBudget, render func(kept []sectionResult, omitted []string) string) string {
+	if budget == (reportBudget{}) {
+		return render(results, nil)
+	}
+	keep := make([]bool, len(results))
+	for i, r := range results {
+		keep[i] = !r.done
+	}
+	build := func() string {
+		var kept []sectionResult
+		var omitted []string
+		for i, r := range results {
+			if keep[i] {
+				kept = append(kept, r)
+			} else {
+				omitted = append(omitted, r.name)
+			}
+		}
+		return render(kept, omitted)
+	}
+	for _, i := range priorityOrder(results, priority) {
+		if keep[i] {
+			continue
+		}
+		keep[i] = true
+		if !budget.fits(build()) {
+			keep[i] = false
+		}
+	}
+	out := build()
+	if !budget.fits(out) {
+		out = budget.cut(out)
+	}
+	return out
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCollectSectionsWaitsByDefault(t *testing.T) {
+	sections := []reportSection{
+		{"Slow", func() (string, error) { time.Sleep(20 * time.Millisecond); return "slow\n", nil }},
+		{"Fast", func() (string, error) { return "fast\n", nil }},
+	}
+	results := collectSections(sections, 0)
+	if len(results) != 2 || !results[0].done || !results[1].done {
+		t.Fatalf("expected every section to complete, got %+v", results)
+	}
+	if results[0].text != "slow\n" || results[1].text != "fast\n" {
+		t.Errorf("results out of section order: %+v", results)
+	}
+	if note := truncationNote(results, 0); note != "" {
+		t.Errorf("expected no truncation note, got %q", note)
+	}
+}
+
+func TestCollectSectionsSoftDeadline(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	sections := []reportSection{
+		{"Fast", func() (string, error) { return "fast\n", nil }},
+		{"Stuck", func() (string, error) { <-release; return "stuck\n", nil }},
+	}
+	results := collectSections(sections, 50*time.Millisecond)
+	if !results[0].done || results[0].text != "fast\n" {
+		t.Errorf("expected the fast section to be kept, got %+v", results[0])
+	}
+	if results[1].done {
+		t.Errorf("expected the stuck section to be cut off, got %+v", results[1])
+	}
+	note := truncationNote(results, 50*time.Millisecond)
+	if !strings.Contains(note, "truncated due to timeout") || !strings.Contains(note, "Stuck") {
+		t.Errorf("unexpected truncation note %q", note)
+	}
+}
+
+func TestCollectSectionsRetry(t *testing.T) {
+	calls := 0
+	flaky := func() (string, error) {
+		calls++
+		if calls <= 2 {
+			return "error\n", errors.New("/proc busy")
+		}
+		return "ok\n", nil
+	}
+	sections := []reportSection{{"Flaky", flaky}}
+	results := collectSectionsRetry(context.Background(), sections, 0, sectionRetry{Attempts: 2})
+	if failedSections(results) != 0 || results[0].text != "ok\n" {
+		t.Errorf("Expected the retry to recover, got %+v", results)
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 collections, got %d", calls)
+	}
+
+	calls = 0
+	results = collectSectionsRetry(context.Background(), sections, 0, sectionRetry{Attempts: 2, Threshold: 1})
+	if calls != 1 || failedSections(results) != 1 {
+		t.Errorf("Expected no retry at or below the threshold, got %d calls", calls)
+	}
+
+	calls = 0
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	collectSectionsRetry(ctx, sections, 0, sectionRetry{Attempts: 2})
+	if calls != 1 {
+		t.Errorf("Expected no retry after the context is done, got %d calls", calls)
+	}
+}
+
+func TestParseSectionPriority(t *testing.T) {
+	names := []string{"Host", "CPU", "Memory"}
+	got, err := parseSectionPriority(" memory, host ,", names)
+	if err != nil || strings.Join(got, ",") != "Memory,Host" {
+		t.Errorf("Expected Memory,Host, got %q, %v", got, err)
+	}
+	if got, err := parseSectionPriority("", names); err != nil || got != nil {
+		t.Errorf("Expected no priority for an empty value, got %q, %v", got, err)
+	}
+	for _, bad := range []string{"Disk", "CPU,cpu"} {
+		if _, err := parseSectionPriority(bad, names); err == nil {
+			t.Errorf("Expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestFitSections(t *testing.T) {
+	results := []sectionResult{
+		{name: "Host", text: "host\n", done: true},
+		{name: "Network", text: strings.Repeat("iface\n", 10), done: true},
+		{name: "Power", text: "power\n", done: true},
+		{name: "Slow", done: false},
+	}
+	render := func(kept []sectionResult, omitted []string) string {
+		var sb strings.Builder
+		sb.WriteString("Report\n")
+		for _, r := range kept {
+			if r.done {
+				sb.WriteString(r.text)
+			}
+		}
+		sb.WriteString(budgetNote(omitted))
+		return sb.String()
+	}
+
+	if out := fitSections(results, nil, reportBudget{}, render); lineCount(out) != 13 {
+		t.Errorf("Expected the full report without a budget, got:\n%s", out)
+	}
+
+	budget := reportBudget{MaxLines: 6}
+	out := fitSections(results, []string{"Power", "Host"}, budget, render)
+	if !budget.fits(out) {
+		t.Errorf("Expected the report to fit %d lines, got:\n%s", budget.MaxLines, out)
+	}
+	if !strings.Contains(out, "host\npower\n") || strings.Contains(out, "iface") || !strings.Contains(out, "Network left out") {
+		t.Errorf("Expected Network to be left out for the priority sections, got:\n%s", out)
+	}
+
+	budget = reportBudget{MaxChars: 60}
+	out = fitSections([]sectionResult{{name: "Big", text: strings.Repeat("x", 200) + "\n", done: true}}, nil, budget, func(kept []sectionResult, omitted []string) string {
+		return strings.Repeat("header\n", 20) + budgetNote(omitted)
+	})
+	if !budget.fits(out) || !strings.HasSuffix(out, budgetCutNote) {
+		t.Errorf("Expected an oversized header to be cut to %d chars, got %d:\n%s", budget.MaxChars, len(out), out)
+	}
+}
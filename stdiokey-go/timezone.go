@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+	"time"
+	// Embed the zone database so timezone works in minimal containers
+	// without /usr/share/zoneinfo.
+	_ "time/tzdata"
+)
+
+// parseTimezone resolves the timezone tool input. An empty name means UTC.
+func parseTimezone(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil || name == "Local" {
+		return nil, fmt.Errorf("unknown timezone %q: use an IANA name such as Europe/Berlin", name)
+	}
+	return loc, nil
+}
+
+// formatTimestamp renders t in loc with its offset and zone name, e.g.
+// "2026-10-16 14:00:00 +02:00 Europe/Berlin". A nil loc means UTC.
+func formatTimestamp(t time.Time, loc *time.Location) string {
+	if loc == nil {
+		loc = time.UTC
+	}
+	return t.In(loc).Format("2006-01-02 15:04:05 -07:00") + " " + loc.String()
+}
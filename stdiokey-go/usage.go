@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// usageText lists the commands and flags of the CLI.
+const usageText = `Usage: stdiokey-go [command] [flags]
+
+With no command, serves MCP over stdio. On a terminal it prints the API key
+status and exits instead, unless --serve is given.
+
+Commands:
+  info                  Print the system information report (requires a valid API key)
+    --resolve-vendor    Annotate MAC addresses with their vendor
+    --memory-detail     Break used memory down by category
+    --remote USER@HOST  Run the report on another host over ssh
+  disk                  Print the disk usage report
+    --json              Print JSON instead of text
+    --show-device       Include the device backing each mount
+    --keep-duplicates   List every mount separately
+    --exact-bytes       Show exact byte counts instead of IEC units
+    --remote USER@HOST  Run the report on another host over ssh
+  check                 Verify the API key against the key in the project
+    --offline           Only check that a key is provided; skip the cloud fetch
+  config                Print the resolved configuration
+    --json              Print JSON instead of text
+  doctor                Diagnose the local setup
+  version, --version    Print the version, git commit and build date
+  help, -h, --help      Show this help
+
+Flags:
+  --key KEY             API key to use when MCP_API_KEY is unset
+  --serve               Serve MCP even when stdin is a terminal
+`
+
+// isHelp reports whether arg asks for the usage text.
+func isHelp(arg string) bool {
+	return arg == "help" || arg == "-h" || arg == "--help"
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprint(w, usageText)
+}